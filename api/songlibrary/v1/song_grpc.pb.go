@@ -0,0 +1,372 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: songlibrary/v1/song.proto
+
+package songlibraryv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SongService_SearchSongs_FullMethodName    = "/songlibrary.v1.SongService/SearchSongs"
+	SongService_GetSong_FullMethodName        = "/songlibrary.v1.SongService/GetSong"
+	SongService_StreamSongText_FullMethodName = "/songlibrary.v1.SongService/StreamSongText"
+	SongService_InsertSong_FullMethodName     = "/songlibrary.v1.SongService/InsertSong"
+	SongService_UpdateSong_FullMethodName     = "/songlibrary.v1.SongService/UpdateSong"
+	SongService_UpdateSongText_FullMethodName = "/songlibrary.v1.SongService/UpdateSongText"
+	SongService_DeleteSong_FullMethodName     = "/songlibrary.v1.SongService/DeleteSong"
+)
+
+// SongServiceClient is the client API for SongService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SongService exposes the same operations as the REST API under /api/v1/songs.
+type SongServiceClient interface {
+	// SearchSongs returns songs matching exact filters with sorting and pagination.
+	SearchSongs(ctx context.Context, in *SearchSongsRequest, opts ...grpc.CallOption) (*SearchSongsResponse, error)
+	// GetSong returns a song by id.
+	GetSong(ctx context.Context, in *GetSongRequest, opts ...grpc.CallOption) (*Song, error)
+	// StreamSongText streams all couplets of the song (or of its translation) in order.
+	StreamSongText(ctx context.Context, in *StreamSongTextRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Couplet], error)
+	// InsertSong adds a new song, its details are requested from the external song info service.
+	InsertSong(ctx context.Context, in *InsertSongRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// UpdateSong changes the fields that are set in the request.
+	UpdateSong(ctx context.Context, in *UpdateSongRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// UpdateSongText replaces the song text. Couplets are separated by double newline symbols.
+	UpdateSongText(ctx context.Context, in *UpdateSongTextRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// DeleteSong deletes a song by id.
+	DeleteSong(ctx context.Context, in *DeleteSongRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type songServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSongServiceClient(cc grpc.ClientConnInterface) SongServiceClient {
+	return &songServiceClient{cc}
+}
+
+func (c *songServiceClient) SearchSongs(ctx context.Context, in *SearchSongsRequest, opts ...grpc.CallOption) (*SearchSongsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchSongsResponse)
+	err := c.cc.Invoke(ctx, SongService_SearchSongs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *songServiceClient) GetSong(ctx context.Context, in *GetSongRequest, opts ...grpc.CallOption) (*Song, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Song)
+	err := c.cc.Invoke(ctx, SongService_GetSong_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *songServiceClient) StreamSongText(ctx context.Context, in *StreamSongTextRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Couplet], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SongService_ServiceDesc.Streams[0], SongService_StreamSongText_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamSongTextRequest, Couplet]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SongService_StreamSongTextClient = grpc.ServerStreamingClient[Couplet]
+
+func (c *songServiceClient) InsertSong(ctx context.Context, in *InsertSongRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, SongService_InsertSong_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *songServiceClient) UpdateSong(ctx context.Context, in *UpdateSongRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, SongService_UpdateSong_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *songServiceClient) UpdateSongText(ctx context.Context, in *UpdateSongTextRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, SongService_UpdateSongText_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *songServiceClient) DeleteSong(ctx context.Context, in *DeleteSongRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, SongService_DeleteSong_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SongServiceServer is the server API for SongService service.
+// All implementations must embed UnimplementedSongServiceServer
+// for forward compatibility.
+//
+// SongService exposes the same operations as the REST API under /api/v1/songs.
+type SongServiceServer interface {
+	// SearchSongs returns songs matching exact filters with sorting and pagination.
+	SearchSongs(context.Context, *SearchSongsRequest) (*SearchSongsResponse, error)
+	// GetSong returns a song by id.
+	GetSong(context.Context, *GetSongRequest) (*Song, error)
+	// StreamSongText streams all couplets of the song (or of its translation) in order.
+	StreamSongText(*StreamSongTextRequest, grpc.ServerStreamingServer[Couplet]) error
+	// InsertSong adds a new song, its details are requested from the external song info service.
+	InsertSong(context.Context, *InsertSongRequest) (*emptypb.Empty, error)
+	// UpdateSong changes the fields that are set in the request.
+	UpdateSong(context.Context, *UpdateSongRequest) (*emptypb.Empty, error)
+	// UpdateSongText replaces the song text. Couplets are separated by double newline symbols.
+	UpdateSongText(context.Context, *UpdateSongTextRequest) (*emptypb.Empty, error)
+	// DeleteSong deletes a song by id.
+	DeleteSong(context.Context, *DeleteSongRequest) (*emptypb.Empty, error)
+	mustEmbedUnimplementedSongServiceServer()
+}
+
+// UnimplementedSongServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSongServiceServer struct{}
+
+func (UnimplementedSongServiceServer) SearchSongs(context.Context, *SearchSongsRequest) (*SearchSongsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchSongs not implemented")
+}
+func (UnimplementedSongServiceServer) GetSong(context.Context, *GetSongRequest) (*Song, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSong not implemented")
+}
+func (UnimplementedSongServiceServer) StreamSongText(*StreamSongTextRequest, grpc.ServerStreamingServer[Couplet]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamSongText not implemented")
+}
+func (UnimplementedSongServiceServer) InsertSong(context.Context, *InsertSongRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InsertSong not implemented")
+}
+func (UnimplementedSongServiceServer) UpdateSong(context.Context, *UpdateSongRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateSong not implemented")
+}
+func (UnimplementedSongServiceServer) UpdateSongText(context.Context, *UpdateSongTextRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateSongText not implemented")
+}
+func (UnimplementedSongServiceServer) DeleteSong(context.Context, *DeleteSongRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSong not implemented")
+}
+func (UnimplementedSongServiceServer) mustEmbedUnimplementedSongServiceServer() {}
+func (UnimplementedSongServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeSongServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SongServiceServer will
+// result in compilation errors.
+type UnsafeSongServiceServer interface {
+	mustEmbedUnimplementedSongServiceServer()
+}
+
+func RegisterSongServiceServer(s grpc.ServiceRegistrar, srv SongServiceServer) {
+	// If the following call pancis, it indicates UnimplementedSongServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SongService_ServiceDesc, srv)
+}
+
+func _SongService_SearchSongs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchSongsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SongServiceServer).SearchSongs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SongService_SearchSongs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SongServiceServer).SearchSongs(ctx, req.(*SearchSongsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SongService_GetSong_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSongRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SongServiceServer).GetSong(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SongService_GetSong_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SongServiceServer).GetSong(ctx, req.(*GetSongRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SongService_StreamSongText_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamSongTextRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SongServiceServer).StreamSongText(m, &grpc.GenericServerStream[StreamSongTextRequest, Couplet]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SongService_StreamSongTextServer = grpc.ServerStreamingServer[Couplet]
+
+func _SongService_InsertSong_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InsertSongRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SongServiceServer).InsertSong(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SongService_InsertSong_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SongServiceServer).InsertSong(ctx, req.(*InsertSongRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SongService_UpdateSong_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateSongRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SongServiceServer).UpdateSong(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SongService_UpdateSong_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SongServiceServer).UpdateSong(ctx, req.(*UpdateSongRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SongService_UpdateSongText_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateSongTextRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SongServiceServer).UpdateSongText(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SongService_UpdateSongText_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SongServiceServer).UpdateSongText(ctx, req.(*UpdateSongTextRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SongService_DeleteSong_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSongRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SongServiceServer).DeleteSong(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SongService_DeleteSong_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SongServiceServer).DeleteSong(ctx, req.(*DeleteSongRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SongService_ServiceDesc is the grpc.ServiceDesc for SongService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SongService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "songlibrary.v1.SongService",
+	HandlerType: (*SongServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SearchSongs",
+			Handler:    _SongService_SearchSongs_Handler,
+		},
+		{
+			MethodName: "GetSong",
+			Handler:    _SongService_GetSong_Handler,
+		},
+		{
+			MethodName: "InsertSong",
+			Handler:    _SongService_InsertSong_Handler,
+		},
+		{
+			MethodName: "UpdateSong",
+			Handler:    _SongService_UpdateSong_Handler,
+		},
+		{
+			MethodName: "UpdateSongText",
+			Handler:    _SongService_UpdateSongText_Handler,
+		},
+		{
+			MethodName: "DeleteSong",
+			Handler:    _SongService_DeleteSong_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamSongText",
+			Handler:       _SongService_StreamSongText_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "songlibrary/v1/song.proto",
+}
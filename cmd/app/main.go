@@ -1,7 +1,33 @@
 package main
 
-import "github.com/spanwalla/song-library/internal/app"
+import (
+	"fmt"
+	"os"
+
+	"github.com/spanwalla/song-library/internal/app"
+)
+
+const usage = `usage: app [command]
+
+commands:
+  serve                    run the server (default)
+  migrate up|down [n]|status|force <v>|version
+                           manage database migrations`
 
 func main() {
-	app.Run()
+	args := os.Args[1:]
+	if len(args) == 0 {
+		app.Run()
+		return
+	}
+
+	switch args[0] {
+	case "serve":
+		app.Run()
+	case "migrate":
+		os.Exit(app.Migrate(args[1:]))
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
 }
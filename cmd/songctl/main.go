@@ -0,0 +1,11 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spanwalla/song-library/internal/songctl"
+)
+
+func main() {
+	os.Exit(songctl.Run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
@@ -2,39 +2,168 @@ package config
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
 type (
 	Config struct {
-		App     `yaml:"app"`
-		HTTP    `yaml:"http"`
-		Log     `yaml:"logger"`
-		PG      `yaml:"postgres"`
-		SongAPI `yaml:"song_api"`
+		App       `yaml:"app"`
+		HTTP      `yaml:"http"`
+		GRPC      `yaml:"grpc"`
+		Log       `yaml:"logger"`
+		PG        `yaml:"postgres"`
+		SongAPI   `yaml:"song_api"`
+		Tracing   `yaml:"tracing"`
+		Health    `yaml:"health"`
+		AccessLog `yaml:"access_log"`
+		Auth      `yaml:"auth"`
+		JWT       `yaml:"jwt"`
+		RateLimit `yaml:"rate_limit"`
+		Tenants   `yaml:"tenants"`
+		Cache     `yaml:"cache"`
 	}
 
 	App struct {
-		Name    string `env-required:"true" yaml:"name" env:"APP_NAME"`
-		Version string `env-required:"true" yaml:"version" env:"APP_VERSION"`
+		Name           string        `env-required:"true" yaml:"name" env:"APP_NAME"`
+		Version        string        `env-required:"true" yaml:"version" env:"APP_VERSION"`
+		ReloadInterval time.Duration `env-default:"10s" yaml:"reload_interval" env:"APP_RELOAD_INTERVAL"`
 	}
 
 	HTTP struct {
-		Port string `env-required:"true" yaml:"port" env:"HTTP_PORT"`
+		Host              string        `yaml:"host" env:"HTTP_HOST"`
+		Port              string        `env-required:"true" yaml:"port" env:"HTTP_PORT"`
+		ReadTimeout       time.Duration `env-default:"5s" yaml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+		ReadHeaderTimeout time.Duration `env-default:"2s" yaml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+		WriteTimeout      time.Duration `env-default:"5s" yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+		IdleTimeout       time.Duration `env-default:"60s" yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+		ShutdownTimeout   time.Duration `env-default:"3s" yaml:"shutdown_timeout" env:"HTTP_SHUTDOWN_TIMEOUT"`
+		MaxHeaderBytes    int           `env-default:"1048576" yaml:"max_header_bytes" env:"HTTP_MAX_HEADER_BYTES"`
+		TLSCertFile       string        `yaml:"tls_cert_file" env:"HTTP_TLS_CERT_FILE"`
+		TLSKeyFile        string        `yaml:"tls_key_file" env:"HTTP_TLS_KEY_FILE"`
+		HTTP2             bool          `env-default:"true" yaml:"http2" env:"HTTP_HTTP2"`
+		H2C               bool          `env-default:"false" yaml:"h2c" env:"HTTP_H2C"`
+		TrustedProxies    []string      `yaml:"trusted_proxies" env:"HTTP_TRUSTED_PROXIES" env-separator:","`
+		CORS              CORS          `yaml:"cors"`
+	}
+
+	// CORS задаёт отдельные политики для чтения (GET, HEAD) и изменения данных.
+	CORS struct {
+		Read  CORSPolicy `yaml:"read" env-prefix:"HTTP_CORS_READ_"`
+		Write CORSPolicy `yaml:"write" env-prefix:"HTTP_CORS_WRITE_"`
+	}
+
+	// CORSPolicy: источник задаётся точно (https://example.com), шаблоном поддомена
+	// (https://*.example.com) или как *. Пустой список запрещает запросы с других сайтов.
+	CORSPolicy struct {
+		AllowOrigins     []string      `yaml:"allow_origins" env:"ALLOW_ORIGINS" env-separator:","`
+		AllowMethods     []string      `yaml:"allow_methods" env:"ALLOW_METHODS" env-separator:","`
+		AllowHeaders     []string      `yaml:"allow_headers" env:"ALLOW_HEADERS" env-separator:","`
+		ExposeHeaders    []string      `yaml:"expose_headers" env:"EXPOSE_HEADERS" env-separator:","`
+		AllowCredentials bool          `yaml:"allow_credentials" env:"ALLOW_CREDENTIALS"`
+		MaxAge           time.Duration `env-default:"10m" yaml:"max_age" env:"MAX_AGE"`
+	}
+
+	GRPC struct {
+		Port string `env-required:"true" yaml:"port" env:"GRPC_PORT"`
 	}
 
 	Log struct {
 		Level string `env-required:"true" yaml:"level" env:"LOG_LEVEL"`
 	}
 
+	// PG: чтение песен и текстов распределяется по ReplicaURLs, если они заданы. ReadYourWrites
+	// направляет чтение запроса на основной сервер после того, как запрос записал данные.
 	PG struct {
-		PoolMax int    `env-required:"true" yaml:"pool_max" env:"PG_POOL_MAX"`
-		URL     string `env-required:"true" env:"PG_URL"`
+		PoolMax              int           `env-required:"true" yaml:"pool_max" env:"PG_POOL_MAX"`
+		URL                  string        `env-required:"true" env:"PG_URL"`
+		AutoMigrate          bool          `env-default:"true" yaml:"auto_migrate" env:"PG_AUTO_MIGRATE"`
+		ReplicaURLs          []string      `env:"PG_REPLICA_URLS" env-separator:","`
+		ReplicaCheckInterval time.Duration `env-default:"5s" yaml:"replica_check_interval" env:"PG_REPLICA_CHECK_INTERVAL"`
+		ReadYourWrites       bool          `env-default:"true" yaml:"read_your_writes" env:"PG_READ_YOUR_WRITES"`
+		ReadYourWritesTTL    time.Duration `env-default:"5s" yaml:"read_your_writes_ttl" env:"PG_READ_YOUR_WRITES_TTL"`
 	}
 
 	SongAPI struct {
-		URL string `env-required:"true" yaml:"url" env:"SONG_API_URL"`
+		URL     string        `env-required:"true" yaml:"url" env:"SONG_API_URL"`
+		Timeout time.Duration `env-default:"10s" yaml:"timeout" env:"SONG_API_TIMEOUT"`
+	}
+
+	Tracing struct {
+		Exporter    string  `env-default:"off" yaml:"exporter" env:"TRACING_EXPORTER"`
+		Endpoint    string  `yaml:"endpoint" env:"TRACING_ENDPOINT"`
+		Insecure    bool    `yaml:"insecure" env:"TRACING_INSECURE"`
+		SampleRatio float64 `env-default:"1" yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+	}
+
+	Health struct {
+		CheckTimeout  time.Duration `env-default:"2s" yaml:"check_timeout" env:"HEALTH_CHECK_TIMEOUT"`
+		ShutdownDelay time.Duration `env-default:"5s" yaml:"shutdown_delay" env:"HEALTH_SHUTDOWN_DELAY"`
+		ProbeSongInfo bool          `env-default:"false" yaml:"probe_song_info" env:"HEALTH_PROBE_SONG_INFO"`
+	}
+
+	AccessLog struct {
+		Output     string        `env-default:"stdout" yaml:"output" env:"ACCESS_LOG_OUTPUT"`
+		Format     string        `env-default:"json" yaml:"format" env:"ACCESS_LOG_FORMAT"`
+		Fields     []string      `env-default:"latency,bytes,user_agent,client_ip" yaml:"fields" env:"ACCESS_LOG_FIELDS" env-separator:","`
+		Path       string        `env-default:"logs/requests.log" yaml:"path" env:"ACCESS_LOG_PATH"`
+		MaxSizeMB  int           `env-default:"100" yaml:"max_size_mb" env:"ACCESS_LOG_MAX_SIZE_MB"`
+		MaxAge     time.Duration `env-default:"24h" yaml:"max_age" env:"ACCESS_LOG_MAX_AGE"`
+		MaxBackups int           `env-default:"7" yaml:"max_backups" env:"ACCESS_LOG_MAX_BACKUPS"`
+	}
+
+	Auth struct {
+		Enabled           bool `env-default:"true" yaml:"enabled" env:"AUTH_ENABLED"`
+		AllowRegistration bool `env-default:"true" yaml:"allow_registration" env:"AUTH_ALLOW_REGISTRATION"`
+	}
+
+	// JWT задаёт подпись access-токенов пользователей. Ключи указываются как id:secret,
+	// первым подписываются новые токены, остальные нужны на время ротации.
+	JWT struct {
+		Keys       []string      `yaml:"keys" env:"JWT_KEYS" env-separator:","`
+		AccessTTL  time.Duration `env-default:"15m" yaml:"access_ttl" env:"JWT_ACCESS_TTL"`
+		RefreshTTL time.Duration `env-default:"720h" yaml:"refresh_ttl" env:"JWT_REFRESH_TTL"`
+	}
+
+	// RateLimit задаёт число запросов клиента за окно Window. 0 отключает бюджет.
+	RateLimit struct {
+		Enabled bool          `env-default:"true" yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+		Store   string        `env-default:"memory" yaml:"store" env:"RATE_LIMIT_STORE"`
+		Window  time.Duration `env-default:"1m" yaml:"window" env:"RATE_LIMIT_WINDOW"`
+		Read    int           `env-default:"600" yaml:"read" env:"RATE_LIMIT_READ"`
+		Write   int           `env-default:"120" yaml:"write" env:"RATE_LIMIT_WRITE"`
+		Insert  int           `env-default:"10" yaml:"insert" env:"RATE_LIMIT_INSERT"`
+	}
+
+	// Tenants: арендатор запроса берётся из заголовка X-Tenant, из поддомена BaseDomain
+	// или из учётных данных. Overrides задаёт настройки отдельных арендаторов по slug.
+	Tenants struct {
+		BaseDomain string                    `yaml:"base_domain" env:"TENANTS_BASE_DOMAIN"`
+		Overrides  map[string]TenantOverride `yaml:"overrides"`
+	}
+
+	// TenantOverride: AllowRegistration открывает библиотеку для регистрации без приглашения,
+	// если она разрешена в auth.allow_registration.
+	TenantOverride struct {
+		SongAPI           *TenantSongAPI `yaml:"song_api"`
+		AllowRegistration bool           `yaml:"allow_registration"`
+	}
+
+	// TenantSongAPI: если Timeout не задан, используется таймаут из секции song_api.
+	TenantSongAPI struct {
+		URL     string        `yaml:"url"`
+		Timeout time.Duration `yaml:"timeout"`
+	}
+
+	// Cache: песни и страницы текста хранятся в памяти процесса не дольше TTL (0 — без срока).
+	// Invalidation "postgres" рассылает удаление записей другим репликам через LISTEN/NOTIFY.
+	Cache struct {
+		Enabled      bool          `env-default:"true" yaml:"enabled" env:"CACHE_ENABLED"`
+		Size         int           `env-default:"10000" yaml:"size" env:"CACHE_SIZE"`
+		TTL          time.Duration `env-default:"1m" yaml:"ttl" env:"CACHE_TTL"`
+		Invalidation string        `env-default:"local" yaml:"invalidation" env:"CACHE_INVALIDATION"`
 	}
 )
 
@@ -51,5 +180,32 @@ func New(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("config - NewConfig - cleanenv.UpdateEnv: %w", err)
 	}
 
+	err = cfg.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("config - NewConfig - cfg.Validate: %w", err)
+	}
+
 	return cfg, nil
 }
+
+// SongAPIs возвращает адреса внешнего API для арендаторов, у которых он переопределён.
+func (t Tenants) SongAPIs(defaults SongAPI) map[string]SongAPI {
+	apis := make(map[string]SongAPI)
+	for slug, override := range t.Overrides {
+		if override.SongAPI == nil {
+			continue
+		}
+		api := SongAPI{URL: override.SongAPI.URL, Timeout: override.SongAPI.Timeout}
+		if api.Timeout <= 0 {
+			api.Timeout = defaults.Timeout
+		}
+		apis[slug] = api
+	}
+	return apis
+}
+
+// SplitSigningKey разбирает ключ подписи в формате id:secret.
+func SplitSigningKey(key string) (id, secret string, ok bool) {
+	id, secret, ok = strings.Cut(key, ":")
+	return id, secret, ok && len(id) > 0 && len(secret) > 0
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/spanwalla/song-library/internal/entity"
+)
+
+// minSigningKeyLength — минимальная длина секрета HS256, меньше длины хэша ключ ослабляет подпись.
+const minSigningKeyLength = 32
+
+var (
+	tracingExporters  = []string{"otlp", "stdout", "off"}
+	accessLogOutputs  = []string{"stdout", "file", "off"}
+	accessLogFormats  = []string{"json", "combined"}
+	accessLogFields   = []string{"latency", "bytes", "user_agent", "client_ip"}
+	rateLimitStores   = []string{"memory", "postgres"}
+	cacheInvalidation = []string{"local", "postgres"}
+	corsMethods       = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	errInvalidSetting = errors.New("invalid setting")
+)
+
+// Validate проверяет значения, которые cleanenv не может проверить по тегам.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, name string, value any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: %s = %v", errInvalidSetting, name, value))
+		}
+	}
+
+	_, err := log.ParseLevel(c.Log.Level)
+	check(err == nil, "logger.level", c.Log.Level)
+
+	songAPI, err := url.Parse(c.SongAPI.URL)
+	check(err == nil && len(songAPI.Scheme) > 0 && len(songAPI.Host) > 0, "song_api.url", c.SongAPI.URL)
+	check(c.SongAPI.Timeout > 0, "song_api.timeout", c.SongAPI.Timeout)
+
+	check(c.PG.PoolMax > 0, "postgres.pool_max", c.PG.PoolMax)
+	check(c.PG.ReplicaCheckInterval > 0, "postgres.replica_check_interval", c.PG.ReplicaCheckInterval)
+	check(c.PG.ReadYourWritesTTL >= 0, "postgres.read_your_writes_ttl", c.PG.ReadYourWritesTTL)
+
+	check(c.HTTP.ReadTimeout >= 0, "http.read_timeout", c.HTTP.ReadTimeout)
+	check(c.HTTP.ReadHeaderTimeout >= 0, "http.read_header_timeout", c.HTTP.ReadHeaderTimeout)
+	check(c.HTTP.WriteTimeout >= 0, "http.write_timeout", c.HTTP.WriteTimeout)
+	check(c.HTTP.IdleTimeout >= 0, "http.idle_timeout", c.HTTP.IdleTimeout)
+	check(c.HTTP.MaxHeaderBytes >= 0, "http.max_header_bytes", c.HTTP.MaxHeaderBytes)
+	check((len(c.HTTP.TLSCertFile) > 0) == (len(c.HTTP.TLSKeyFile) > 0), "http.tls_key_file", c.HTTP.TLSKeyFile)
+	for _, proxy := range c.HTTP.TrustedProxies {
+		_, _, err := net.ParseCIDR(proxy)
+		check(err == nil, "http.trusted_proxies", proxy)
+	}
+
+	c.HTTP.CORS.Read.validate("http.cors.read", check)
+	c.HTTP.CORS.Write.validate("http.cors.write", check)
+
+	check(slices.Contains(tracingExporters, c.Tracing.Exporter), "tracing.exporter", c.Tracing.Exporter)
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio", c.Tracing.SampleRatio)
+
+	check(c.Health.CheckTimeout > 0, "health.check_timeout", c.Health.CheckTimeout)
+	check(c.Health.ShutdownDelay >= 0, "health.shutdown_delay", c.Health.ShutdownDelay)
+
+	check(slices.Contains(accessLogOutputs, c.AccessLog.Output), "access_log.output", c.AccessLog.Output)
+	check(slices.Contains(accessLogFormats, c.AccessLog.Format), "access_log.format", c.AccessLog.Format)
+	for _, field := range c.AccessLog.Fields {
+		check(slices.Contains(accessLogFields, field), "access_log.fields", field)
+	}
+
+	check(c.App.ReloadInterval >= 0, "app.reload_interval", c.App.ReloadInterval)
+
+	// Секреты в сообщение об ошибке не попадают, только идентификатор или номер ключа.
+	ids := make(map[string]bool)
+	for i, key := range c.JWT.Keys {
+		id, secret, ok := SplitSigningKey(key)
+		check(ok, "jwt.keys", fmt.Sprintf("#%d (expected id:secret)", i+1))
+		check(!ok || len(secret) >= minSigningKeyLength, "jwt.keys", fmt.Sprintf("%s (secret shorter than %d bytes)", id, minSigningKeyLength))
+		check(!ok || !ids[id], "jwt.keys", fmt.Sprintf("%s (duplicate id)", id))
+		ids[id] = true
+	}
+	check(c.JWT.AccessTTL > 0, "jwt.access_ttl", c.JWT.AccessTTL)
+	check(c.JWT.RefreshTTL > 0, "jwt.refresh_ttl", c.JWT.RefreshTTL)
+
+	check(slices.Contains(rateLimitStores, c.RateLimit.Store), "rate_limit.store", c.RateLimit.Store)
+	check(c.RateLimit.Window >= time.Second, "rate_limit.window", c.RateLimit.Window)
+	check(c.RateLimit.Read >= 0, "rate_limit.read", c.RateLimit.Read)
+	check(c.RateLimit.Write >= 0, "rate_limit.write", c.RateLimit.Write)
+	check(c.RateLimit.Insert >= 0, "rate_limit.insert", c.RateLimit.Insert)
+
+	check(c.Cache.Size > 0, "cache.size", c.Cache.Size)
+	check(c.Cache.TTL >= 0, "cache.ttl", c.Cache.TTL)
+	check(slices.Contains(cacheInvalidation, c.Cache.Invalidation), "cache.invalidation", c.Cache.Invalidation)
+
+	check(validBaseDomain(c.Tenants.BaseDomain), "tenants.base_domain", c.Tenants.BaseDomain)
+	for slug, override := range c.Tenants.Overrides {
+		check(entity.ValidTenantSlug(slug), "tenants.overrides", slug)
+		if override.SongAPI != nil {
+			name := "tenants.overrides." + slug + ".song_api"
+			api, err := url.Parse(override.SongAPI.URL)
+			check(err == nil && len(api.Scheme) > 0 && len(api.Host) > 0, name+".url", override.SongAPI.URL)
+			check(override.SongAPI.Timeout >= 0, name+".timeout", override.SongAPI.Timeout)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (p CORSPolicy) validate(name string, check func(ok bool, name string, value any)) {
+	for _, origin := range p.AllowOrigins {
+		check(validOrigin(origin), name+".allow_origins", origin)
+		// Браузер не принимает * вместе с учётными данными, а подставлять любой Origin небезопасно.
+		check(origin != "*" || !p.AllowCredentials, name+".allow_credentials", p.AllowCredentials)
+	}
+	for _, method := range p.AllowMethods {
+		check(slices.Contains(corsMethods, method), name+".allow_methods", method)
+	}
+	check(p.MaxAge >= 0, name+".max_age", p.MaxAge)
+}
+
+// validBaseDomain проверяет, что домен указан без схемы, порта и точки в начале.
+// Пустой домен отключает выбор арендатора по поддомену.
+func validBaseDomain(domain string) bool {
+	if len(domain) == 0 {
+		return true
+	}
+	u, err := url.Parse("http://" + domain)
+	return err == nil && u.Host == domain && len(u.Port()) == 0 && !strings.HasPrefix(domain, ".")
+}
+
+// validOrigin проверяет, что источник — это *, схема с хостом и портом без пути
+// или шаблон поддомена вида https://*.example.com.
+func validOrigin(origin string) bool {
+	if origin == "*" {
+		return true
+	}
+
+	u, err := url.Parse(strings.Replace(origin, "://*.", "://wildcard.", 1))
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && len(u.Host) > 0 &&
+		len(u.Path) == 0 && len(u.RawQuery) == 0 && u.User == nil && !strings.Contains(u.Host, "*")
+}
@@ -0,0 +1,184 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/spanwalla/song-library/config"
+	"github.com/spanwalla/song-library/pkg/logfile"
+)
+
+const (
+	accessLogOutputStdout = "stdout"
+	accessLogOutputFile   = "file"
+	accessLogOutputOff    = "off"
+
+	accessLogFormatJSON     = "json"
+	accessLogFormatCombined = "combined"
+
+	accessLogFieldLatency   = "latency"
+	accessLogFieldBytes     = "bytes"
+	accessLogFieldUserAgent = "user_agent"
+	accessLogFieldClientIP  = "client_ip"
+
+	combinedTimeFormat = "02/Jan/2006:15:04:05 -0700"
+	megabyte           = 1 << 20
+)
+
+// accessLogEntry описывает запись журнала запросов в формате JSON. Необязательные поля
+// заполняются, только если они выбраны в конфигурации.
+type accessLogEntry struct {
+	Time      string  `json:"time"`
+	RequestId string  `json:"request_id,omitempty"`
+	Method    string  `json:"method"`
+	URI       string  `json:"uri"`
+	Route     string  `json:"route,omitempty"`
+	Status    int     `json:"status"`
+	Error     string  `json:"error,omitempty"`
+	LatencyMs *int64  `json:"latency_ms,omitempty"`
+	BytesIn   *string `json:"bytes_in,omitempty"`
+	BytesOut  *int64  `json:"bytes_out,omitempty"`
+	UserAgent *string `json:"user_agent,omitempty"`
+	ClientIP  *string `json:"client_ip,omitempty"`
+}
+
+// newAccessLog создаёт middleware журнала запросов. Если журнал пишется в файл, вместе с ним
+// возвращается файл, который нужно переоткрывать по SIGHUP и закрывать при остановке.
+// При output: off middleware не создаётся.
+func newAccessLog(cfg config.AccessLog) (echo.MiddlewareFunc, *logfile.Writer, error) {
+	var (
+		output io.Writer
+		file   *logfile.Writer
+		err    error
+	)
+
+	switch cfg.Output {
+	case accessLogOutputOff:
+		return nil, nil, nil
+	case accessLogOutputStdout:
+		output = os.Stdout
+	case accessLogOutputFile:
+		file, err = logfile.Open(cfg.Path,
+			logfile.MaxSize(int64(cfg.MaxSizeMB)*megabyte),
+			logfile.MaxAge(cfg.MaxAge),
+			logfile.MaxBackups(cfg.MaxBackups),
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("app - newAccessLog - logfile.Open: %w", err)
+		}
+		output = file
+	default:
+		return nil, nil, fmt.Errorf("app - newAccessLog: unknown output %q", cfg.Output)
+	}
+
+	var format func(middleware.RequestLoggerValues) []byte
+	switch cfg.Format {
+	case accessLogFormatJSON:
+		format = formatJSON
+	case accessLogFormatCombined:
+		format = formatCombined
+	default:
+		return nil, nil, fmt.Errorf("app - newAccessLog: unknown format %q", cfg.Format)
+	}
+
+	for _, field := range cfg.Fields {
+		if !slices.Contains([]string{accessLogFieldLatency, accessLogFieldBytes, accessLogFieldUserAgent, accessLogFieldClientIP}, field) {
+			return nil, nil, fmt.Errorf("app - newAccessLog: unknown field %q", field)
+		}
+	}
+	has := func(field string) bool {
+		return slices.Contains(cfg.Fields, field)
+	}
+
+	return middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
+		HandleError:      true,
+		LogMethod:        true,
+		LogURI:           true,
+		LogRoutePath:     true,
+		LogStatus:        true,
+		LogError:         true,
+		LogRequestID:     true,
+		LogProtocol:      true,
+		LogReferer:       true,
+		LogLatency:       has(accessLogFieldLatency),
+		LogContentLength: has(accessLogFieldBytes),
+		LogResponseSize:  has(accessLogFieldBytes),
+		LogUserAgent:     has(accessLogFieldUserAgent),
+		LogRemoteIP:      has(accessLogFieldClientIP),
+		LogValuesFunc: func(_ echo.Context, v middleware.RequestLoggerValues) error {
+			if _, err := output.Write(format(v)); err != nil {
+				log.Errorf("app - accessLog - output.Write: %v", err)
+			}
+			return nil
+		},
+	}), file, nil
+}
+
+func formatJSON(v middleware.RequestLoggerValues) []byte {
+	entry := accessLogEntry{
+		Time:      v.StartTime.Format(time.RFC3339Nano),
+		RequestId: v.RequestID,
+		Method:    v.Method,
+		URI:       v.URI,
+		Route:     v.RoutePath,
+		Status:    v.Status,
+	}
+	if v.Error != nil {
+		entry.Error = v.Error.Error()
+	}
+	if v.Latency > 0 {
+		latency := v.Latency.Milliseconds()
+		entry.LatencyMs = &latency
+	}
+	if v.ResponseSize > 0 || len(v.ContentLength) > 0 {
+		entry.BytesIn, entry.BytesOut = &v.ContentLength, &v.ResponseSize
+	}
+	if len(v.UserAgent) > 0 {
+		entry.UserAgent = &v.UserAgent
+	}
+	if len(v.RemoteIP) > 0 {
+		entry.ClientIP = &v.RemoteIP
+	}
+
+	data, _ := json.Marshal(entry)
+	return append(data, '\n')
+}
+
+// formatCombined пишет запись в формате Apache combined. Невыбранные поля заменяются на «-»,
+// а длительность, если выбрана, добавляется в конец строки.
+func formatCombined(v middleware.RequestLoggerValues) []byte {
+	dash := func(s string) string {
+		if len(s) == 0 {
+			return "-"
+		}
+		return s
+	}
+
+	bytesOut := "-"
+	if v.ResponseSize > 0 {
+		bytesOut = fmt.Sprint(v.ResponseSize)
+	}
+
+	line := fmt.Sprintf("%s - - [%s] %q %d %s %q %q",
+		dash(v.RemoteIP),
+		v.StartTime.Format(combinedTimeFormat),
+		v.Method+" "+v.URI+" "+v.Protocol,
+		v.Status,
+		bytesOut,
+		dash(v.Referer),
+		dash(v.UserAgent),
+	)
+	if v.Latency > 0 {
+		line += fmt.Sprintf(" %dms", v.Latency.Milliseconds())
+	}
+
+	return []byte(line + "\n")
+}
@@ -1,22 +1,34 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
+	"google.golang.org/grpc"
 
-	"github.com/spanwalla/song-library/config"
 	_ "github.com/spanwalla/song-library/docs"
+	"github.com/spanwalla/song-library/internal/controller/graphql"
+	grpcv1 "github.com/spanwalla/song-library/internal/controller/grpc/v1"
 	v1 "github.com/spanwalla/song-library/internal/controller/http/v1"
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/health"
+	"github.com/spanwalla/song-library/internal/metrics"
 	"github.com/spanwalla/song-library/internal/repository"
 	"github.com/spanwalla/song-library/internal/service"
 	"github.com/spanwalla/song-library/internal/webapi"
+	"github.com/spanwalla/song-library/migrations"
+	"github.com/spanwalla/song-library/pkg/grpcserver"
 	"github.com/spanwalla/song-library/pkg/httpserver"
 	"github.com/spanwalla/song-library/pkg/postgres"
+	"github.com/spanwalla/song-library/pkg/ratelimit"
+	"github.com/spanwalla/song-library/pkg/tracing"
 	"github.com/spanwalla/song-library/pkg/validator"
 )
 
@@ -26,65 +38,257 @@ import (
 // @host localhost:8080
 // @BasePath /api/v1
 
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key with read, write or admin scope
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description API key or user access token in the form "Bearer <token>"
+
 // Run creates objects via constructors
 func Run() {
 	// Config
-	configPath, ok := os.LookupEnv("CONFIG_PATH")
-	if !ok || len(configPath) == 0 {
-		log.Fatal("app - os.LookupEnv: CONFIG_PATH is empty")
-	}
-
-	cfg, err := config.New(configPath)
+	cfg, configPath, err := loadConfig()
 	if err != nil {
-		log.Fatal(fmt.Errorf("app - config.New: %w", err))
+		log.Fatal(err)
 	}
 
 	// Logger
 	initLogger(cfg.Log.Level)
 	log.Info("Config read")
+	if !cfg.Auth.Enabled {
+		log.Warn("Authentication is disabled, the API is open to anyone")
+	} else if len(cfg.JWT.Keys) == 0 {
+		log.Warn("JWT keys are not set, user login is disabled, only API keys are accepted")
+	}
+
+	// Tracing
+	tracer, err := tracing.New(cfg.App.Name, cfg.App.Version,
+		tracing.Exporter(cfg.Tracing.Exporter),
+		tracing.Endpoint(cfg.Tracing.Endpoint),
+		tracing.Insecure(cfg.Tracing.Insecure),
+		tracing.SampleRatio(cfg.Tracing.SampleRatio),
+	)
+	if err != nil {
+		log.Fatal(fmt.Errorf("app - Run - tracing.New: %w", err))
+	}
+	log.Debugf("Tracing exporter: %s", cfg.Tracing.Exporter)
 
 	// Postgres
 	log.Info("Connecting to postgres...")
-	pg, err := postgres.New(cfg.PG.URL, postgres.MaxPoolSize(cfg.PG.PoolMax))
+	pg, err := postgres.New(cfg.PG.URL,
+		postgres.MaxPoolSize(cfg.PG.PoolMax),
+		postgres.Replicas(cfg.PG.ReplicaURLs...),
+		postgres.ReplicaCheckInterval(cfg.PG.ReplicaCheckInterval),
+		postgres.ReadYourWrites(cfg.PG.ReadYourWrites),
+	)
 	if err != nil {
 		log.Fatal(fmt.Errorf("app - Run - postgres.New: %w", err))
 	}
 	defer pg.Close()
 
+	// Migrations
+	if cfg.PG.AutoMigrate {
+		log.Info("Applying migrations...")
+		if err = autoMigrate(cfg.PG.URL); err != nil {
+			log.Fatal(fmt.Errorf("app - Run - autoMigrate: %w", err))
+		}
+	}
+
+	// Metrics
+	m := metrics.New()
+	if err = m.Register(metrics.NewPoolCollector(pg.Pool)); err != nil {
+		log.Fatal(fmt.Errorf("app - Run - m.Register: %w", err))
+	}
+
 	// Services and repos
 	log.Info("Initializing services and repos...")
+	cacheCtx, stopCache := context.WithCancel(context.Background())
+	defer stopCache()
+
+	songInfo := webapi.NewSongInfoWebAPI(cfg.SongAPI.URL, cfg.SongAPI.Timeout)
+	authConfig := service.NewAuthConfig(authSettings(cfg))
 	services := service.NewServices(service.Dependencies{
-		Repos:    repository.NewRepositories(pg),
-		SongInfo: webapi.NewSongInfoWebAPI(cfg.SongAPI.URL),
+		Repos:      repository.NewRepositories(pg),
+		SongInfo:   m.SongInfo(tenantSongInfo(cfg, songInfo)),
+		Transactor: pg,
+		AuthConfig: authConfig,
+		Cache:      newCache(cacheCtx, cfg.Cache, pg),
+		CacheTTL:   cfg.Cache.TTL,
+		Primary:    postgres.WithPrimary,
 	})
+	services.Song = m.SongService(services.Song)
+
+	// Config reload
+	reloader := newConfigReloader(configPath, cfg)
+	reloader.OnReload(applyLogLevel)
+	reloader.OnReload(songAPIApplier(songInfo))
+	reloader.OnReload(authApplier(authConfig))
+
+	// Health checks
+	latestMigration, err := health.LatestMigration(migrations.FS)
+	if err != nil {
+		log.Fatal(fmt.Errorf("app - Run - health.LatestMigration: %w", err))
+	}
+
+	healthChecker := health.New(health.Timeout(cfg.Health.CheckTimeout))
+	healthChecker.Add("postgres", health.PostgresCheck(pg.Pool))
+	healthChecker.Add("migrations", health.MigrationsCheck(pg.Pool, latestMigration))
+	if cfg.Health.ProbeSongInfo {
+		healthChecker.Add("song_info", songInfo.Ping)
+	}
 
 	// Echo handler
 	log.Info("Initializing handlers and routes...")
 	handler := echo.New()
 	handler.Validator = validator.NewCustomValidator()
-	v1.ConfigureRouter(handler, services)
+	handler.IPExtractor = ipExtractor(cfg.HTTP.TrustedProxies)
+
+	accessLog, accessLogFile, err := newAccessLog(cfg.AccessLog)
+	if err != nil {
+		log.Fatal(fmt.Errorf("app - Run - newAccessLog: %w", err))
+	}
+	if accessLog != nil {
+		handler.Use(accessLog)
+	}
+
+	handler.Use(otelecho.Middleware(cfg.App.Name, otelecho.WithSkipper(func(c echo.Context) bool {
+		switch c.Path() {
+		case "/metrics", "/healthz", "/readyz":
+			return true
+		}
+		return false
+	})))
+	handler.Use(m.HTTPMiddleware())
+	handler.Use(readYourWrites(pg, cfg.PG.ReadYourWritesTTL))
+	handler.GET("/metrics", echo.WrapHandler(m.Handler()))
+	handler.GET("/healthz", healthChecker.Liveness)
+	handler.GET("/readyz", healthChecker.Readiness)
+
+	corsConfig := v1.NewCORSConfig(corsPolicy(cfg.HTTP.CORS.Read), corsPolicy(cfg.HTTP.CORS.Write))
+	reloader.OnReload(corsApplier(corsConfig))
+	routerOptions := []v1.Option{
+		v1.Auth(cfg.Auth.Enabled),
+		v1.TenantDomain(cfg.Tenants.BaseDomain),
+		v1.CORS(corsConfig),
+	}
+	var graphqlMiddleware []echo.MiddlewareFunc
+	if cfg.Auth.Enabled {
+		graphqlMiddleware = append(graphqlMiddleware, v1.RequireScope(services.Auth, entity.ScopeRead))
+	}
+	if cfg.RateLimit.Enabled {
+		var store ratelimit.Store = ratelimit.NewMemoryStore()
+		if cfg.RateLimit.Store == "postgres" {
+			store = repository.NewRateLimitRepo(pg)
+		}
+		limiter := ratelimit.New(store)
+		limits := v1.NewRateLimitConfig(rateLimits(cfg))
+		reloader.OnReload(rateLimitApplier(limits))
+		routerOptions = append(routerOptions, v1.Limits(limiter, limits))
+		graphqlMiddleware = append(graphqlMiddleware, v1.RateLimit(limiter, v1.BudgetRead, limits))
+	}
+
+	v1.ConfigureRouter(handler, services, routerOptions...)
+	if err = graphql.ConfigureRouter(handler, services, graphqlMiddleware...); err != nil {
+		log.Fatal(fmt.Errorf("app - Run - graphql.ConfigureRouter: %w", err))
+	}
 
 	// HTTP Server
 	log.Info("Starting HTTP server...")
-	log.Debugf("Server port: %s", cfg.HTTP.Port)
-	httpServer := httpserver.New(handler, httpserver.Port(cfg.HTTP.Port))
+	log.Debugf("Server address: %s:%s, TLS: %t", cfg.HTTP.Host, cfg.HTTP.Port, len(cfg.HTTP.TLSCertFile) > 0)
+	httpServer := httpserver.New(handler,
+		httpserver.Host(cfg.HTTP.Host),
+		httpserver.Port(cfg.HTTP.Port),
+		httpserver.ReadTimeout(cfg.HTTP.ReadTimeout),
+		httpserver.ReadHeaderTimeout(cfg.HTTP.ReadHeaderTimeout),
+		httpserver.WriteTimeout(cfg.HTTP.WriteTimeout),
+		httpserver.IdleTimeout(cfg.HTTP.IdleTimeout),
+		httpserver.ShutdownTimeout(cfg.HTTP.ShutdownTimeout),
+		httpserver.MaxHeaderBytes(cfg.HTTP.MaxHeaderBytes),
+		httpserver.TLS(cfg.HTTP.TLSCertFile, cfg.HTTP.TLSKeyFile),
+		httpserver.HTTP2(cfg.HTTP.HTTP2),
+		httpserver.H2C(cfg.HTTP.H2C),
+	)
+
+	// gRPC Server
+	log.Info("Starting gRPC server...")
+	log.Debugf("gRPC server port: %s", cfg.GRPC.Port)
+	unaryInterceptors := []grpc.UnaryServerInterceptor{grpcv1.UnaryRequestLogger(), grpcv1.UnaryContext(pg.WithReadYourWrites), grpcv1.UnaryTenant(services.Tenant)}
+	streamInterceptors := []grpc.StreamServerInterceptor{grpcv1.StreamRequestLogger(), grpcv1.StreamContext(pg.WithReadYourWrites), grpcv1.StreamTenant(services.Tenant)}
+	if cfg.Auth.Enabled {
+		unaryInterceptors = append(unaryInterceptors, grpcv1.UnaryAuth(services.Auth))
+		streamInterceptors = append(streamInterceptors, grpcv1.StreamAuth(services.Auth))
+	}
+	grpcServer := grpcserver.New(func(s *grpc.Server) {
+		grpcv1.ConfigureServer(s, services, handler.Validator)
+	}, grpcserver.Port(cfg.GRPC.Port), grpcserver.ServerOptions(
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+	))
 
 	log.Info("Configuring graceful shutdown...")
 	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
 
-	select {
-	case s := <-interrupt:
-		log.Info("app - Run - signal: " + s.String())
-	case err = <-httpServer.Notify():
-		log.Errorf("app - Run - httpServer.Notify: %v", err)
+	// SIGHUP перечитывает конфигурацию и переоткрывает журнал запросов после того,
+	// как logrotate переместил файл.
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	stopWatch := make(chan struct{})
+	defer close(stopWatch)
+	go reloader.Watch(cfg.App.ReloadInterval, stopWatch)
+
+wait:
+	for {
+		select {
+		case s := <-interrupt:
+			log.Info("app - Run - signal: " + s.String())
+			break wait
+		case <-hangup:
+			reloader.Reload()
+			if accessLogFile != nil {
+				if err = accessLogFile.Reopen(); err != nil {
+					log.Errorf("app - Run - accessLogFile.Reopen: %v", err)
+				}
+			}
+		case err = <-httpServer.Notify():
+			log.Errorf("app - Run - httpServer.Notify: %v", err)
+			break wait
+		case err = <-grpcServer.Notify():
+			log.Errorf("app - Run - grpcServer.Notify: %v", err)
+			break wait
+		}
 	}
 
 	// Graceful shutdown
 	log.Info("Shutting down...")
 
+	// Сначала сообщаем о неготовности и ждём, пока балансировщик перестанет присылать запросы.
+	healthChecker.Shutdown()
+	time.Sleep(cfg.Health.ShutdownDelay)
+
 	err = httpServer.Shutdown()
 	if err != nil {
 		log.Errorf("app - Run - httpServer.Shutdown: %v", err)
 	}
+
+	err = grpcServer.Shutdown()
+	if err != nil {
+		log.Errorf("app - Run - grpcServer.Shutdown: %v", err)
+	}
+
+	err = tracer.Shutdown()
+	if err != nil {
+		log.Errorf("app - Run - tracer.Shutdown: %v", err)
+	}
+
+	if accessLogFile != nil {
+		if err = accessLogFile.Close(); err != nil {
+			log.Errorf("app - Run - accessLogFile.Close: %v", err)
+		}
+	}
 }
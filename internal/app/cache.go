@@ -0,0 +1,27 @@
+package app
+
+import (
+	"context"
+
+	"github.com/spanwalla/song-library/config"
+	"github.com/spanwalla/song-library/internal/repository"
+	"github.com/spanwalla/song-library/pkg/cache"
+	"github.com/spanwalla/song-library/pkg/postgres"
+)
+
+// newCache возвращает кэш песен или nil, если он отключён. При invalidation = postgres
+// кэш получает удаления других экземпляров, пока не отменён ctx.
+func newCache(ctx context.Context, cfg config.Cache, pg *postgres.Postgres) cache.Cache {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	lru := cache.NewLRU(cfg.Size)
+	if cfg.Invalidation != "postgres" {
+		return lru
+	}
+
+	broadcast := cache.NewBroadcast(lru, repository.NewCacheNotifier(pg))
+	go broadcast.Run(ctx)
+	return broadcast
+}
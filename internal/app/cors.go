@@ -0,0 +1,25 @@
+package app
+
+import (
+	"github.com/spanwalla/song-library/config"
+	v1 "github.com/spanwalla/song-library/internal/controller/http/v1"
+)
+
+// corsPolicy переносит политику CORS из конфигурации в настройки маршрутов.
+func corsPolicy(p config.CORSPolicy) v1.CORSPolicy {
+	return v1.CORSPolicy{
+		AllowOrigins:     p.AllowOrigins,
+		AllowMethods:     p.AllowMethods,
+		AllowHeaders:     p.AllowHeaders,
+		ExposeHeaders:    p.ExposeHeaders,
+		AllowCredentials: p.AllowCredentials,
+		MaxAge:           p.MaxAge,
+	}
+}
+
+// corsApplier применяет новые политики CORS к следующим запросам.
+func corsApplier(corsConfig *v1.CORSConfig) func(cfg *config.Config) {
+	return func(cfg *config.Config) {
+		corsConfig.Set(corsPolicy(cfg.HTTP.CORS.Read), corsPolicy(cfg.HTTP.CORS.Write))
+	}
+}
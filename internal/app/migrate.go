@@ -4,12 +4,17 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 	log "github.com/sirupsen/logrus"
+
+	"github.com/spanwalla/song-library/config"
+	"github.com/spanwalla/song-library/migrations"
 )
 
 const (
@@ -17,50 +22,227 @@ const (
 	defaultTimeout  = time.Second
 )
 
-func init() {
-	databaseURL, ok := os.LookupEnv("PG_URL")
-	if !ok || len(databaseURL) == 0 {
-		log.Fatal("migrate: environment variable PG_URL not set")
+const migrateUsage = `usage: app migrate <command>
+
+commands:
+  up           apply all pending migrations
+  down [n]     roll back n migrations (1 by default)
+  status       show applied and pending migrations
+  force <v>    set version v without running migrations and clear the dirty flag
+  version      print the current version`
+
+var errMigrateUsage = errors.New(migrateUsage)
+
+// Migrate выполняет подкоманду migrate и возвращает код завершения процесса.
+func Migrate(args []string) int {
+	command, err := parseMigrateCommand(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	cfg, _, err := loadConfig()
+	if err != nil {
+		log.Error(err)
+		return 1
+	}
+	initLogger(cfg.Log.Level)
+
+	m, err := newMigrate(cfg.PG.URL, 1)
+	if err != nil {
+		log.Error(fmt.Errorf("app - Migrate - newMigrate: %w", err))
+		return 1
+	}
+	defer closeMigrate(m)
+
+	err = command(m)
+	if errors.Is(err, migrate.ErrNoChange) {
+		log.Info("migrate: no database change")
+		return 0
+	}
+	if err != nil {
+		log.Error(fmt.Errorf("app - Migrate - %s: %w", args[0], err))
+		return 1
+	}
+
+	return 0
+}
+
+// parseMigrateCommand проверяет аргументы до подключения к базе, чтобы опечатка
+// в команде не требовала доступной базы.
+func parseMigrateCommand(args []string) (func(m *migrate.Migrate) error, error) {
+	if len(args) == 0 {
+		return nil, errMigrateUsage
+	}
+
+	command, args := args[0], args[1:]
+	switch {
+	case command == "up" && len(args) == 0:
+		return (*migrate.Migrate).Up, nil
+	case command == "down" && len(args) <= 1:
+		n := 1
+		if len(args) == 1 {
+			var err error
+			if n, err = strconv.Atoi(args[0]); err != nil || n <= 0 {
+				return nil, errMigrateUsage
+			}
+		}
+		return func(m *migrate.Migrate) error {
+			return m.Steps(-n)
+		}, nil
+	case command == "force" && len(args) == 1:
+		version, err := strconv.Atoi(args[0])
+		if err != nil {
+			return nil, errMigrateUsage
+		}
+		return func(m *migrate.Migrate) error {
+			return m.Force(version)
+		}, nil
+	case command == "version" && len(args) == 0:
+		return printVersion, nil
+	case command == "status" && len(args) == 0:
+		return printStatus, nil
+	default:
+		return nil, errMigrateUsage
+	}
+}
+
+func printVersion(m *migrate.Migrate) error {
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		fmt.Println("no migrations applied")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d%s\n", version, dirtySuffix(dirty))
+	return nil
+}
+
+// printStatus выводит все встроенные миграции с отметкой, применена ли каждая из них.
+func printStatus(m *migrate.Migrate) error {
+	current, dirty, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return err
+	}
+	applied := err == nil
+
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = src.Close()
+	}()
+
+	version, err := src.First()
+	for err == nil {
+		state := "pending"
+		if applied && version <= current {
+			state = "applied"
+		}
+		if applied && version == current && dirty {
+			state = "dirty"
+		}
+
+		name := ""
+		if r, identifier, readErr := src.ReadUp(version); readErr == nil {
+			_ = r.Close()
+			name = identifier
+		}
+		fmt.Printf("%d\t%-8s\t%s\n", version, state, name)
+
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func dirtySuffix(dirty bool) string {
+	if dirty {
+		return " (dirty)"
+	}
+	return ""
+}
+
+// autoMigrate применяет все миграции при запуске serve. База может ещё подниматься,
+// поэтому подключение повторяется несколько раз.
+func autoMigrate(databaseURL string) error {
+	m, err := newMigrate(databaseURL, defaultAttempts)
+	if err != nil {
+		return err
+	}
+	defer closeMigrate(m)
+
+	err = m.Up()
+	if errors.Is(err, migrate.ErrNoChange) {
+		log.Info("migrate: no database change")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("migrate: up error: %w", err)
 	}
 
-	databaseURL += "?sslmode=disable"
+	log.Info("migrate: up success")
+	return nil
+}
 
+// newMigrate подключается к базе с миграциями из migrations.FS. Параметры подключения,
+// в том числе sslmode, берутся из DSN как есть.
+func newMigrate(databaseURL string, attempts int) (*migrate.Migrate, error) {
 	var (
-		attempts = defaultAttempts
-		err      error
-		m        *migrate.Migrate
+		src source.Driver
+		m   *migrate.Migrate
+		err error
 	)
 
 	for attempts > 0 {
-		m, err = migrate.New("file://migrations", databaseURL)
+		src, err = iofs.New(migrations.FS, ".")
+		if err != nil {
+			return nil, fmt.Errorf("migrate: source error: %w", err)
+		}
+
+		m, err = migrate.NewWithSourceInstance("iofs", src, databaseURL)
 		if err == nil {
-			break
+			return m, nil
 		}
 
-		log.Infof("migrate: postgres is trying to connect, attempts left: %d", attempts)
-		time.Sleep(defaultTimeout)
 		attempts--
+		if attempts > 0 {
+			log.Infof("migrate: postgres is trying to connect, attempts left: %d", attempts)
+			time.Sleep(defaultTimeout)
+		}
 	}
 
-	if err != nil {
-		log.Fatalf("migrate: postgres connect error: %s", err)
+	return nil, fmt.Errorf("migrate: postgres connect error: %w", err)
+}
+
+func closeMigrate(m *migrate.Migrate) {
+	srcErr, dbErr := m.Close()
+	if srcErr != nil {
+		log.Errorf("migrate: source close error: %s", srcErr)
+	}
+	if dbErr != nil {
+		log.Errorf("migrate: postgres close error: %s", dbErr)
 	}
+}
 
-	err = m.Up()
-	defer func(m *migrate.Migrate) {
-		err, _ = m.Close()
-		if err != nil {
-			log.Fatalf("migrate: postgres close error: %s", err)
-		}
-	}(m)
-	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		panic(fmt.Errorf("migrate: up error: %w", err))
+// loadConfig читает конфигурацию из файла CONFIG_PATH и возвращает её вместе с путём к файлу.
+func loadConfig() (*config.Config, string, error) {
+	configPath, ok := os.LookupEnv("CONFIG_PATH")
+	if !ok || len(configPath) == 0 {
+		return nil, "", errors.New("app - os.LookupEnv: CONFIG_PATH is empty")
 	}
 
-	if errors.Is(err, migrate.ErrNoChange) {
-		log.Infof("migrate: no database change")
-		return
+	cfg, err := config.New(configPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("app - config.New: %w", err)
 	}
 
-	log.Info("migrate: up success")
+	return cfg, configPath, nil
 }
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/spanwalla/song-library/pkg/postgres"
+)
+
+// readYourWritesCookie хранит время в секундах Unix, до которого чтение клиента идёт
+// с основного сервера.
+const readYourWritesCookie = "read_your_writes"
+
+// readYourWrites направляет чтение запроса на основной сервер после того, как запрос
+// записал данные, см. postgres.WithReadYourWrites. Чтобы следующие запросы клиента тоже
+// увидели запись, ответ ставит cookie на ttl, пока реплики догоняют основной сервер.
+// ttl = 0 отключает cookie.
+func readYourWrites(pg *postgres.Postgres, ttl time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx := pg.WithReadYourWrites(req.Context())
+			c.SetRequest(req.WithContext(ctx))
+			if ttl <= 0 {
+				return next(c)
+			}
+
+			if cookie, err := c.Cookie(readYourWritesCookie); err == nil {
+				now := time.Now()
+				// Срок больше ttl клиент мог поставить только сам, такой cookie не учитывается.
+				until, err := strconv.ParseInt(cookie.Value, 10, 64)
+				if err == nil && now.Unix() < until && until <= now.Add(ttl).Unix()+1 {
+					postgres.Pin(ctx)
+				}
+			}
+
+			c.Response().Before(func() {
+				if !postgres.Wrote(ctx) {
+					return
+				}
+				c.SetCookie(&http.Cookie{
+					Name:     readYourWritesCookie,
+					Value:    strconv.FormatInt(time.Now().Add(ttl).Unix()+1, 10),
+					Path:     "/",
+					MaxAge:   int((ttl + time.Second - 1) / time.Second),
+					Secure:   c.IsTLS(),
+					HttpOnly: true,
+					SameSite: http.SameSiteLaxMode,
+				})
+			})
+
+			return next(c)
+		}
+	}
+}
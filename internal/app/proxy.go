@@ -0,0 +1,28 @@
+package app
+
+import (
+	"net"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ipExtractor определяет адрес клиента. Без доверенных прокси берётся адрес соединения,
+// иначе — последний адрес в X-Forwarded-For, не принадлежащий доверенным сетям.
+// Заголовок от остальных клиентов не учитывается, чтобы его нельзя было подделать.
+func ipExtractor(trustedProxies []string) echo.IPExtractor {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, proxy := range trustedProxies {
+		// Адреса проверены при загрузке конфигурации.
+		_, ipNet, _ := net.ParseCIDR(proxy)
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
@@ -0,0 +1,199 @@
+package app
+
+import (
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/spanwalla/song-library/config"
+	v1 "github.com/spanwalla/song-library/internal/controller/http/v1"
+	"github.com/spanwalla/song-library/internal/service"
+	"github.com/spanwalla/song-library/pkg/ratelimit"
+)
+
+// configReloader перечитывает конфигурацию по SIGHUP или при изменении файла и применяет
+// настройки, которые безопасно менять на лету. Остальные изменения только сообщаются в журнал.
+type configReloader struct {
+	path string
+
+	mu       sync.Mutex
+	current  *config.Config
+	modTime  time.Time
+	appliers []func(cfg *config.Config)
+}
+
+func newConfigReloader(path string, cfg *config.Config) *configReloader {
+	r := &configReloader{path: path, current: cfg}
+	if info, err := os.Stat(path); err == nil {
+		r.modTime = info.ModTime()
+	}
+	return r
+}
+
+// OnReload регистрирует функцию, которая применяет новую конфигурацию к работающему компоненту.
+func (r *configReloader) OnReload(apply func(cfg *config.Config)) {
+	r.appliers = append(r.appliers, apply)
+}
+
+// Reload читает и проверяет конфигурацию. Если она некорректна, текущая остаётся в силе.
+func (r *configReloader) Reload() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cfg, err := config.New(r.path)
+	if err != nil {
+		log.Errorf("app - configReloader - config rejected, keeping current: %v", err)
+		return
+	}
+
+	for _, section := range restartRequired(r.current, cfg) {
+		log.Warnf("app - configReloader - %s changed, restart required to apply", section)
+	}
+
+	// Секции, требующие перезапуска, остаются прежними, чтобы следующая перезагрузка
+	// снова сообщила о них, а не считала их применёнными.
+	applied := *r.current
+	applied.Log = cfg.Log
+	applied.SongAPI = cfg.SongAPI
+	applied.Auth.AllowRegistration = cfg.Auth.AllowRegistration
+	applied.JWT = cfg.JWT
+	applied.HTTP.CORS = cfg.HTTP.CORS
+	applied.RateLimit.Window = cfg.RateLimit.Window
+	applied.RateLimit.Read = cfg.RateLimit.Read
+	applied.RateLimit.Write = cfg.RateLimit.Write
+	applied.RateLimit.Insert = cfg.RateLimit.Insert
+	for _, apply := range r.appliers {
+		apply(&applied)
+	}
+	r.current = &applied
+
+	log.Info("app - configReloader - config reloaded")
+}
+
+// Watch проверяет время изменения файла конфигурации с заданным интервалом и перечитывает его.
+// Интервал 0 отключает наблюдение. Функция блокируется до закрытия done.
+func (r *configReloader) Watch(interval time.Duration, done <-chan struct{}) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			info, err := os.Stat(r.path)
+			if err != nil {
+				log.Errorf("app - configReloader - os.Stat: %v", err)
+				continue
+			}
+			if info.ModTime().Equal(r.modTime) {
+				continue
+			}
+			r.modTime = info.ModTime()
+			r.Reload()
+		}
+	}
+}
+
+// restartRequired возвращает имена секций, изменение которых нельзя применить на лету.
+func restartRequired(current, next *config.Config) []string {
+	sections := []struct {
+		name          string
+		current, next any
+	}{
+		{"app", current.App, next.App},
+		{"http", withoutCORS(current.HTTP), withoutCORS(next.HTTP)},
+		{"grpc", current.GRPC, next.GRPC},
+		{"postgres", current.PG, next.PG},
+		{"tracing", current.Tracing, next.Tracing},
+		{"health", current.Health, next.Health},
+		{"access_log", current.AccessLog, next.AccessLog},
+		{"auth.enabled", current.Auth.Enabled, next.Auth.Enabled},
+		{"rate_limit.enabled", current.RateLimit.Enabled, next.RateLimit.Enabled},
+		{"rate_limit.store", current.RateLimit.Store, next.RateLimit.Store},
+		{"tenants", current.Tenants, next.Tenants},
+		{"cache", current.Cache, next.Cache},
+	}
+
+	var changed []string
+	for _, s := range sections {
+		if !reflect.DeepEqual(s.current, s.next) {
+			changed = append(changed, s.name)
+		}
+	}
+	return changed
+}
+
+// withoutCORS убирает из секции http политики CORS, которые применяются на лету.
+func withoutCORS(h config.HTTP) config.HTTP {
+	h.CORS = config.CORS{}
+	return h
+}
+
+func applyLogLevel(cfg *config.Config) {
+	level, err := log.ParseLevel(cfg.Log.Level)
+	if err != nil {
+		return
+	}
+	if level != log.GetLevel() {
+		log.Infof("app - configReloader - log level: %s", level)
+		log.SetLevel(level)
+	}
+}
+
+func songAPIApplier(songInfo interface {
+	Configure(url string, timeout time.Duration)
+}) func(cfg *config.Config) {
+	return func(cfg *config.Config) {
+		songInfo.Configure(cfg.SongAPI.URL, cfg.SongAPI.Timeout)
+	}
+}
+
+// authSettings собирает настройки входа пользователей из конфигурации.
+func authSettings(cfg *config.Config) service.AuthSettings {
+	settings := service.AuthSettings{
+		AllowRegistration: cfg.Auth.AllowRegistration,
+		AccessTTL:         cfg.JWT.AccessTTL,
+		RefreshTTL:        cfg.JWT.RefreshTTL,
+	}
+	for _, key := range cfg.JWT.Keys {
+		id, secret, _ := config.SplitSigningKey(key)
+		settings.SigningKeys = append(settings.SigningKeys, service.SigningKey{Id: id, Secret: []byte(secret)})
+	}
+	for slug, override := range cfg.Tenants.Overrides {
+		if override.AllowRegistration {
+			settings.RegistrationTenants = append(settings.RegistrationTenants, slug)
+		}
+	}
+	return settings
+}
+
+// authApplier применяет новые ключи подписи и сроки токенов. Токены, подписанные ключом,
+// которого больше нет в списке, перестают приниматься.
+func authApplier(authConfig *service.AuthConfig) func(cfg *config.Config) {
+	return func(cfg *config.Config) {
+		authConfig.Set(authSettings(cfg))
+	}
+}
+
+// rateLimits собирает бюджеты запросов из конфигурации.
+func rateLimits(cfg *config.Config) v1.RateLimits {
+	return v1.RateLimits{
+		Read:   ratelimit.Limit{Requests: cfg.RateLimit.Read, Window: cfg.RateLimit.Window},
+		Write:  ratelimit.Limit{Requests: cfg.RateLimit.Write, Window: cfg.RateLimit.Window},
+		Insert: ratelimit.Limit{Requests: cfg.RateLimit.Insert, Window: cfg.RateLimit.Window},
+	}
+}
+
+func rateLimitApplier(limits *v1.RateLimitConfig) func(cfg *config.Config) {
+	return func(cfg *config.Config) {
+		limits.Set(rateLimits(cfg))
+	}
+}
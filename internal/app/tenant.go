@@ -0,0 +1,21 @@
+package app
+
+import (
+	"github.com/spanwalla/song-library/config"
+	"github.com/spanwalla/song-library/internal/webapi"
+)
+
+// tenantSongInfo подключает отдельные внешние API для арендаторов, у которых они заданы
+// в tenants.overrides. Эти адреса применяются только при запуске.
+func tenantSongInfo(cfg *config.Config, fallback webapi.SongInfo) webapi.SongInfo {
+	apis := cfg.Tenants.SongAPIs(cfg.SongAPI)
+	if len(apis) == 0 {
+		return fallback
+	}
+
+	tenants := make(map[string]webapi.SongInfo, len(apis))
+	for slug, api := range apis {
+		tenants[slug] = webapi.NewSongInfoWebAPI(api.URL, api.Timeout)
+	}
+	return webapi.NewTenantSongInfo(fallback, tenants)
+}
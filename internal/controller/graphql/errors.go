@@ -0,0 +1,89 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/service"
+	"github.com/spanwalla/song-library/pkg/validator"
+)
+
+// Коды ошибок в extensions.code.
+const (
+	CodeBadUserInput = "BAD_USER_INPUT"
+	CodeNotFound     = "NOT_FOUND"
+	CodeForbidden    = "FORBIDDEN"
+	CodeBadGateway   = "BAD_GATEWAY"
+	CodeInternal     = "INTERNAL_SERVER_ERROR"
+)
+
+var serviceErrorCodes = []struct {
+	err  error
+	code string
+}{
+	{service.ErrSongNotFound, CodeNotFound},
+	{service.ErrTranslationNotFound, CodeNotFound},
+	{service.ErrLineNotFound, CodeNotFound},
+	{service.ErrFieldsAreEmpty, CodeBadUserInput},
+	{service.ErrInvalidLRC, CodeBadUserInput},
+	{service.ErrTranslationMismatch, CodeBadUserInput},
+	{service.ErrCannotGetSongInfo, CodeBadGateway},
+	{service.ErrPermissionDenied, CodeForbidden},
+}
+
+// resolverError реализует gqlerrors.ExtendedError, чтобы клиент получил код ошибки в extensions.
+type resolverError struct {
+	message    string
+	extensions map[string]any
+}
+
+func (e *resolverError) Error() string {
+	return e.message
+}
+
+func (e *resolverError) Extensions() map[string]any {
+	return e.extensions
+}
+
+func newError(err error) error {
+	var validationErr *validator.ValidationError
+	if errors.As(err, &validationErr) {
+		fields := make([]string, 0, len(validationErr.Fields))
+		for _, field := range validationErr.Fields {
+			fields = append(fields, field.Field)
+		}
+		return &resolverError{
+			message:    validationErr.Error(),
+			extensions: map[string]any{"code": CodeBadUserInput, "fields": fields},
+		}
+	}
+
+	code := CodeInternal
+	for _, e := range serviceErrorCodes {
+		if errors.Is(err, e.err) {
+			code = e.code
+			break
+		}
+	}
+
+	return &resolverError{
+		message:    err.Error(),
+		extensions: map[string]any{"code": code},
+	}
+}
+
+// requireScope проверяет уровень доступа участника из контекста. Участника нет,
+// только если аутентификация отключена, и тогда проверка не нужна.
+func requireScope(ctx context.Context, scope entity.Scope) error {
+	principal, ok := service.PrincipalFrom(ctx)
+	if !ok || principal.HasScope(scope) {
+		return nil
+	}
+
+	return &resolverError{
+		message:    fmt.Sprintf("credentials do not have the %s scope", scope),
+		extensions: map[string]any{"code": CodeForbidden},
+	}
+}
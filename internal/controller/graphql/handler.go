@@ -0,0 +1,85 @@
+package graphql
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/labstack/echo/v4"
+
+	"github.com/spanwalla/song-library/internal/service"
+)
+
+type request struct {
+	Query         string         `json:"query" query:"query"`
+	OperationName string         `json:"operationName" query:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+type handler struct {
+	schema      graphql.Schema
+	songService service.Song
+}
+
+// ConfigureRouter регистрирует эндпоинт /graphql. Middleware m применяется к эндпоинту,
+// например, для проверки API-ключа; мутации дополнительно требуют уровня write.
+func ConfigureRouter(e *echo.Echo, services *service.Services, m ...echo.MiddlewareFunc) error {
+	r := &resolver{songService: services.Song, validator: e.Validator}
+
+	schema, err := newSchema(r)
+	if err != nil {
+		return fmt.Errorf("graphql - ConfigureRouter - newSchema: %w", err)
+	}
+
+	h := &handler{schema: schema, songService: services.Song}
+	e.POST("/graphql", h.serve, m...)
+	e.GET("/graphql", h.serve, m...)
+
+	return nil
+}
+
+func (h *handler) serve(c echo.Context) error {
+	var req request
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	// Мутации по GET не выполняем, чтобы их нельзя было вызвать простой ссылкой.
+	if c.Request().Method == http.MethodGet && isMutation(req.Query, req.OperationName) {
+		return echo.NewHTTPError(http.StatusMethodNotAllowed, "mutations are only allowed with POST")
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        withLoaders(c.Request().Context(), h.songService),
+	})
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func isMutation(query, operationName string) bool {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return false
+	}
+
+	for _, definition := range doc.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if len(operationName) > 0 && (operation.Name == nil || operation.Name.Value != operationName) {
+			continue
+		}
+		if operation.Operation == ast.OperationTypeMutation {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,90 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/spanwalla/song-library/internal/service"
+)
+
+// lyricsPage определяет страницу текста, которую запросили у нескольких песен сразу.
+type lyricsPage struct {
+	offset int
+	limit  int
+}
+
+// lyricsBatch собирает идентификаторы песен для одной страницы и загружает их тексты одним запросом.
+type lyricsBatch struct {
+	songIds []int
+	once    sync.Once
+	texts   map[int]service.Text
+	err     error
+}
+
+// lyricsLoader откладывает загрузку текстов до тех пор, пока исполнитель GraphQL не соберёт
+// все поля списка песен, а затем загружает их пачкой. Живёт в пределах одного запроса.
+type lyricsLoader struct {
+	songService service.Song
+
+	mu      sync.Mutex
+	pending map[lyricsPage]*lyricsBatch
+}
+
+func newLyricsLoader(songService service.Song) *lyricsLoader {
+	return &lyricsLoader{
+		songService: songService,
+		pending:     make(map[lyricsPage]*lyricsBatch),
+	}
+}
+
+// Load регистрирует песню в текущей пачке и возвращает функцию, которая вернёт её текст.
+func (l *lyricsLoader) Load(ctx context.Context, songId, offset, limit int) func() (service.Text, error) {
+	page := lyricsPage{offset: offset, limit: limit}
+
+	l.mu.Lock()
+	batch, ok := l.pending[page]
+	if !ok {
+		batch = &lyricsBatch{}
+		l.pending[page] = batch
+	}
+	batch.songIds = append(batch.songIds, songId)
+	l.mu.Unlock()
+
+	return func() (service.Text, error) {
+		batch.once.Do(func() {
+			// Следующие вызовы Load для этой страницы начнут новую пачку.
+			l.mu.Lock()
+			if l.pending[page] == batch {
+				delete(l.pending, page)
+			}
+			songIds := batch.songIds
+			l.mu.Unlock()
+
+			batch.texts, batch.err = l.songService.GetTexts(ctx, service.GetTextsInput{
+				SongIds: songIds,
+				Offset:  page.offset,
+				Limit:   page.limit,
+			})
+		})
+		if batch.err != nil {
+			return service.Text{}, batch.err
+		}
+
+		text, ok := batch.texts[songId]
+		if !ok {
+			return service.Text{Couplets: []string{}}, nil
+		}
+		return text, nil
+	}
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, songService service.Song) context.Context {
+	return context.WithValue(ctx, loadersKey{}, newLyricsLoader(songService))
+}
+
+func lyricsLoaderFrom(ctx context.Context) *lyricsLoader {
+	loader, _ := ctx.Value(loadersKey{}).(*lyricsLoader)
+	return loader
+}
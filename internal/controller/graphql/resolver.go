@@ -0,0 +1,242 @@
+package graphql
+
+import (
+	"errors"
+
+	"github.com/graphql-go/graphql"
+	"github.com/labstack/echo/v4"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/service"
+)
+
+type idInput struct {
+	Id int `json:"id" validate:"number,gt=0"`
+}
+
+type insertSongInput struct {
+	Group string `json:"group" validate:"required,max=128"`
+	Song  string `json:"song" validate:"required,max=128"`
+}
+
+type updateInput struct {
+	Id          int     `json:"id" validate:"number,gt=0"`
+	Group       *string `json:"group" validate:"omitempty,max=128"`
+	Song        *string `json:"song" validate:"omitempty,max=128"`
+	Link        *string `json:"link" validate:"omitempty,max=128,uri"`
+	ReleaseDate *string `json:"releaseDate" validate:"omitempty,date"`
+}
+
+type updateTextInput struct {
+	Id   int    `json:"id" validate:"number,gt=0"`
+	Text string `json:"text" validate:"required"`
+}
+
+type resolver struct {
+	songService service.Song
+	validator   echo.Validator
+}
+
+type lyrics struct {
+	Text  []string `json:"text"`
+	Count int      `json:"count"`
+}
+
+func (r *resolver) song(p graphql.ResolveParams) (any, error) {
+	input := idInput{Id: p.Args["id"].(int)}
+	if err := r.validator.Validate(input); err != nil {
+		return nil, newError(err)
+	}
+
+	song, err := r.songService.Get(p.Context, input.Id)
+	if err != nil {
+		if errors.Is(err, service.ErrSongNotFound) {
+			return nil, nil
+		}
+		return nil, newError(err)
+	}
+
+	return song, nil
+}
+
+func (r *resolver) songs(p graphql.ResolveParams) (any, error) {
+	input := service.SearchSongInput{
+		Filters: make(map[string]string),
+		Offset:  p.Args["offset"].(int),
+		Limit:   p.Args["limit"].(int),
+	}
+
+	if filter, ok := p.Args["filter"].(map[string]any); ok {
+		for field, value := range filter {
+			if s, ok := value.(string); ok {
+				input.Filters[field] = s
+			}
+		}
+	}
+
+	if orderBy, ok := p.Args["orderBy"].([]any); ok {
+		for _, item := range orderBy {
+			criteria, _ := item.(map[string]any)
+			field, _ := criteria["field"].(string)
+			order, _ := criteria["order"].(string)
+			input.OrderBy = append(input.OrderBy, []string{field, order})
+		}
+	}
+
+	songs, err := r.songService.Search(p.Context, input)
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	return songs, nil
+}
+
+func (r *resolver) releaseDate(p graphql.ResolveParams) (any, error) {
+	song, _ := p.Source.(entity.Song)
+	return song.ReleaseDate.Format(dateLayout), nil
+}
+
+// lyrics не обращается к сервису сразу, а возвращает отложенное значение:
+// тексты всех песен списка загружаются одним запросом через lyricsLoader.
+func (r *resolver) lyrics(p graphql.ResolveParams) (any, error) {
+	song, _ := p.Source.(entity.Song)
+
+	loader := lyricsLoaderFrom(p.Context)
+	if loader == nil {
+		loader = newLyricsLoader(r.songService)
+	}
+
+	thunk := loader.Load(p.Context, song.Id, p.Args["offset"].(int), p.Args["limit"].(int))
+
+	return func() (any, error) {
+		text, err := thunk()
+		if err != nil {
+			return nil, newError(err)
+		}
+		return lyrics{Text: text.Couplets, Count: text.Count}, nil
+	}, nil
+}
+
+func (r *resolver) related(p graphql.ResolveParams) (any, error) {
+	song, _ := p.Source.(entity.Song)
+	limit := p.Args["limit"].(int)
+
+	// Запрашиваем на одну песню больше, так как в выборку попадёт и текущая.
+	songs, err := r.songService.Search(p.Context, service.SearchSongInput{
+		Filters: map[string]string{"group": song.Group},
+		OrderBy: [][]string{{"releaseDate", "desc"}},
+		Limit:   limit + 1,
+	})
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	related := make([]entity.Song, 0, len(songs))
+	for _, s := range songs {
+		if s.Id != song.Id && len(related) < limit {
+			related = append(related, s)
+		}
+	}
+
+	return related, nil
+}
+
+func (r *resolver) insertSong(p graphql.ResolveParams) (any, error) {
+	if err := requireScope(p.Context, entity.ScopeWrite); err != nil {
+		return nil, err
+	}
+
+	input := insertSongInput{
+		Group: p.Args["group"].(string),
+		Song:  p.Args["song"].(string),
+	}
+	if err := r.validator.Validate(input); err != nil {
+		return nil, newError(err)
+	}
+
+	err := r.songService.Insert(p.Context, service.InsertSongInput{
+		Group: input.Group,
+		Song:  input.Song,
+	})
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	return true, nil
+}
+
+func (r *resolver) updateSong(p graphql.ResolveParams) (any, error) {
+	if err := requireScope(p.Context, entity.ScopeWrite); err != nil {
+		return nil, err
+	}
+
+	fields, _ := p.Args["input"].(map[string]any)
+	optional := func(name string) *string {
+		if value, ok := fields[name].(string); ok {
+			return &value
+		}
+		return nil
+	}
+
+	input := updateInput{
+		Id:          p.Args["id"].(int),
+		Group:       optional("group"),
+		Song:        optional("song"),
+		Link:        optional("link"),
+		ReleaseDate: optional("releaseDate"),
+	}
+	if err := r.validator.Validate(input); err != nil {
+		return nil, newError(err)
+	}
+
+	err := r.songService.Update(p.Context, input.Id, service.UpdateSongInput{
+		Name:        input.Song,
+		Group:       input.Group,
+		Link:        input.Link,
+		ReleaseDate: input.ReleaseDate,
+	})
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	return true, nil
+}
+
+func (r *resolver) updateSongText(p graphql.ResolveParams) (any, error) {
+	if err := requireScope(p.Context, entity.ScopeWrite); err != nil {
+		return nil, err
+	}
+
+	input := updateTextInput{
+		Id:   p.Args["id"].(int),
+		Text: p.Args["text"].(string),
+	}
+	if err := r.validator.Validate(input); err != nil {
+		return nil, newError(err)
+	}
+
+	err := r.songService.UpdateText(p.Context, input.Id, input.Text)
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	return true, nil
+}
+
+func (r *resolver) deleteSong(p graphql.ResolveParams) (any, error) {
+	if err := requireScope(p.Context, entity.ScopeWrite); err != nil {
+		return nil, err
+	}
+
+	input := idInput{Id: p.Args["id"].(int)}
+	if err := r.validator.Validate(input); err != nil {
+		return nil, newError(err)
+	}
+
+	err := r.songService.Delete(p.Context, input.Id)
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,174 @@
+package graphql
+
+import (
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+const (
+	defaultLimit = 5
+	dateLayout   = time.DateOnly
+)
+
+var sortOrderEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "SortOrder",
+	Values: graphql.EnumValueConfigMap{
+		"ASC":  &graphql.EnumValueConfig{Value: "asc"},
+		"DESC": &graphql.EnumValueConfig{Value: "desc"},
+	},
+})
+
+var songFieldEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name:        "SongField",
+	Description: "Song fields available for sorting",
+	Values: graphql.EnumValueConfigMap{
+		"ID":           &graphql.EnumValueConfig{Value: "id"},
+		"SONG":         &graphql.EnumValueConfig{Value: "song"},
+		"GROUP":        &graphql.EnumValueConfig{Value: "group"},
+		"LINK":         &graphql.EnumValueConfig{Value: "link"},
+		"RELEASE_DATE": &graphql.EnumValueConfig{Value: "releaseDate"},
+	},
+})
+
+var songFilterInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name:        "SongFilter",
+	Description: "Exact match filters, the same as filter[<name>] in the REST API",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"id":          &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"song":        &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"group":       &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"link":        &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"releaseDate": &graphql.InputObjectFieldConfig{Type: graphql.String},
+	},
+})
+
+var sortInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "SortInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"field": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(songFieldEnum)},
+		"order": &graphql.InputObjectFieldConfig{Type: sortOrderEnum, DefaultValue: "asc"},
+	},
+})
+
+var updateSongInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "UpdateSongInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"song":        &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"group":       &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"link":        &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"releaseDate": &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "Date in format 2006-01-02"},
+	},
+})
+
+var lyricsType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Lyrics",
+	Fields: graphql.Fields{
+		"text":  &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))},
+		"count": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "Total number of couplets"},
+	},
+})
+
+func paginationArgs() graphql.FieldConfigArgument {
+	return graphql.FieldConfigArgument{
+		"offset": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+		"limit":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultLimit},
+	}
+}
+
+func newSchema(r *resolver) (graphql.Schema, error) {
+	songType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Song",
+		Fields: graphql.Fields{
+			"id":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"song":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"group": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"link":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"releaseDate": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.String),
+				Resolve: r.releaseDate,
+			},
+			"lyrics": &graphql.Field{
+				Type:        graphql.NewNonNull(lyricsType),
+				Description: "Page of song couplets, loaded in batches for lists of songs",
+				Args:        paginationArgs(),
+				Resolve:     r.lyrics,
+			},
+		},
+	})
+
+	songType.AddFieldConfig("related", &graphql.Field{
+		Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(songType))),
+		Description: "Other songs of the same group",
+		Args: graphql.FieldConfigArgument{
+			"limit": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultLimit},
+		},
+		Resolve: r.related,
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"song": &graphql.Field{
+				Type: songType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: r.song,
+			},
+			"songs": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(songType))),
+				Args: graphql.FieldConfigArgument{
+					"filter":  &graphql.ArgumentConfig{Type: songFilterInput},
+					"orderBy": &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(sortInput))},
+					"offset":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+					"limit":   &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultLimit},
+				},
+				Resolve: r.songs,
+			},
+		},
+	})
+
+	mutationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"insertSong": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"group": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"song":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: r.insertSong,
+			},
+			"updateSong": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(updateSongInput)},
+				},
+				Resolve: r.updateSong,
+			},
+			"updateSongText": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Boolean),
+				Description: "Replace song text. Each couplet is separated by double newline symbols.",
+				Args: graphql.FieldConfigArgument{
+					"id":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"text": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: r.updateSongText,
+			},
+			"deleteSong": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: r.deleteSong,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    queryType,
+		Mutation: mutationType,
+	})
+}
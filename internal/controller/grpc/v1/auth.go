@@ -0,0 +1,105 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	songlibraryv1 "github.com/spanwalla/song-library/api/songlibrary/v1"
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/service"
+	"github.com/spanwalla/song-library/pkg/logger"
+)
+
+const (
+	authorizationHeader = "authorization"
+	apiKeyHeader        = "x-api-key"
+	bearerPrefix        = "bearer "
+)
+
+// methodScopes задаёт уровень доступа для методов библиотеки. Методы, которых здесь нет
+// (проверка здоровья, reflection), вызываются без ключа.
+var methodScopes = map[string]entity.Scope{
+	songlibraryv1.SongService_SearchSongs_FullMethodName:    entity.ScopeRead,
+	songlibraryv1.SongService_GetSong_FullMethodName:        entity.ScopeRead,
+	songlibraryv1.SongService_StreamSongText_FullMethodName: entity.ScopeRead,
+	songlibraryv1.SongService_InsertSong_FullMethodName:     entity.ScopeWrite,
+	songlibraryv1.SongService_UpdateSong_FullMethodName:     entity.ScopeWrite,
+	songlibraryv1.SongService_UpdateSongText_FullMethodName: entity.ScopeWrite,
+	songlibraryv1.SongService_DeleteSong_FullMethodName:     entity.ScopeWrite,
+}
+
+// UnaryAuth проверяет API-ключ или access-токен из метаданных authorization (Bearer)
+// или API-ключ из x-api-key.
+func UnaryAuth(auth service.Auth) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, auth, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuth делает то же, что UnaryAuth, для потоковых методов.
+func StreamAuth(auth service.Auth) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), auth, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &loggedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func authenticate(ctx context.Context, auth service.Auth, method string) (context.Context, error) {
+	scope, ok := methodScopes[method]
+	if !ok {
+		return ctx, nil
+	}
+
+	secret, ok := credentials(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "api key or access token is required")
+	}
+
+	principal, err := auth.Authenticate(ctx, secret)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			return nil, status.Error(codes.Unauthenticated, "api key or access token is invalid, expired or revoked")
+		}
+		return nil, toStatus(err)
+	}
+
+	if !principal.HasScope(scope) {
+		return nil, status.Errorf(codes.PermissionDenied, "credentials do not have the %s scope", scope)
+	}
+
+	ctx = service.WithPrincipal(ctx, principal)
+	return logger.WithFields(ctx, principal.LogFields()), nil
+}
+
+func credentials(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	if values := md.Get(authorizationHeader); len(values) > 0 {
+		if len(values[0]) > len(bearerPrefix) && strings.EqualFold(values[0][:len(bearerPrefix)], bearerPrefix) {
+			return strings.TrimSpace(values[0][len(bearerPrefix):]), true
+		}
+		return "", false
+	}
+
+	if values := md.Get(apiKeyHeader); len(values) > 0 && len(values[0]) > 0 {
+		return values[0], true
+	}
+
+	return "", false
+}
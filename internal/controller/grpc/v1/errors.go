@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/spanwalla/song-library/internal/service"
+	"github.com/spanwalla/song-library/pkg/validator"
+)
+
+var serviceErrorCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{service.ErrSongNotFound, codes.NotFound},
+	{service.ErrTranslationNotFound, codes.NotFound},
+	{service.ErrLineNotFound, codes.NotFound},
+	{service.ErrFieldsAreEmpty, codes.InvalidArgument},
+	{service.ErrInvalidLRC, codes.InvalidArgument},
+	{service.ErrTranslationMismatch, codes.InvalidArgument},
+	{service.ErrCannotGetSongInfo, codes.Unavailable},
+	{service.ErrInvalidCredentials, codes.Unauthenticated},
+	{service.ErrPermissionDenied, codes.PermissionDenied},
+	{service.ErrTenantNotFound, codes.NotFound},
+	{service.ErrTenantMismatch, codes.PermissionDenied},
+}
+
+// toStatus приводит ошибки сервисного слоя и валидации к статусам gRPC.
+func toStatus(err error) error {
+	var validationErr *validator.ValidationError
+	if errors.As(err, &validationErr) {
+		return status.Error(codes.InvalidArgument, validationErr.Error())
+	}
+
+	for _, e := range serviceErrorCodes {
+		if errors.Is(err, e.err) {
+			return status.Error(e.code, err.Error())
+		}
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,95 @@
+package v1
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/spanwalla/song-library/pkg/logger"
+)
+
+const (
+	requestIdHeader = "x-request-id"
+	requestIdLength = 16
+)
+
+// idGetter реализуют все запросы, адресованные одной песне.
+type idGetter interface {
+	GetId() int64
+}
+
+// UnaryRequestLogger принимает или создаёт идентификатор запроса, возвращает его в заголовке
+// ответа и кладёт в контекст логгер с идентификатором запроса, методом и песней.
+func UnaryRequestLogger() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx = withRequestLogger(ctx, info.FullMethod, req)
+		return handler(ctx, req)
+	}
+}
+
+// StreamRequestLogger делает то же, что UnaryRequestLogger, для потоковых методов.
+// Идентификатор песни недоступен до чтения запроса, поэтому в логгер он не попадает.
+func StreamRequestLogger() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &loggedStream{
+			ServerStream: ss,
+			ctx:          withRequestLogger(ss.Context(), info.FullMethod, nil),
+		})
+	}
+}
+
+// UnaryContext дополняет контекст запроса функцией wrap, например привязкой к основному серверу БД.
+func UnaryContext(wrap func(context.Context) context.Context) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(wrap(ctx), req)
+	}
+}
+
+// StreamContext делает то же, что UnaryContext, для потоковых методов.
+func StreamContext(wrap func(context.Context) context.Context) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &loggedStream{ServerStream: ss, ctx: wrap(ss.Context())})
+	}
+}
+
+type loggedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *loggedStream) Context() context.Context {
+	return s.ctx
+}
+
+func withRequestLogger(ctx context.Context, method string, req any) context.Context {
+	var requestId string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIdHeader); len(values) > 0 {
+			requestId = values[0]
+		}
+	}
+	if len(requestId) == 0 {
+		requestId = newRequestId()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIdHeader, requestId))
+
+	fields := log.Fields{
+		logger.FieldRequestId: requestId,
+		logger.FieldRoute:     method,
+	}
+	if r, ok := req.(idGetter); ok {
+		fields[logger.FieldSongId] = r.GetId()
+	}
+
+	return logger.WithFields(ctx, fields)
+}
+
+func newRequestId() string {
+	b := make([]byte, requestIdLength)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,223 @@
+package v1
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	songlibraryv1 "github.com/spanwalla/song-library/api/songlibrary/v1"
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/service"
+	"github.com/spanwalla/song-library/pkg/logger"
+	"github.com/spanwalla/song-library/pkg/query"
+)
+
+const textPageSize = 10
+
+type inputValidator interface {
+	Validate(i any) error
+}
+
+type songServer struct {
+	songlibraryv1.UnimplementedSongServiceServer
+
+	songService service.Song
+	validator   inputValidator
+}
+
+type songIdInput struct {
+	Id int `json:"id" validate:"number,gt=0"`
+}
+
+type streamTextInput struct {
+	Id   int    `json:"id" validate:"number,gt=0"`
+	Lang string `json:"lang" validate:"omitempty,lang"`
+}
+
+type insertSongInput struct {
+	Group string `json:"group" validate:"required,max=128"`
+	Song  string `json:"song" validate:"required,max=128"`
+}
+
+type updateSongInput struct {
+	Id          int     `json:"id" validate:"number,gt=0"`
+	Group       *string `json:"group" validate:"omitempty,max=128"`
+	Song        *string `json:"song" validate:"omitempty,max=128"`
+	Link        *string `json:"link" validate:"omitempty,max=128,uri"`
+	ReleaseDate *string `json:"release_date" validate:"omitempty,date"`
+}
+
+type updateSongTextInput struct {
+	Id   int    `json:"id" validate:"number,gt=0"`
+	Text string `json:"text" validate:"required"`
+}
+
+// ConfigureServer регистрирует gRPC-сервисы библиотеки.
+func ConfigureServer(s *grpc.Server, services *service.Services, v inputValidator) {
+	songlibraryv1.RegisterSongServiceServer(s, &songServer{
+		songService: services.Song,
+		validator:   v,
+	})
+}
+
+func (s *songServer) SearchSongs(ctx context.Context, req *songlibraryv1.SearchSongsRequest) (*songlibraryv1.SearchSongsResponse, error) {
+	var orderBy [][]string
+	for _, criteria := range req.GetOrderBy() {
+		order := query.AscendingSortOrder
+		if criteria.GetOrder() == songlibraryv1.SortOrder_SORT_ORDER_DESC {
+			order = query.DescendingSortOrder
+		}
+		orderBy = append(orderBy, []string{criteria.GetField(), order})
+	}
+
+	songs, err := s.songService.Search(ctx, service.SearchSongInput{
+		Filters: req.GetFilters(),
+		OrderBy: orderBy,
+		Offset:  int(req.GetOffset()),
+		Limit:   int(req.GetLimit()),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &songlibraryv1.SearchSongsResponse{Songs: make([]*songlibraryv1.Song, 0, len(songs))}
+	for _, song := range songs {
+		resp.Songs = append(resp.Songs, toProtoSong(song))
+	}
+
+	return resp, nil
+}
+
+func (s *songServer) GetSong(ctx context.Context, req *songlibraryv1.GetSongRequest) (*songlibraryv1.Song, error) {
+	input := songIdInput{Id: int(req.GetId())}
+	if err := s.validator.Validate(input); err != nil {
+		return nil, toStatus(err)
+	}
+
+	song, err := s.songService.Get(ctx, input.Id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toProtoSong(song), nil
+}
+
+// StreamSongText читает текст страницами и отправляет клиенту по одному куплету.
+func (s *songServer) StreamSongText(req *songlibraryv1.StreamSongTextRequest, stream grpc.ServerStreamingServer[songlibraryv1.Couplet]) error {
+	input := streamTextInput{Id: int(req.GetId()), Lang: req.GetLang()}
+	if err := s.validator.Validate(input); err != nil {
+		return toStatus(err)
+	}
+
+	ctx := logger.WithFields(stream.Context(), log.Fields{logger.FieldSongId: input.Id})
+
+	for offset := 0; ; offset += textPageSize {
+		couplets, count, err := s.songService.GetText(ctx, service.GetTextInput{
+			SongId:   input.Id,
+			Language: input.Lang,
+			Offset:   offset,
+			Limit:    textPageSize,
+		})
+		if err != nil {
+			return toStatus(err)
+		}
+
+		for i, text := range couplets {
+			err = stream.Send(&songlibraryv1.Couplet{
+				Number: int32(offset + i + 1),
+				Text:   text,
+				Count:  int32(count),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(couplets) == 0 || offset+len(couplets) >= count {
+			return nil
+		}
+	}
+}
+
+func (s *songServer) InsertSong(ctx context.Context, req *songlibraryv1.InsertSongRequest) (*emptypb.Empty, error) {
+	input := insertSongInput{Group: req.GetGroup(), Song: req.GetSong()}
+	if err := s.validator.Validate(input); err != nil {
+		return nil, toStatus(err)
+	}
+
+	err := s.songService.Insert(ctx, service.InsertSongInput{
+		Group: input.Group,
+		Song:  input.Song,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *songServer) UpdateSong(ctx context.Context, req *songlibraryv1.UpdateSongRequest) (*emptypb.Empty, error) {
+	input := updateSongInput{
+		Id:          int(req.GetId()),
+		Group:       req.Group,
+		Song:        req.Song,
+		Link:        req.Link,
+		ReleaseDate: req.ReleaseDate,
+	}
+	if err := s.validator.Validate(input); err != nil {
+		return nil, toStatus(err)
+	}
+
+	err := s.songService.Update(ctx, input.Id, service.UpdateSongInput{
+		Name:        input.Song,
+		Group:       input.Group,
+		Link:        input.Link,
+		ReleaseDate: input.ReleaseDate,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *songServer) UpdateSongText(ctx context.Context, req *songlibraryv1.UpdateSongTextRequest) (*emptypb.Empty, error) {
+	input := updateSongTextInput{Id: int(req.GetId()), Text: req.GetText()}
+	if err := s.validator.Validate(input); err != nil {
+		return nil, toStatus(err)
+	}
+
+	err := s.songService.UpdateText(ctx, input.Id, input.Text)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *songServer) DeleteSong(ctx context.Context, req *songlibraryv1.DeleteSongRequest) (*emptypb.Empty, error) {
+	input := songIdInput{Id: int(req.GetId())}
+	if err := s.validator.Validate(input); err != nil {
+		return nil, toStatus(err)
+	}
+
+	err := s.songService.Delete(ctx, input.Id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func toProtoSong(song entity.Song) *songlibraryv1.Song {
+	return &songlibraryv1.Song{
+		Id:          int64(song.Id),
+		Song:        song.Name,
+		Group:       song.Group,
+		Link:        song.Link,
+		ReleaseDate: song.ReleaseDate.Format(time.DateOnly),
+	}
+}
@@ -0,0 +1,58 @@
+package v1
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/spanwalla/song-library/internal/service"
+	"github.com/spanwalla/song-library/internal/tenant"
+	"github.com/spanwalla/song-library/pkg/logger"
+)
+
+const tenantHeader = "x-tenant"
+
+// UnaryTenant кладёт в контекст арендатора из метаданных x-tenant. Без них арендатором
+// станет арендатор учётных данных или, если аутентификация отключена, арендатор по умолчанию.
+// Должен стоять перед UnaryAuth, чтобы тот отклонил ключи другого арендатора.
+func UnaryTenant(tenants service.Tenant) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := resolveTenant(ctx, tenants)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamTenant делает то же, что UnaryTenant, для потоковых методов.
+func StreamTenant(tenants service.Tenant) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := resolveTenant(ss.Context(), tenants)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &loggedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func resolveTenant(ctx context.Context, tenants service.Tenant) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx, nil
+	}
+	values := md.Get(tenantHeader)
+	if len(values) == 0 || len(values[0]) == 0 {
+		return ctx, nil
+	}
+
+	t, err := tenants.Resolve(ctx, values[0])
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	ctx = tenant.With(ctx, t)
+	return logger.WithFields(ctx, log.Fields{logger.FieldTenant: t.Slug}), nil
+}
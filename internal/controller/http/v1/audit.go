@@ -0,0 +1,124 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/service"
+)
+
+type auditRoutes struct {
+	auditService service.Audit
+}
+
+type auditFilterInput struct {
+	SongId int        `query:"songId" validate:"gte=0"`
+	Actor  string     `query:"actor" validate:"omitempty,max=64"`
+	Action string     `query:"action" validate:"omitempty,oneof=insert update update_text delete"`
+	Since  *time.Time `query:"since"`
+	Until  *time.Time `query:"until"`
+	Offset int        `query:"offset" validate:"gte=0"`
+	Limit  int        `query:"limit" validate:"gte=0,lte=100"`
+}
+
+type songAuditInput struct {
+	Id     int `param:"id" validate:"number,gt=0"`
+	Offset int `query:"offset" validate:"gte=0"`
+	Limit  int `query:"limit" validate:"gte=0,lte=100"`
+}
+
+// newAuditRoutes регистрирует журнал аудита: общий доступен только администраторам,
+// а история отдельной песни — всем, кто может её читать.
+func newAuditRoutes(g *echo.Group, auditService service.Audit, access *accessControl, limiter *rateLimiter) {
+	r := &auditRoutes{auditService: auditService}
+
+	g.GET("/audit", r.listAudit, access.require(entity.ScopeAdmin), limiter.limit(BudgetRead))
+	g.GET("/songs/:id/audit", r.songAudit, access.require(entity.ScopeRead), limiter.limit(BudgetRead))
+}
+
+// @Description List changes of all songs, newest first
+// @Summary List audit log
+// @Param songId query int false "Song ID" minimum(1) example(2)
+// @Param actor query string false "Who made the change: user:<id>, api_key:<id> or system" example(user:1)
+// @Param action query string false "Kind of change" Enums(insert, update, update_text, delete)
+// @Param since query string false "Changes made at or after this time, RFC 3339" example(2025-05-01T00:00:00Z)
+// @Param until query string false "Changes made before this time, RFC 3339" example(2025-06-01T00:00:00Z)
+// @Param offset query int false "Offset" default(0) minimum(0) example(20)
+// @Param limit query int false "Limit" default(20) minimum(1) maximum(100) example(50)
+// @Produce json
+// @Success 200 {array} entity.AuditEntry
+// @Failure 400 {object} v1.problem
+// @Failure 401 {object} v1.problem
+// @Failure 403 {object} v1.problem
+// @Failure 429 {object} v1.problem
+// @Failure 500 {object} v1.problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /audit [get]
+func (r *auditRoutes) listAudit(c echo.Context) error {
+	var input auditFilterInput
+
+	if err := c.Bind(&input); err != nil {
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		return err
+	}
+
+	entries, err := r.auditService.List(c.Request().Context(), service.ListAuditInput{
+		SongId: input.SongId,
+		Actor:  input.Actor,
+		Action: entity.AuditAction(input.Action),
+		Since:  input.Since,
+		Until:  input.Until,
+		Offset: input.Offset,
+		Limit:  input.Limit,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, entries)
+}
+
+// @Description List changes of the song, newest first. Changes of deleted songs are kept.
+// @Summary Song audit log
+// @Param id path int true "Song ID" minimum(1) example(2)
+// @Param offset query int false "Offset" default(0) minimum(0) example(20)
+// @Param limit query int false "Limit" default(20) minimum(1) maximum(100) example(50)
+// @Produce json
+// @Success 200 {array} entity.AuditEntry
+// @Failure 400 {object} v1.problem
+// @Failure 401 {object} v1.problem
+// @Failure 403 {object} v1.problem
+// @Failure 429 {object} v1.problem
+// @Failure 500 {object} v1.problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id}/audit [get]
+func (r *auditRoutes) songAudit(c echo.Context) error {
+	var input songAuditInput
+
+	if err := c.Bind(&input); err != nil {
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		return err
+	}
+
+	entries, err := r.auditService.List(c.Request().Context(), service.ListAuditInput{
+		SongId: input.Id,
+		Offset: input.Offset,
+		Limit:  input.Limit,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, entries)
+}
@@ -0,0 +1,95 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/service"
+	"github.com/spanwalla/song-library/pkg/logger"
+)
+
+const (
+	headerAPIKey = "X-API-Key"
+	bearerScheme = "Bearer"
+	authRealm    = `Bearer realm="song-library"`
+)
+
+var (
+	errMissingCredentials = newAPIError(http.StatusUnauthorized, CodeUnauthorized, "api key or access token is required")
+	errInvalidCredentials = newAPIError(http.StatusUnauthorized, CodeUnauthorized, "api key or access token is invalid, expired or revoked")
+	errInsufficientScope  = newAPIError(http.StatusForbidden, CodeInsufficientScope, "credentials do not have the required scope")
+)
+
+// accessControl подключает проверку API-ключей к отдельным маршрутам.
+type accessControl struct {
+	auth    service.Auth
+	enabled bool
+}
+
+// require возвращает RequireScope или, если аутентификация отключена, middleware,
+// которое пропускает запросы без проверки.
+func (a *accessControl) require(scope entity.Scope) echo.MiddlewareFunc {
+	if !a.enabled {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return next
+		}
+	}
+	return RequireScope(a.auth, scope)
+}
+
+// RequireScope пропускает запрос только с ключом или токеном уровня scope или выше и кладёт
+// участника в контекст запроса. API-ключ принимается в заголовке Authorization: Bearer
+// или X-API-Key, access-токен пользователя — только в Authorization: Bearer.
+func RequireScope(auth service.Auth, scope entity.Scope) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			secret, ok := credentials(c.Request())
+			if !ok {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, authRealm)
+				return errMissingCredentials
+			}
+
+			ctx := c.Request().Context()
+			principal, err := auth.Authenticate(ctx, secret)
+			if err != nil {
+				if errors.Is(err, service.ErrInvalidCredentials) {
+					c.Response().Header().Set(echo.HeaderWWWAuthenticate, authRealm+`, error="invalid_token"`)
+					return errInvalidCredentials
+				}
+				return err
+			}
+
+			if !principal.HasScope(scope) {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, authRealm+`, error="insufficient_scope", scope="`+string(scope)+`"`)
+				return errInsufficientScope
+			}
+
+			ctx = service.WithPrincipal(ctx, principal)
+			ctx = logger.WithFields(ctx, principal.LogFields())
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			return next(c)
+		}
+	}
+}
+
+// credentials достаёт ключ или токен из Authorization: Bearer или X-API-Key.
+func credentials(r *http.Request) (string, bool) {
+	if header := r.Header.Get(echo.HeaderAuthorization); len(header) > 0 {
+		scheme, token, ok := strings.Cut(header, " ")
+		if ok && strings.EqualFold(scheme, bearerScheme) && len(strings.TrimSpace(token)) > 0 {
+			return strings.TrimSpace(token), true
+		}
+		return "", false
+	}
+
+	if key := r.Header.Get(headerAPIKey); len(key) > 0 {
+		return key, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,102 @@
+package v1
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// CORSPolicy описывает, каким сайтам браузер разрешит обращаться к API.
+// Источник задаётся точно (https://example.com), шаблоном поддомена (https://*.example.com)
+// или звёздочкой для любого сайта. Пустой список запрещает запросы с других сайтов.
+type CORSPolicy struct {
+	AllowOrigins     []string
+	AllowMethods     []string
+	AllowHeaders     []string
+	ExposeHeaders    []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// CORSConfig хранит политики для чтения и изменения данных, которые можно заменить без перезапуска.
+type CORSConfig struct {
+	policies atomic.Pointer[corsPolicies]
+}
+
+type corsPolicies struct {
+	read, write echo.MiddlewareFunc
+}
+
+func NewCORSConfig(read, write CORSPolicy) *CORSConfig {
+	c := &CORSConfig{}
+	c.Set(read, write)
+	return c
+}
+
+func (c *CORSConfig) Set(read, write CORSPolicy) {
+	c.policies.Store(&corsPolicies{read: corsWithPolicy(read), write: corsWithPolicy(write)})
+}
+
+// cors выбирает политику по методу запроса: GET и HEAD читают данные и получают политику read,
+// остальные методы — write. Для предварительного запроса OPTIONS метод берётся
+// из Access-Control-Request-Method, так как путь у чтения и изменения песни один.
+// Политики читаются из config на каждый запрос.
+func cors(config *CORSConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			method := c.Request().Method
+			if requested := c.Request().Header.Get(echo.HeaderAccessControlRequestMethod); method == http.MethodOptions && len(requested) > 0 {
+				method = requested
+			}
+
+			policies := config.policies.Load()
+			if method == http.MethodGet || method == http.MethodHead {
+				return policies.read(next)(c)
+			}
+			return policies.write(next)(c)
+		}
+	}
+}
+
+func corsWithPolicy(p CORSPolicy) echo.MiddlewareFunc {
+	// Источники проверяются функцией: echo считает пустой AllowOrigins разрешением для всех.
+	return middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowOriginFunc: func(origin string) (bool, error) {
+			return originAllowed(p.AllowOrigins, origin), nil
+		},
+		AllowMethods:     p.AllowMethods,
+		AllowHeaders:     p.AllowHeaders,
+		ExposeHeaders:    p.ExposeHeaders,
+		AllowCredentials: p.AllowCredentials,
+		MaxAge:           int(p.MaxAge.Seconds()),
+	})
+}
+
+// originAllowed сравнивает Origin с разрешёнными источниками. Шаблон https://*.example.com
+// подходит для любого поддомена example.com с той же схемой и портом, но не для самого example.com.
+func originAllowed(allowed []string, origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(pattern)
+		if pattern == "*" || pattern == origin {
+			return true
+		}
+
+		scheme, domain, ok := strings.Cut(pattern, "://*.")
+		if !ok {
+			continue
+		}
+		prefix, suffix := scheme+"://", "."+domain
+		if len(origin) <= len(prefix)+len(suffix) || !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+			continue
+		}
+		if subdomain := origin[len(prefix) : len(origin)-len(suffix)]; !strings.ContainsAny(subdomain, "/:@") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOriginAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		origin  string
+		want    bool
+	}{
+		{"wildcard", []string{"*"}, "https://any.site", true},
+		{"empty list", nil, "https://example.com", false},
+		{"exact", []string{"https://example.com"}, "https://example.com", true},
+		{"case insensitive", []string{"https://Example.com"}, "HTTPS://example.COM", true},
+		{"other scheme", []string{"https://example.com"}, "http://example.com", false},
+		{"other port", []string{"https://example.com"}, "https://example.com:8443", false},
+		{"subdomain", []string{"https://*.example.com"}, "https://app.example.com", true},
+		{"nested subdomain", []string{"https://*.example.com"}, "https://a.b.example.com", true},
+		{"subdomain pattern excludes apex", []string{"https://*.example.com"}, "https://example.com", false},
+		{"subdomain with other scheme", []string{"https://*.example.com"}, "http://app.example.com", false},
+		{"subdomain with port", []string{"https://*.example.com"}, "https://app.example.com:8443", false},
+		{"subdomain pattern with port", []string{"https://*.example.com:8443"}, "https://app.example.com:8443", true},
+		{"suffix without dot", []string{"https://*.example.com"}, "https://evilexample.com", false},
+		{"userinfo", []string{"https://*.example.com"}, "https://user@app.example.com", false},
+		{"empty subdomain", []string{"https://*.example.com"}, "https://.example.com", false},
+		{"second pattern", []string{"https://a.com", "https://*.b.com"}, "https://x.b.com", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, originAllowed(tt.allowed, tt.origin))
+		})
+	}
+}
+
+func TestCORSPolicyByMethod(t *testing.T) {
+	config := NewCORSConfig(
+		CORSPolicy{AllowOrigins: []string{"*"}},
+		CORSPolicy{AllowOrigins: []string{"https://admin.example.com"}},
+	)
+	handler := cors(config)(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	request := func(method, origin, preflight string) string {
+		req := httptest.NewRequest(method, "/", nil)
+		req.Header.Set(echo.HeaderOrigin, origin)
+		if len(preflight) > 0 {
+			req.Header.Set(echo.HeaderAccessControlRequestMethod, preflight)
+		}
+		rec := httptest.NewRecorder()
+		_ = handler(echo.New().NewContext(req, rec))
+		return rec.Header().Get(echo.HeaderAccessControlAllowOrigin)
+	}
+
+	assert.Equal(t, "https://site.com", request(http.MethodGet, "https://site.com", ""))
+	assert.Empty(t, request(http.MethodPost, "https://site.com", ""))
+	assert.Empty(t, request(http.MethodOptions, "https://site.com", http.MethodDelete))
+	assert.Equal(t, "https://admin.example.com", request(http.MethodPost, "https://admin.example.com", ""))
+
+	// Новые политики действуют со следующего запроса.
+	config.Set(CORSPolicy{}, CORSPolicy{AllowOrigins: []string{"https://site.com"}})
+	assert.Empty(t, request(http.MethodGet, "https://site.com", ""))
+	assert.Equal(t, "https://site.com", request(http.MethodPost, "https://site.com", ""))
+}
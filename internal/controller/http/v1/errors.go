@@ -2,17 +2,249 @@ package v1
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
 
 	"github.com/labstack/echo/v4"
+
+	"github.com/spanwalla/song-library/internal/service"
+	"github.com/spanwalla/song-library/pkg/logger"
+	"github.com/spanwalla/song-library/pkg/validator"
+)
+
+const (
+	mimeProblemJSON   = "application/problem+json"
+	problemTypePrefix = "urn:song-library:problem:"
+)
+
+// Коды ошибок API. Значения являются частью контракта и не должны меняться.
+const (
+	CodeInvalidRequest        = "invalid_request"
+	CodeValidationFailed      = "validation_failed"
+	CodeUnsupportedFormat     = "unsupported_format"
+	CodePayloadTooLarge       = "payload_too_large"
+	CodeNotFound              = "not_found"
+	CodeMethodNotAllowed      = "method_not_allowed"
+	CodeInternal              = "internal_error"
+	CodeSongInfoUnavailable   = "song_info_unavailable"
+	CodeSongInsertFailed      = "song_insert_failed"
+	CodeCoupletsInsertFailed  = "couplets_insert_failed"
+	CodeSongNotFound          = "song_not_found"
+	CodeSongReadFailed        = "song_read_failed"
+	CodeTextReadFailed        = "text_read_failed"
+	CodeFieldsEmpty           = "fields_empty"
+	CodeSongUpdateFailed      = "song_update_failed"
+	CodeTextUpdateFailed      = "text_update_failed"
+	CodeSongDeleteFailed      = "song_delete_failed"
+	CodeInvalidLRC            = "invalid_lrc"
+	CodeLineNotFound          = "line_not_found"
+	CodeTranslationNotFound   = "translation_not_found"
+	CodeTranslationMismatch   = "translation_mismatch"
+	CodeTranslationReadFailed = "translation_read_failed"
+	CodeTranslationSaveFailed = "translation_save_failed"
+	CodeUnauthorized          = "unauthorized"
+	CodeInsufficientScope     = "insufficient_scope"
+	CodeAuthFailed            = "authentication_failed"
+	CodeInvalidScope          = "invalid_scope"
+	CodeInvalidExpiry         = "invalid_expiry"
+	CodeKeyNotFound           = "api_key_not_found"
+	CodeKeyIssueFailed        = "api_key_issue_failed"
+	CodeKeyReadFailed         = "api_key_read_failed"
+	CodeKeyRevokeFailed       = "api_key_revoke_failed"
+	CodeRegistrationDisabled  = "registration_disabled"
+	CodeInviteRequired        = "invite_required"
+	CodeInvalidInvite         = "invalid_invite"
+	CodeInviteIssueFailed     = "invite_issue_failed"
+	CodeUserExists            = "user_exists"
+	CodeRegisterFailed        = "register_failed"
+	CodeSessionsDisabled      = "sessions_disabled"
+	CodeSessionFailed         = "session_failed"
+	CodeLogoutFailed          = "logout_failed"
+	CodeRateLimited           = "rate_limited"
+	CodeAuditReadFailed       = "audit_read_failed"
+	CodePermissionDenied      = "permission_denied"
+	CodeInvalidRole           = "invalid_role"
+	CodeUserNotFound          = "user_not_found"
+	CodeUserReadFailed        = "user_read_failed"
+	CodeRoleAssignFailed      = "role_assign_failed"
+	CodeTenantNotFound        = "tenant_not_found"
+	CodeTenantReadFailed      = "tenant_read_failed"
+	CodeTenantMismatch        = "tenant_mismatch"
 )
 
-func newErrorResponse(c echo.Context, errStatus int, message string) {
-	err := errors.New(message)
-	var HTTPError *echo.HTTPError
-	ok := errors.As(err, &HTTPError)
-	if !ok {
-		report := echo.NewHTTPError(errStatus, err.Error())
-		_ = c.JSON(errStatus, report)
+var (
+	errUnsupportedFormat = newAPIError(http.StatusBadRequest, CodeUnsupportedFormat, "unsupported format")
+	errLangNotSupported  = newAPIError(http.StatusBadRequest, CodeUnsupportedFormat, "lang is not supported for lrc format")
+	errLangRequired      = newAPIError(http.StatusBadRequest, CodeValidationFailed, "field lang is required for side by side output")
+	errBodyTooLarge      = newAPIError(http.StatusRequestEntityTooLarge, CodePayloadTooLarge, "request body is too large")
+	errInvalidBody       = newAPIError(http.StatusBadRequest, CodeInvalidRequest, "invalid request body")
+)
+
+// serviceErrors сопоставляет ошибки сервисного слоя с HTTP-статусами и кодами.
+var serviceErrors = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{service.ErrCannotGetSongInfo, http.StatusBadGateway, CodeSongInfoUnavailable},
+	{service.ErrCannotInsertSong, http.StatusInternalServerError, CodeSongInsertFailed},
+	{service.ErrCannotInsertCouplets, http.StatusInternalServerError, CodeCoupletsInsertFailed},
+	{service.ErrSongNotFound, http.StatusNotFound, CodeSongNotFound},
+	{service.ErrCannotGetSong, http.StatusInternalServerError, CodeSongReadFailed},
+	{service.ErrCannotGetText, http.StatusInternalServerError, CodeTextReadFailed},
+	{service.ErrFieldsAreEmpty, http.StatusBadRequest, CodeFieldsEmpty},
+	{service.ErrCannotUpdateSong, http.StatusInternalServerError, CodeSongUpdateFailed},
+	{service.ErrCannotUpdateCouplets, http.StatusInternalServerError, CodeTextUpdateFailed},
+	{service.ErrCannotDeleteSong, http.StatusInternalServerError, CodeSongDeleteFailed},
+	{service.ErrInvalidLRC, http.StatusBadRequest, CodeInvalidLRC},
+	{service.ErrLineNotFound, http.StatusNotFound, CodeLineNotFound},
+	{service.ErrTranslationNotFound, http.StatusNotFound, CodeTranslationNotFound},
+	{service.ErrTranslationMismatch, http.StatusBadRequest, CodeTranslationMismatch},
+	{service.ErrCannotGetTranslation, http.StatusInternalServerError, CodeTranslationReadFailed},
+	{service.ErrCannotPutTranslation, http.StatusInternalServerError, CodeTranslationSaveFailed},
+	{service.ErrCannotAuthenticate, http.StatusInternalServerError, CodeAuthFailed},
+	{service.ErrInvalidScope, http.StatusBadRequest, CodeInvalidScope},
+	{service.ErrInvalidExpiry, http.StatusBadRequest, CodeInvalidExpiry},
+	{service.ErrKeyNotFound, http.StatusNotFound, CodeKeyNotFound},
+	{service.ErrCannotIssueKey, http.StatusInternalServerError, CodeKeyIssueFailed},
+	{service.ErrCannotGetKeys, http.StatusInternalServerError, CodeKeyReadFailed},
+	{service.ErrCannotRevokeKey, http.StatusInternalServerError, CodeKeyRevokeFailed},
+	{service.ErrInvalidCredentials, http.StatusUnauthorized, CodeUnauthorized},
+	{service.ErrRegistrationDisabled, http.StatusForbidden, CodeRegistrationDisabled},
+	{service.ErrInviteRequired, http.StatusForbidden, CodeInviteRequired},
+	{service.ErrInvalidInvite, http.StatusForbidden, CodeInvalidInvite},
+	{service.ErrCannotIssueInvite, http.StatusInternalServerError, CodeInviteIssueFailed},
+	{service.ErrUserAlreadyExists, http.StatusConflict, CodeUserExists},
+	{service.ErrCannotRegister, http.StatusInternalServerError, CodeRegisterFailed},
+	{service.ErrSessionsDisabled, http.StatusServiceUnavailable, CodeSessionsDisabled},
+	{service.ErrCannotCreateSession, http.StatusInternalServerError, CodeSessionFailed},
+	{service.ErrCannotLogout, http.StatusInternalServerError, CodeLogoutFailed},
+	{service.ErrCannotGetAudit, http.StatusInternalServerError, CodeAuditReadFailed},
+	{service.ErrPermissionDenied, http.StatusForbidden, CodePermissionDenied},
+	{service.ErrInvalidRole, http.StatusBadRequest, CodeInvalidRole},
+	{service.ErrUserNotFound, http.StatusNotFound, CodeUserNotFound},
+	{service.ErrCannotGetUsers, http.StatusInternalServerError, CodeUserReadFailed},
+	{service.ErrCannotAssignRole, http.StatusInternalServerError, CodeRoleAssignFailed},
+	{service.ErrTenantNotFound, http.StatusNotFound, CodeTenantNotFound},
+	{service.ErrCannotGetTenant, http.StatusInternalServerError, CodeTenantReadFailed},
+	{service.ErrTenantMismatch, http.StatusForbidden, CodeTenantMismatch},
+}
+
+// httpStatusCodes задаёт коды для ошибок, которые возвращает сам echo (биндинг, маршрутизация).
+var httpStatusCodes = map[int]string{
+	http.StatusBadRequest:            CodeInvalidRequest,
+	http.StatusUnauthorized:          CodeUnauthorized,
+	http.StatusForbidden:             CodeInsufficientScope,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusMethodNotAllowed:      CodeMethodNotAllowed,
+	http.StatusRequestEntityTooLarge: CodePayloadTooLarge,
+	http.StatusUnsupportedMediaType:  CodeUnsupportedFormat,
+	http.StatusTooManyRequests:       CodeRateLimited,
+}
+
+// problem описывает ответ с ошибкой в формате RFC 7807.
+type problem struct {
+	Type      string         `json:"type" example:"urn:song-library:problem:song_not_found"`
+	Title     string         `json:"title" example:"Not Found"`
+	Status    int            `json:"status" example:"404"`
+	Detail    string         `json:"detail,omitempty" example:"song not found"`
+	Instance  string         `json:"instance,omitempty" example:"/api/v1/songs/42"`
+	Code      string         `json:"code" example:"song_not_found"`
+	Fields    []problemField `json:"fields,omitempty"`
+	RequestId string         `json:"requestId,omitempty" example:"3XhQUxlnBF6nLq0Jzgs1lSNWBUZ3iLg3"`
+}
+
+type problemField struct {
+	Name   string `json:"name" example:"song"`
+	Reason string `json:"reason" example:"field song is required"`
+}
+
+// apiError описывает ошибку, которую обработчик возвращает с заранее известными статусом и кодом.
+type apiError struct {
+	status int
+	code   string
+	detail string
+}
+
+func newAPIError(status int, code, detail string) *apiError {
+	return &apiError{status: status, code: code, detail: detail}
+}
+
+func (e *apiError) Error() string {
+	return e.detail
+}
+
+// newProblem приводит любую ошибку обработчика к problem.
+func newProblem(err error) problem {
+	p := problem{
+		Status: http.StatusInternalServerError,
+		Code:   CodeInternal,
+	}
+
+	var (
+		apiErr        *apiError
+		validationErr *validator.ValidationError
+		bindingErr    *echo.BindingError
+		httpErr       *echo.HTTPError
+	)
+
+	switch {
+	case errors.As(err, &apiErr):
+		p.Status, p.Code, p.Detail = apiErr.status, apiErr.code, apiErr.detail
+	case errors.As(err, &validationErr):
+		p.Status, p.Code, p.Detail = http.StatusBadRequest, CodeValidationFailed, validationErr.Error()
+		for _, field := range validationErr.Fields {
+			p.Fields = append(p.Fields, problemField{Name: field.Field, Reason: field.Message})
+		}
+	case errors.As(err, &bindingErr):
+		reason := fmt.Sprintf("field %s has invalid value", bindingErr.Field)
+		p.Status, p.Code, p.Detail = http.StatusBadRequest, CodeInvalidRequest, reason
+		p.Fields = []problemField{{Name: bindingErr.Field, Reason: reason}}
+	case errors.As(err, &httpErr):
+		p.Status = httpErr.Code
+		if code, ok := httpStatusCodes[httpErr.Code]; ok {
+			p.Code = code
+		}
+		if p.Status < http.StatusInternalServerError {
+			p.Detail = fmt.Sprint(httpErr.Message)
+		}
+	default:
+		for _, e := range serviceErrors {
+			if errors.Is(err, e.err) {
+				p.Status, p.Code, p.Detail = e.status, e.code, e.err.Error()
+				break
+			}
+		}
+	}
+
+	p.Type = problemTypePrefix + p.Code
+	p.Title = http.StatusText(p.Status)
+
+	return p
+}
+
+// ErrorHandler отдаёт ошибки обработчиков клиенту в формате application/problem+json.
+func ErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	p := newProblem(err)
+	p.Instance = c.Request().URL.Path
+	p.RequestId = c.Response().Header().Get(echo.HeaderXRequestID)
+
+	if p.Status >= http.StatusInternalServerError {
+		logger.From(c.Request().Context()).Errorf("v1 - ErrorHandler - %s %s: %v", c.Request().Method, p.Instance, err)
+	}
+
+	var writeErr error
+	if c.Request().Method == http.MethodHead {
+		writeErr = c.NoContent(p.Status)
+	} else {
+		c.Response().Header().Set(echo.HeaderContentType, mimeProblemJSON)
+		writeErr = c.JSON(p.Status, p)
+	}
+	if writeErr != nil {
+		logger.From(c.Request().Context()).Errorf("v1 - ErrorHandler - c.JSON: %v", writeErr)
 	}
-	c.Error(errors.New("internal server error"))
 }
@@ -0,0 +1,133 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/service"
+)
+
+type keyRoutes struct {
+	authService service.Auth
+}
+
+type keyIdInput struct {
+	Id int `param:"id" validate:"number,gt=0"`
+}
+
+type issueKeyInput struct {
+	Name      string     `json:"name" validate:"required,max=128" example:"importer"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,oneof=read write admin" example:"read,write"`
+	ExpiresAt *time.Time `json:"expiresAt" example:"2025-07-22T10:30:00Z"`
+}
+
+// issuedKey возвращается один раз при выпуске ключа: секрет в базе не хранится.
+type issuedKey struct {
+	entity.APIKey
+	Key string `json:"key" example:"sl_3f9a1c2b_Vb2yq1h0oX4k9cQz8sJt6wE3rN5mL7pA0dF2gH4jK6l"`
+}
+
+func newKeyRoutes(g *echo.Group, authService service.Auth, access *accessControl, limiter *rateLimiter) {
+	r := &keyRoutes{authService: authService}
+	admin := []echo.MiddlewareFunc{access.require(entity.ScopeAdmin), limiter.limit(BudgetWrite)}
+
+	g.POST("", r.issueKey, admin...)
+	g.GET("", r.listKeys, admin...)
+	g.DELETE("/:id", r.revokeKey, admin...)
+}
+
+// @Description Issue a new API key. The key is shown only in this response, the library stores its hash.
+// @Summary Issue API key
+// @Param input body v1.issueKeyInput true "Key name, scopes (read, write, admin) and optional expiry"
+// @Accept json
+// @Produce json
+// @Success 201 {object} v1.issuedKey
+// @Failure 400 {object} v1.problem
+// @Failure 401 {object} v1.problem
+// @Failure 403 {object} v1.problem
+// @Failure 429 {object} v1.problem
+// @Failure 500 {object} v1.problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /keys [post]
+func (r *keyRoutes) issueKey(c echo.Context) error {
+	var input issueKeyInput
+
+	if err := c.Bind(&input); err != nil {
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		return err
+	}
+
+	scopes := make([]entity.Scope, 0, len(input.Scopes))
+	for _, scope := range input.Scopes {
+		scopes = append(scopes, entity.Scope(scope))
+	}
+
+	key, err := r.authService.IssueKey(c.Request().Context(), service.IssueKeyInput{
+		Name:      input.Name,
+		Scopes:    scopes,
+		ExpiresAt: input.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, issuedKey{APIKey: key.APIKey, Key: key.Secret})
+}
+
+// @Description List all API keys, including expired and revoked ones
+// @Summary List API keys
+// @Produce json
+// @Success 200 {array} entity.APIKey
+// @Failure 401 {object} v1.problem
+// @Failure 403 {object} v1.problem
+// @Failure 429 {object} v1.problem
+// @Failure 500 {object} v1.problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /keys [get]
+func (r *keyRoutes) listKeys(c echo.Context) error {
+	keys, err := r.authService.ListKeys(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, keys)
+}
+
+// @Description Revoke API key by id. Requests with a revoked key are rejected immediately.
+// @Summary Revoke API key
+// @Param id path int true "Key ID" minimum(1) example(1)
+// @Success 204
+// @Failure 400 {object} v1.problem
+// @Failure 401 {object} v1.problem
+// @Failure 403 {object} v1.problem
+// @Failure 404 {object} v1.problem
+// @Failure 429 {object} v1.problem
+// @Failure 500 {object} v1.problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /keys/{id} [delete]
+func (r *keyRoutes) revokeKey(c echo.Context) error {
+	var input keyIdInput
+
+	if err := c.Bind(&input); err != nil {
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		return err
+	}
+
+	if err := r.authService.RevokeKey(c.Request().Context(), input.Id); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,31 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/spanwalla/song-library/pkg/logger"
+)
+
+// requestLogger кладёт в контекст запроса логгер с идентификатором запроса, маршрутом
+// и идентификатором песни, чтобы записи сервиса и репозиториев можно было связать с запросом.
+// Должен стоять после middleware.RequestID.
+func requestLogger() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			fields := log.Fields{
+				logger.FieldRequestId: c.Response().Header().Get(echo.HeaderXRequestID),
+				logger.FieldMethod:    c.Request().Method,
+				logger.FieldRoute:     c.Path(),
+			}
+			if songId := c.Param("id"); len(songId) > 0 {
+				fields[logger.FieldSongId] = songId
+			}
+
+			ctx := logger.WithFields(c.Request().Context(), fields)
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package v1
+
+import "github.com/spanwalla/song-library/pkg/ratelimit"
+
+type options struct {
+	auth       bool
+	limiter    *ratelimit.Limiter
+	rateLimits *RateLimitConfig
+	cors       *CORSConfig
+	baseDomain string
+}
+
+// Option настраивает маршруты API.
+type Option func(*options)
+
+// Auth включает или отключает проверку API-ключей. По умолчанию проверка включена.
+func Auth(enabled bool) Option {
+	return func(o *options) {
+		o.auth = enabled
+	}
+}
+
+// Limits включает ограничение частоты запросов с бюджетами из limits.
+func Limits(limiter *ratelimit.Limiter, limits *RateLimitConfig) Option {
+	return func(o *options) {
+		o.limiter = limiter
+		o.rateLimits = limits
+	}
+}
+
+// TenantDomain включает выбор арендатора по поддомену domain, например team-a.songs.example.com
+// для songs.example.com. Заголовок X-Tenant действует и без этой опции.
+func TenantDomain(domain string) Option {
+	return func(o *options) {
+		o.baseDomain = domain
+	}
+}
+
+// CORS задаёт политики для чтения и изменения данных. Без этой опции разрешены любые сайты.
+func CORS(config *CORSConfig) Option {
+	return func(o *options) {
+		o.cors = config
+	}
+}
@@ -0,0 +1,127 @@
+package v1
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/spanwalla/song-library/internal/service"
+	"github.com/spanwalla/song-library/pkg/logger"
+	"github.com/spanwalla/song-library/pkg/ratelimit"
+)
+
+// Заголовки из черновика IETF RateLimit header fields.
+const (
+	headerRateLimitLimit     = "RateLimit-Limit"
+	headerRateLimitRemaining = "RateLimit-Remaining"
+	headerRateLimitReset     = "RateLimit-Reset"
+	headerRateLimitPolicy    = "RateLimit-Policy"
+	headerRetryAfter         = "Retry-After"
+)
+
+// Бюджеты запросов. У каждого свой счётчик, поэтому поиск не расходует бюджет изменений.
+const (
+	BudgetRead   = "read"
+	BudgetWrite  = "write"
+	BudgetInsert = "insert"
+)
+
+var errRateLimited = newAPIError(http.StatusTooManyRequests, CodeRateLimited, "rate limit exceeded, retry later")
+
+// RateLimits задаёт бюджеты: на чтение, на изменения и отдельный, более строгий,
+// на добавление песен, потому что каждое добавление обращается к внешнему API.
+type RateLimits struct {
+	Read   ratelimit.Limit
+	Write  ratelimit.Limit
+	Insert ratelimit.Limit
+}
+
+func (l RateLimits) budget(name string) ratelimit.Limit {
+	switch name {
+	case BudgetWrite:
+		return l.Write
+	case BudgetInsert:
+		return l.Insert
+	default:
+		return l.Read
+	}
+}
+
+// RateLimitConfig хранит бюджеты, которые можно заменить без перезапуска.
+type RateLimitConfig struct {
+	limits atomic.Pointer[RateLimits]
+}
+
+func NewRateLimitConfig(limits RateLimits) *RateLimitConfig {
+	c := &RateLimitConfig{}
+	c.Set(limits)
+	return c
+}
+
+func (c *RateLimitConfig) Set(limits RateLimits) {
+	c.limits.Store(&limits)
+}
+
+func (c *RateLimitConfig) Get() RateLimits {
+	return *c.limits.Load()
+}
+
+// RateLimit ограничивает число запросов клиента в бюджете budget. Клиент определяется
+// по участнику из контекста, а без аутентификации — по IP-адресу, поэтому middleware
+// ставится после RequireScope. Бюджет читается из config на каждый запрос.
+// Если хранилище счётчиков недоступно, запрос пропускается.
+func RateLimit(limiter *ratelimit.Limiter, budget string, config *RateLimitConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+			limit := config.Get().budget(budget)
+
+			result, err := limiter.Allow(ctx, budget+":"+client(c), limit)
+			if err != nil {
+				logger.From(ctx).Errorf("v1 - RateLimit - limiter.Allow: %v", err)
+				return next(c)
+			}
+
+			if result.Limit > 0 {
+				reset := strconv.Itoa(int(math.Ceil(result.Reset.Seconds())))
+				header := c.Response().Header()
+				header.Set(headerRateLimitLimit, strconv.Itoa(result.Limit))
+				header.Set(headerRateLimitRemaining, strconv.Itoa(result.Remaining))
+				header.Set(headerRateLimitReset, reset)
+				header.Set(headerRateLimitPolicy, fmt.Sprintf("%d;w=%d", limit.Requests, int(limit.Window.Seconds())))
+				if !result.Allowed {
+					header.Set(headerRetryAfter, reset)
+					return errRateLimited
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
+
+func client(c echo.Context) string {
+	if principal, ok := service.PrincipalFrom(c.Request().Context()); ok {
+		return principal.String()
+	}
+	return "ip:" + c.RealIP()
+}
+
+// rateLimiter подключает бюджеты к маршрутам. Без ограничителя запросы не считаются.
+type rateLimiter struct {
+	limiter *ratelimit.Limiter
+	config  *RateLimitConfig
+}
+
+func (r *rateLimiter) limit(budget string) echo.MiddlewareFunc {
+	if r.limiter == nil {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return next
+		}
+	}
+	return RateLimit(r.limiter, budget, r.config)
+}
@@ -1,36 +1,41 @@
 package v1
 
 import (
-	"os"
-
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
-	log "github.com/sirupsen/logrus"
 	echoSwagger "github.com/swaggo/echo-swagger"
 
 	"github.com/spanwalla/song-library/internal/service"
 )
 
-func ConfigureRouter(handler *echo.Echo, services *service.Services) {
-	handler.Use(middleware.CORS())
-	handler.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
-		Format: `{"time":"${time_rfc3339_nano}", "method":"${method}","uri":"${uri}", "status":${status},"error":"${error}"}` + "\n",
-		Output: setLogsFile(),
-	}))
+func ConfigureRouter(handler *echo.Echo, services *service.Services, opts ...Option) {
+	o := &options{auth: true}
+	for _, opt := range opts {
+		opt(o)
+	}
+	access := &accessControl{auth: services.Auth, enabled: o.auth}
+	limiter := &rateLimiter{limiter: o.limiter, config: o.rateLimits}
+
+	handler.HTTPErrorHandler = ErrorHandler
+
+	handler.Use(middleware.RequestID())
+	handler.Use(requestLogger())
+	if o.cors != nil {
+		handler.Use(cors(o.cors))
+	} else {
+		handler.Use(middleware.CORS())
+	}
 	handler.Use(middleware.Recover())
+	handler.Use(resolveTenant(services.Tenant, o.baseDomain))
 
 	handler.GET("/swagger/*", echoSwagger.WrapHandler)
 
 	v1 := handler.Group("/api/v1")
 	{
-		newSongRoutes(v1.Group("/songs"), services.Song)
-	}
-}
-
-func setLogsFile() *os.File {
-	file, err := os.OpenFile("/logs/requests.log", os.O_APPEND|os.O_CREATE|os.O_RDWR, 0666)
-	if err != nil {
-		log.Fatalf("v1 - setLogsFile - os.OpenFile: %v", err)
+		newSongRoutes(v1.Group("/songs"), services.Song, access, limiter)
+		newKeyRoutes(v1.Group("/keys"), services.Auth, access, limiter)
+		newUserRoutes(v1.Group("/auth"), services.Auth, limiter)
+		newRoleRoutes(v1.Group("/users"), services.Auth, access, limiter)
+		newAuditRoutes(v1, services.Audit, access, limiter)
 	}
-	return file
 }
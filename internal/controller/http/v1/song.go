@@ -1,16 +1,25 @@
 package v1
 
 import (
-	"errors"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/labstack/echo/v4"
 
-	_ "github.com/spanwalla/song-library/internal/entity" // for swagger docs
+	"github.com/spanwalla/song-library/internal/entity"
 	"github.com/spanwalla/song-library/internal/service"
 	"github.com/spanwalla/song-library/pkg/query"
 )
 
+const (
+	formatJSON = "json"
+	formatLRC  = "lrc"
+
+	mimeLRC    = "text/plain; charset=utf-8"
+	maxLRCSize = 1 << 20
+)
+
 type songRoutes struct {
 	songService service.Song
 }
@@ -32,21 +41,39 @@ type updateSongInput struct {
 	ReleaseDate *string `json:"releaseDate" validate:"omitempty,date" example:"2006-06-22"`
 }
 
+type getSongTextInput struct {
+	Id         int    `param:"id" validate:"number,gt=0"`
+	Lang       string `query:"lang" validate:"omitempty,lang"`
+	SideBySide bool   `query:"side_by_side"`
+}
+
+type activeLineInput struct {
+	Id int  `param:"id" validate:"number,gt=0"`
+	At *int `query:"at" validate:"required,gte=0"`
+}
+
 type updateSongTextInput struct {
 	Id   int    `param:"id" validate:"number,gt=0"`
 	Text string `json:"text" validate:"required" example:"I can do\nit easily\n\nNew couplet.\n\nAnother one."`
 }
 
-func newSongRoutes(g *echo.Group, songService service.Song) {
+func newSongRoutes(g *echo.Group, songService service.Song, access *accessControl, limiter *rateLimiter) {
 	r := &songRoutes{songService: songService}
-
-	g.GET("", r.searchSongs)
-	g.GET("/:id", r.getSong)
-	g.GET("/:id/text", r.getSongText)
-	g.DELETE("/:id", r.deleteSong)
-	g.PATCH("/:id", r.patchSong)
-	g.PUT("/:id/text", r.putSongText)
-	g.POST("", r.insertSong)
+	read := []echo.MiddlewareFunc{access.require(entity.ScopeRead), limiter.limit(BudgetRead)}
+	write := []echo.MiddlewareFunc{access.require(entity.ScopeWrite), limiter.limit(BudgetWrite)}
+	insert := []echo.MiddlewareFunc{access.require(entity.ScopeWrite), limiter.limit(BudgetInsert)}
+
+	g.GET("", r.searchSongs, read...)
+	g.GET("/:id", r.getSong, read...)
+	g.GET("/:id/text", r.getSongText, read...)
+	g.GET("/:id/text/line", r.getActiveLine, read...)
+	g.GET("/:id/translations", r.listTranslations, read...)
+	g.GET("/:id/translations/:lang", r.getTranslation, read...)
+	g.PUT("/:id/translations/:lang", r.putTranslation, write...)
+	g.DELETE("/:id", r.deleteSong, write...)
+	g.PATCH("/:id", r.patchSong, write...)
+	g.PUT("/:id/text", r.putSongText, write...)
+	g.POST("", r.insertSong, insert...)
 }
 
 // @Description Search songs with filters
@@ -57,8 +84,13 @@ func newSongRoutes(g *echo.Group, songService service.Song) {
 // @Param limit query int false "Limit" default(5) minimum(1) maximum(10) example(10)
 // @Produce json
 // @Success 200 {array} entity.Song
-// @Failure 400 {object} echo.HTTPError
-// @Failure 500 {object} echo.HTTPError
+// @Failure 400 {object} v1.problem
+// @Failure 401 {object} v1.problem
+// @Failure 403 {object} v1.problem
+// @Failure 429 {object} v1.problem
+// @Failure 500 {object} v1.problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /songs [get]
 func (r *songRoutes) searchSongs(c echo.Context) error {
 	q := query.NewParams(c.QueryParams())
@@ -78,7 +110,6 @@ func (r *songRoutes) searchSongs(c echo.Context) error {
 		Limit:   q.Limit,
 	})
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
 		return err
 	}
 
@@ -90,74 +121,99 @@ func (r *songRoutes) searchSongs(c echo.Context) error {
 // @Param id path int true "Song ID" minimum(1) example(2)
 // @Produce json
 // @Success 200 {object} entity.Song
-// @Failure 400 {object} echo.HTTPError
-// @Failure 404 {object} echo.HTTPError
-// @Failure 500 {object} echo.HTTPError
+// @Failure 400 {object} v1.problem
+// @Failure 404 {object} v1.problem
+// @Failure 401 {object} v1.problem
+// @Failure 403 {object} v1.problem
+// @Failure 429 {object} v1.problem
+// @Failure 500 {object} v1.problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /songs/{id} [get]
 func (r *songRoutes) getSong(c echo.Context) error {
 	var input songIdInput
 
 	if err := c.Bind(&input); err != nil {
-		newErrorResponse(c, http.StatusBadRequest, "invalid request")
 		return err
 	}
 
 	if err := c.Validate(input); err != nil {
-		newErrorResponse(c, http.StatusBadRequest, err.Error())
 		return err
 	}
 
 	song, err := r.songService.Get(c.Request().Context(), input.Id)
 	if err != nil {
-		if errors.Is(err, service.ErrSongNotFound) {
-			newErrorResponse(c, http.StatusNotFound, err.Error())
-		} else {
-			newErrorResponse(c, http.StatusInternalServerError, "internal server error")
-		}
 		return err
 	}
 
 	return c.JSON(http.StatusOK, song)
 }
 
-// @Description Get song text with pagination by couplets
+// @Description Get song text with pagination by couplets. With format=lrc the whole text is returned as LRC with line timestamps.
+// @Description With side_by_side=true the response contains "lang", "count" and "couplets" of objects with "number", "original" and "translation".
 // @Summary Get song text
 // @Param id path int true "Song ID" minimum(1) example(2)
 // @Param offset query int false "Offset" default(0) minimum(0) example(10)
 // @Param limit query int false "Limit" default(5) minimum(1) maximum(10) example(10)
+// @Param format query string false "Output format" Enums(json, lrc) default(json)
+// @Param lang query string false "Return the translation into this language instead of the original" example(en)
+// @Param side_by_side query bool false "Return original and translated couplets side by side, requires lang" default(false)
 // @Produce json
+// @Produce plain
 // @Success 200 {object} v1.songRoutes.getSongText.response
-// @Failure 400 {object} echo.HTTPError
-// @Failure 404 {object} echo.HTTPError
-// @Failure 500 {object} echo.HTTPError
+// @Failure 400 {object} v1.problem
+// @Failure 404 {object} v1.problem
+// @Failure 401 {object} v1.problem
+// @Failure 403 {object} v1.problem
+// @Failure 429 {object} v1.problem
+// @Failure 500 {object} v1.problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /songs/{id}/text [get]
 func (r *songRoutes) getSongText(c echo.Context) error {
-	var input songIdInput
+	var input getSongTextInput
 
 	if err := c.Bind(&input); err != nil {
-		newErrorResponse(c, http.StatusBadRequest, "invalid request")
 		return err
 	}
 
 	if err := c.Validate(input); err != nil {
-		newErrorResponse(c, http.StatusBadRequest, err.Error())
 		return err
 	}
 
+	switch c.QueryParam("format") {
+	case "", formatJSON:
+	case formatLRC:
+		if len(input.Lang) > 0 {
+			return errLangNotSupported
+		}
+		return r.getSongTextLRC(c, input.Id)
+	default:
+		return errUnsupportedFormat
+	}
+
 	q := query.NewParams(c.QueryParams())
 	q.ParsePagination()
 
+	if input.SideBySide {
+		if len(input.Lang) == 0 {
+			return errLangRequired
+		}
+		return r.getSideBySide(c, service.GetTextInput{
+			SongId:   input.Id,
+			Language: input.Lang,
+			Offset:   q.Offset,
+			Limit:    q.Limit,
+		})
+	}
+
 	couplets, count, err := r.songService.GetText(c.Request().Context(), service.GetTextInput{
-		SongId: input.Id,
-		Offset: q.Offset,
-		Limit:  q.Limit,
+		SongId:   input.Id,
+		Language: input.Lang,
+		Offset:   q.Offset,
+		Limit:    q.Limit,
 	})
 	if err != nil {
-		if errors.Is(err, service.ErrSongNotFound) {
-			newErrorResponse(c, http.StatusNotFound, err.Error())
-		} else {
-			newErrorResponse(c, http.StatusInternalServerError, "internal server error")
-		}
 		return err
 	}
 
@@ -176,25 +232,28 @@ func (r *songRoutes) getSongText(c echo.Context) error {
 // @Summary Delete song
 // @Param id path int true "Song ID" minimum(1) example(2)
 // @Success 204
-// @Failure 400 {object} echo.HTTPError
-// @Failure 500 {object} echo.HTTPError
+// @Failure 400 {object} v1.problem
+// @Failure 401 {object} v1.problem
+// @Failure 403 {object} v1.problem
+// @Failure 404 {object} v1.problem
+// @Failure 429 {object} v1.problem
+// @Failure 500 {object} v1.problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /songs/{id} [delete]
 func (r *songRoutes) deleteSong(c echo.Context) error {
 	var input songIdInput
 
 	if err := c.Bind(&input); err != nil {
-		newErrorResponse(c, http.StatusBadRequest, "invalid request")
 		return err
 	}
 
 	if err := c.Validate(input); err != nil {
-		newErrorResponse(c, http.StatusBadRequest, err.Error())
 		return err
 	}
 
 	err := r.songService.Delete(c.Request().Context(), input.Id)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
 		return err
 	}
 
@@ -207,19 +266,23 @@ func (r *songRoutes) deleteSong(c echo.Context) error {
 // @Param song body updateSongInput true "JSON-body"
 // @Accept json
 // @Success 204
-// @Failure 400 {object} echo.HTTPError
-// @Failure 500 {object} echo.HTTPError
+// @Failure 400 {object} v1.problem
+// @Failure 401 {object} v1.problem
+// @Failure 403 {object} v1.problem
+// @Failure 404 {object} v1.problem
+// @Failure 429 {object} v1.problem
+// @Failure 500 {object} v1.problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /songs/{id} [patch]
 func (r *songRoutes) patchSong(c echo.Context) error {
 	var input updateSongInput
 
 	if err := c.Bind(&input); err != nil {
-		newErrorResponse(c, http.StatusBadRequest, "invalid request")
 		return err
 	}
 
 	if err := c.Validate(input); err != nil {
-		newErrorResponse(c, http.StatusBadRequest, err.Error())
 		return err
 	}
 
@@ -230,42 +293,50 @@ func (r *songRoutes) patchSong(c echo.Context) error {
 		ReleaseDate: input.ReleaseDate,
 	})
 	if err != nil {
-		if errors.Is(err, service.ErrFieldsAreEmpty) {
-			newErrorResponse(c, http.StatusBadRequest, err.Error())
-		} else {
-			newErrorResponse(c, http.StatusInternalServerError, "internal server error")
-		}
 		return err
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
 
-// @Description Edit song text by id
+// @Description Edit song text by id. With format=lrc the body is an LRC file, couplets are separated by empty lines. Replacing the text deletes all its translations.
 // @Summary Edit song text
 // @Param id path int true "Song ID" example(2)
+// @Param format query string false "Input format" Enums(json, lrc) default(json)
 // @Param text body updateSongTextInput true "New song text. Each couplet is separated by double newline symbols."
 // @Accept json
+// @Accept plain
 // @Success 204
-// @Failure 400 {object} echo.HTTPError
-// @Failure 500 {object} echo.HTTPError
+// @Failure 400 {object} v1.problem
+// @Failure 401 {object} v1.problem
+// @Failure 403 {object} v1.problem
+// @Failure 404 {object} v1.problem
+// @Failure 429 {object} v1.problem
+// @Failure 500 {object} v1.problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /songs/{id}/text [put]
 func (r *songRoutes) putSongText(c echo.Context) error {
+	switch c.QueryParam("format") {
+	case "", formatJSON:
+	case formatLRC:
+		return r.putSongTextLRC(c)
+	default:
+		return errUnsupportedFormat
+	}
+
 	var input updateSongTextInput
 
 	if err := c.Bind(&input); err != nil {
-		newErrorResponse(c, http.StatusBadRequest, "invalid request")
 		return err
 	}
 
 	if err := c.Validate(input); err != nil {
-		newErrorResponse(c, http.StatusBadRequest, err.Error())
 		return err
 	}
 
 	err := r.songService.UpdateText(c.Request().Context(), input.Id, input.Text)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
 		return err
 	}
 
@@ -277,20 +348,23 @@ func (r *songRoutes) putSongText(c echo.Context) error {
 // @Param group body insertSongInput true "Short song info"
 // @Accept json
 // @Success 201
-// @Failure 400 {object} echo.HTTPError
-// @Failure 500 {object} echo.HTTPError
-// @Failure 502 {object} echo.HTTPError
+// @Failure 400 {object} v1.problem
+// @Failure 401 {object} v1.problem
+// @Failure 403 {object} v1.problem
+// @Failure 429 {object} v1.problem
+// @Failure 500 {object} v1.problem
+// @Failure 502 {object} v1.problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /songs [post]
 func (r *songRoutes) insertSong(c echo.Context) error {
 	var input insertSongInput
 
 	if err := c.Bind(&input); err != nil {
-		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
 		return err
 	}
 
 	if err := c.Validate(input); err != nil {
-		newErrorResponse(c, http.StatusBadRequest, err.Error())
 		return err
 	}
 
@@ -299,16 +373,78 @@ func (r *songRoutes) insertSong(c echo.Context) error {
 		Song:  input.Song,
 	})
 	if err != nil {
-		switch {
-		case errors.Is(err, service.ErrCannotInsertSong) || errors.Is(err, service.ErrCannotInsertCouplets):
-			newErrorResponse(c, http.StatusInternalServerError, err.Error())
-		case errors.Is(err, service.ErrCannotGetSongInfo):
-			newErrorResponse(c, http.StatusBadGateway, err.Error())
-		default:
-			newErrorResponse(c, http.StatusInternalServerError, "internal server error")
-		}
 		return err
 	}
 
 	return c.NoContent(http.StatusCreated)
 }
+
+func (r *songRoutes) getSongTextLRC(c echo.Context, songId int) error {
+	text, err := r.songService.ExportLRC(c.Request().Context(), songId)
+	if err != nil {
+		return err
+	}
+
+	return c.Blob(http.StatusOK, mimeLRC, []byte(text))
+}
+
+func (r *songRoutes) putSongTextLRC(c echo.Context) error {
+	var input songIdInput
+
+	if err := (&echo.DefaultBinder{}).BindPathParams(c, &input); err != nil {
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		return err
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxLRCSize+1))
+	if err != nil {
+		return errInvalidBody
+	}
+	if len(body) > maxLRCSize {
+		return errBodyTooLarge
+	}
+
+	err = r.songService.ImportLRC(c.Request().Context(), input.Id, string(body))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// @Description Get the lyrics line that is active at the given playback position. During an instrumental gap there is no active line
+// @Summary Get active line
+// @Param id path int true "Song ID" minimum(1) example(2)
+// @Param at query int true "Playback position in milliseconds" minimum(0) example(83450)
+// @Produce json
+// @Success 200 {object} entity.SyncedLine
+// @Failure 400 {object} v1.problem
+// @Failure 404 {object} v1.problem
+// @Failure 401 {object} v1.problem
+// @Failure 403 {object} v1.problem
+// @Failure 429 {object} v1.problem
+// @Failure 500 {object} v1.problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id}/text/line [get]
+func (r *songRoutes) getActiveLine(c echo.Context) error {
+	var input activeLineInput
+
+	if err := c.Bind(&input); err != nil {
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		return err
+	}
+
+	line, err := r.songService.GetActiveLine(c.Request().Context(), input.Id, time.Duration(*input.At)*time.Millisecond)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, line)
+}
@@ -0,0 +1,61 @@
+package v1
+
+import (
+	"net"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/spanwalla/song-library/internal/service"
+	"github.com/spanwalla/song-library/internal/tenant"
+	"github.com/spanwalla/song-library/pkg/logger"
+)
+
+const headerTenant = "X-Tenant"
+
+// resolveTenant кладёт в контекст арендатора, указанного в заголовке X-Tenant или поддомене
+// baseDomain. Если арендатор не указан, им станет арендатор учётных данных или, без них,
+// арендатор по умолчанию.
+func resolveTenant(tenants service.Tenant, baseDomain string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			slug := c.Request().Header.Get(headerTenant)
+			if len(slug) == 0 {
+				slug = subdomain(c.Request().Host, baseDomain)
+			}
+			if len(slug) == 0 {
+				return next(c)
+			}
+
+			ctx := c.Request().Context()
+			t, err := tenants.Resolve(ctx, slug)
+			if err != nil {
+				return err
+			}
+
+			ctx = tenant.With(ctx, t)
+			ctx = logger.WithFields(ctx, log.Fields{logger.FieldTenant: t.Slug})
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			return next(c)
+		}
+	}
+}
+
+// subdomain возвращает первую метку хоста перед baseDomain: для team-a.songs.example.com
+// и songs.example.com это team-a. Сам baseDomain и другие хосты арендатора не задают.
+func subdomain(host, baseDomain string) string {
+	if len(baseDomain) == 0 {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	label, ok := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(baseDomain))
+	if !ok || strings.Contains(label, ".") {
+		return ""
+	}
+	return label
+}
@@ -0,0 +1,166 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/service"
+	"github.com/spanwalla/song-library/pkg/query"
+)
+
+type translationInput struct {
+	Id   int    `param:"id" validate:"number,gt=0"`
+	Lang string `param:"lang" validate:"required,lang"`
+}
+
+type putTranslationInput struct {
+	Id   int    `param:"id" validate:"number,gt=0"`
+	Lang string `param:"lang" validate:"required,lang"`
+	Text string `json:"text" validate:"required" example:"I see those who don't see me\nI know those who don't know me\n\nNew couplet."`
+}
+
+// @Description List languages the song is translated into
+// @Summary List translations
+// @Param id path int true "Song ID" minimum(1) example(3)
+// @Produce json
+// @Success 200 {array} entity.TranslationInfo
+// @Failure 400 {object} v1.problem
+// @Failure 401 {object} v1.problem
+// @Failure 403 {object} v1.problem
+// @Failure 429 {object} v1.problem
+// @Failure 500 {object} v1.problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id}/translations [get]
+func (r *songRoutes) listTranslations(c echo.Context) error {
+	var input songIdInput
+
+	if err := c.Bind(&input); err != nil {
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		return err
+	}
+
+	infos, err := r.songService.ListTranslations(c.Request().Context(), input.Id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, infos)
+}
+
+// @Description Get song translation with pagination by couplets
+// @Summary Get translation
+// @Param id path int true "Song ID" minimum(1) example(3)
+// @Param lang path string true "Language code" example(en)
+// @Param offset query int false "Offset" default(0) minimum(0) example(10)
+// @Param limit query int false "Limit" default(5) minimum(1) maximum(10) example(10)
+// @Produce json
+// @Success 200 {object} v1.songRoutes.getTranslation.response
+// @Failure 400 {object} v1.problem
+// @Failure 404 {object} v1.problem
+// @Failure 401 {object} v1.problem
+// @Failure 403 {object} v1.problem
+// @Failure 429 {object} v1.problem
+// @Failure 500 {object} v1.problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id}/translations/{lang} [get]
+func (r *songRoutes) getTranslation(c echo.Context) error {
+	var input translationInput
+
+	if err := c.Bind(&input); err != nil {
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		return err
+	}
+
+	q := query.NewParams(c.QueryParams())
+	q.ParsePagination()
+
+	couplets, count, err := r.songService.GetText(c.Request().Context(), service.GetTextInput{
+		SongId:   input.Id,
+		Language: input.Lang,
+		Offset:   q.Offset,
+		Limit:    q.Limit,
+	})
+	if err != nil {
+		return err
+	}
+
+	type response struct {
+		Lang  string   `json:"lang"`
+		Text  []string `json:"text"`
+		Count int      `json:"count"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Lang:  input.Lang,
+		Text:  couplets,
+		Count: count,
+	})
+}
+
+// @Description Add or replace song translation. Couplets are separated by double newline symbols and must match the original couplets.
+// @Summary Put translation
+// @Param id path int true "Song ID" minimum(1) example(3)
+// @Param lang path string true "Language code" example(en)
+// @Param text body putTranslationInput true "Translated text"
+// @Accept json
+// @Success 204
+// @Failure 400 {object} v1.problem
+// @Failure 404 {object} v1.problem
+// @Failure 401 {object} v1.problem
+// @Failure 403 {object} v1.problem
+// @Failure 429 {object} v1.problem
+// @Failure 500 {object} v1.problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id}/translations/{lang} [put]
+func (r *songRoutes) putTranslation(c echo.Context) error {
+	var input putTranslationInput
+
+	if err := c.Bind(&input); err != nil {
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		return err
+	}
+
+	err := r.songService.PutTranslation(c.Request().Context(), service.PutTranslationInput{
+		SongId:   input.Id,
+		Language: input.Lang,
+		Text:     input.Text,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (r *songRoutes) getSideBySide(c echo.Context, input service.GetTextInput) error {
+	couplets, count, err := r.songService.GetSideBySide(c.Request().Context(), input)
+	if err != nil {
+		return err
+	}
+
+	type response struct {
+		Lang     string                  `json:"lang"`
+		Couplets []entity.AlignedCouplet `json:"couplets"`
+		Count    int                     `json:"count"`
+	}
+
+	return c.JSON(http.StatusOK, response{
+		Lang:     input.Language,
+		Couplets: couplets,
+		Count:    count,
+	})
+}
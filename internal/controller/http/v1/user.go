@@ -0,0 +1,306 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/service"
+)
+
+const tokenTypeBearer = "Bearer"
+
+type userRoutes struct {
+	authService service.Auth
+}
+
+// credentialsInput: bcrypt принимает не больше 72 байт пароля, поэтому длина ограничена в байтах.
+type credentialsInput struct {
+	Username string `json:"username" validate:"required,min=3,max=64" example:"kurt"`
+	Password string `json:"password" validate:"required,min=8,maxbytes=72" example:"smells-like-teen-spirit"`
+}
+
+// registerInput: приглашение нужно для библиотек, закрытых для свободной регистрации.
+type registerInput struct {
+	credentialsInput
+	Invite string `json:"invite" validate:"max=64" example:"Vb2yq1h0oX4k9cQz8sJt6wE3rN5mL7pA"`
+}
+
+type issueInviteInput struct {
+	ExpiresAt *time.Time `json:"expiresAt" example:"2025-05-19T10:00:00Z"`
+}
+
+// issuedInvite возвращается один раз при создании приглашения: код в базе не хранится.
+type issuedInvite struct {
+	entity.Invite
+	Code string `json:"code" example:"Vb2yq1h0oX4k9cQz8sJt6wE3rN5mL7pA"`
+}
+
+type refreshTokenInput struct {
+	RefreshToken string `json:"refreshToken" validate:"required" example:"q9QhN1c3m2c4Yz0m1sW5o8J7a6B2vX4kL0pR3tU5wY8"`
+}
+
+type assignRoleInput struct {
+	Id   int    `param:"id" validate:"number,gt=0"`
+	Role string `json:"role" validate:"required,oneof=viewer editor moderator admin" example:"editor"`
+}
+
+type tokensResponse struct {
+	AccessToken  string `json:"accessToken" example:"eyJhbGciOiJIUzI1NiIsImtpZCI6ImtleS0xIiwidHlwIjoiSldUIn0..."`
+	RefreshToken string `json:"refreshToken" example:"q9QhN1c3m2c4Yz0m1sW5o8J7a6B2vX4kL0pR3tU5wY8"`
+	TokenType    string `json:"tokenType" example:"Bearer"`
+	ExpiresIn    int    `json:"expiresIn" example:"900"`
+}
+
+func newUserRoutes(g *echo.Group, authService service.Auth, limiter *rateLimiter) {
+	r := &userRoutes{authService: authService}
+	// Запросы без аутентификации считаются по IP, бюджет добавления ограничивает подбор паролей.
+	strict := limiter.limit(BudgetInsert)
+
+	g.POST("/register", r.register, strict)
+	g.POST("/login", r.login, strict)
+	g.POST("/refresh", r.refresh, strict)
+	g.POST("/logout", r.logout, strict)
+}
+
+// newRoleRoutes регистрирует управление ролями пользователей, доступное только администраторам.
+func newRoleRoutes(g *echo.Group, authService service.Auth, access *accessControl, limiter *rateLimiter) {
+	r := &userRoutes{authService: authService}
+	admin := []echo.MiddlewareFunc{access.require(entity.ScopeAdmin), limiter.limit(BudgetWrite)}
+
+	g.GET("", r.listUsers, admin...)
+	g.PUT("/:id/role", r.assignRole, admin...)
+	g.POST("/invites", r.issueInvite, admin...)
+}
+
+// @Description Create a user account with the viewer role in the tenant of the request. An administrator can grant another role at /users/{id}/role.
+// @Description Without an invite only the default tenant and tenants open for registration accept new users.
+// @Summary Register
+// @Param input body v1.registerInput true "Username, password and optional invite code"
+// @Accept json
+// @Produce json
+// @Success 201 {object} entity.User
+// @Failure 400 {object} v1.problem
+// @Failure 403 {object} v1.problem
+// @Failure 409 {object} v1.problem
+// @Failure 429 {object} v1.problem
+// @Failure 500 {object} v1.problem
+// @Router /auth/register [post]
+func (r *userRoutes) register(c echo.Context) error {
+	var input registerInput
+
+	if err := c.Bind(&input); err != nil {
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		return err
+	}
+
+	user, err := r.authService.Register(c.Request().Context(), service.RegisterInput{
+		Username: input.Username,
+		Password: input.Password,
+		Invite:   input.Invite,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, user)
+}
+
+// @Description Log in with username and password. The access token is sent as "Authorization: Bearer <token>",
+// @Description the refresh token is exchanged for a new pair at /auth/refresh.
+// @Description Usernames are unique within a tenant: pass the tenant in X-Tenant or the subdomain as at registration, otherwise the default tenant is searched.
+// @Summary Log in
+// @Param input body v1.credentialsInput true "Username and password"
+// @Accept json
+// @Produce json
+// @Success 200 {object} v1.tokensResponse
+// @Failure 400 {object} v1.problem
+// @Failure 401 {object} v1.problem
+// @Failure 429 {object} v1.problem
+// @Failure 500 {object} v1.problem
+// @Failure 503 {object} v1.problem
+// @Router /auth/login [post]
+func (r *userRoutes) login(c echo.Context) error {
+	var input credentialsInput
+
+	if err := c.Bind(&input); err != nil {
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		return err
+	}
+
+	tokens, err := r.authService.Login(c.Request().Context(), service.LoginInput{
+		Username: input.Username,
+		Password: input.Password,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, newTokensResponse(tokens))
+}
+
+// @Description Exchange a refresh token for a new pair of tokens. The old refresh token stops working;
+// @Description reusing it revokes all sessions of the user.
+// @Summary Refresh tokens
+// @Param input body v1.refreshTokenInput true "Refresh token"
+// @Accept json
+// @Produce json
+// @Success 200 {object} v1.tokensResponse
+// @Failure 400 {object} v1.problem
+// @Failure 401 {object} v1.problem
+// @Failure 429 {object} v1.problem
+// @Failure 500 {object} v1.problem
+// @Failure 503 {object} v1.problem
+// @Router /auth/refresh [post]
+func (r *userRoutes) refresh(c echo.Context) error {
+	var input refreshTokenInput
+
+	if err := c.Bind(&input); err != nil {
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		return err
+	}
+
+	tokens, err := r.authService.Refresh(c.Request().Context(), input.RefreshToken)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, newTokensResponse(tokens))
+}
+
+// @Description End the session of the refresh token. Access tokens already issued stay valid until they expire.
+// @Summary Log out
+// @Param input body v1.refreshTokenInput true "Refresh token"
+// @Accept json
+// @Success 204
+// @Failure 400 {object} v1.problem
+// @Failure 401 {object} v1.problem
+// @Failure 429 {object} v1.problem
+// @Failure 500 {object} v1.problem
+// @Router /auth/logout [post]
+func (r *userRoutes) logout(c echo.Context) error {
+	var input refreshTokenInput
+
+	if err := c.Bind(&input); err != nil {
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		return err
+	}
+
+	if err := r.authService.Logout(c.Request().Context(), input.RefreshToken); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func newTokensResponse(tokens service.Tokens) tokensResponse {
+	return tokensResponse{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		TokenType:    tokenTypeBearer,
+		ExpiresIn:    int(tokens.ExpiresIn.Seconds()),
+	}
+}
+
+// @Description List users with their roles
+// @Summary List users
+// @Produce json
+// @Success 200 {array} entity.User
+// @Failure 401 {object} v1.problem
+// @Failure 403 {object} v1.problem
+// @Failure 429 {object} v1.problem
+// @Failure 500 {object} v1.problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users [get]
+func (r *userRoutes) listUsers(c echo.Context) error {
+	users, err := r.authService.ListUsers(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, users)
+}
+
+// @Description Assign a role to the user: viewer reads songs, editor also changes songs and lyrics,
+// @Description moderator also adds and deletes songs, admin also manages roles.
+// @Description The user gets the new role with the next access token.
+// @Summary Assign role
+// @Param id path int true "User ID" minimum(1) example(1)
+// @Param input body v1.assignRoleInput true "Role"
+// @Accept json
+// @Produce json
+// @Success 200 {object} entity.User
+// @Failure 400 {object} v1.problem
+// @Failure 401 {object} v1.problem
+// @Failure 403 {object} v1.problem
+// @Failure 404 {object} v1.problem
+// @Failure 429 {object} v1.problem
+// @Failure 500 {object} v1.problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/{id}/role [put]
+func (r *userRoutes) assignRole(c echo.Context) error {
+	var input assignRoleInput
+
+	if err := c.Bind(&input); err != nil {
+		return err
+	}
+
+	if err := c.Validate(input); err != nil {
+		return err
+	}
+
+	user, err := r.authService.AssignRole(c.Request().Context(), input.Id, entity.Role(input.Role))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+// @Description Create a one-time invite to register in the tenant of the administrator. The code is shown only in this response,
+// @Description the library stores its hash. Without expiresAt the invite is valid for a week.
+// @Summary Issue invite
+// @Param input body v1.issueInviteInput false "Optional expiry"
+// @Accept json
+// @Produce json
+// @Success 201 {object} v1.issuedInvite
+// @Failure 400 {object} v1.problem
+// @Failure 401 {object} v1.problem
+// @Failure 403 {object} v1.problem
+// @Failure 429 {object} v1.problem
+// @Failure 500 {object} v1.problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/invites [post]
+func (r *userRoutes) issueInvite(c echo.Context) error {
+	var input issueInviteInput
+
+	if err := c.Bind(&input); err != nil {
+		return err
+	}
+
+	invite, err := r.authService.IssueInvite(c.Request().Context(), service.IssueInviteInput{
+		ExpiresAt: input.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, issuedInvite{Invite: invite.Invite, Code: invite.Code})
+}
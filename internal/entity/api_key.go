@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"slices"
+	"time"
+)
+
+// Scope определяет уровень доступа API-ключа. Каждый следующий уровень включает предыдущие:
+// admin может всё, что может write, а write — всё, что может read.
+type Scope string
+
+const (
+	ScopeRead  Scope = "read"
+	ScopeWrite Scope = "write"
+	ScopeAdmin Scope = "admin"
+)
+
+var scopeLevels = map[Scope]int{
+	ScopeRead:  1,
+	ScopeWrite: 2,
+	ScopeAdmin: 3,
+}
+
+// Valid сообщает, известен ли уровень доступа.
+func (s Scope) Valid() bool {
+	_, ok := scopeLevels[s]
+	return ok
+}
+
+// Includes сообщает, покрывает ли s уровень доступа required.
+func (s Scope) Includes(required Scope) bool {
+	return s.Valid() && scopeLevels[s] >= scopeLevels[required]
+}
+
+type APIKey struct {
+	Id        int        `json:"id" example:"1"`
+	TenantId  int        `json:"-"`
+	Name      string     `json:"name" example:"importer"`
+	Prefix    string     `json:"prefix" example:"sl_3f9a1c2b"`
+	Hash      string     `json:"-"`
+	Scopes    []Scope    `json:"scopes" swaggertype:"array,string" example:"read,write"`
+	CreatedAt time.Time  `json:"createdAt" example:"2025-04-22T10:30:00Z"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" example:"2025-07-22T10:30:00Z"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+// HasScope сообщает, даёт ли ключ уровень доступа required.
+func (k APIKey) HasScope(required Scope) bool {
+	return slices.ContainsFunc(k.Scopes, func(s Scope) bool { return s.Includes(required) })
+}
+
+// Active сообщает, что ключ не отозван и не истёк к моменту now.
+func (k APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditAction определяет вид изменения песни в журнале аудита.
+type AuditAction string
+
+const (
+	AuditInsert     AuditAction = "insert"
+	AuditUpdate     AuditAction = "update"
+	AuditUpdateText AuditAction = "update_text"
+	AuditDelete     AuditAction = "delete"
+)
+
+// AuditEntry описывает одно изменение песни: кто, когда и в рамках какого запроса его сделал,
+// а также состояние до и после изменения. При добавлении Before пуст, при удалении пуст After.
+type AuditEntry struct {
+	Id        int             `json:"id" example:"1"`
+	SongId    int             `json:"songId" example:"2"`
+	Action    AuditAction     `json:"action" example:"update"`
+	Actor     string          `json:"actor" example:"user:1"`
+	RequestId string          `json:"requestId,omitempty" example:"3XhQUxlnBF6nLq0Jzgs1lSNWBUZ3iLg3"`
+	Before    json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After     json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	CreatedAt time.Time       `json:"createdAt" example:"2025-05-02T11:00:00Z"`
+}
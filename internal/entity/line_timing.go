@@ -0,0 +1,17 @@
+package entity
+
+// LineTiming — метка времени строки куплета. EndMs задан, если после строки идёт проигрыш.
+type LineTiming struct {
+	SongId         int  `db:"song_id"`
+	SequenceNumber int  `db:"sequence_number"`
+	LineNumber     int  `db:"line_number"`
+	StartMs        int  `db:"start_ms"`
+	EndMs          *int `db:"end_ms"`
+}
+
+type SyncedLine struct {
+	SequenceNumber int    `db:"sequence_number" json:"couplet" example:"2"`
+	LineNumber     int    `db:"line_number" json:"line" example:"3"`
+	StartMs        int    `db:"start_ms" json:"startMs" example:"83450"`
+	Text           string `db:"line_text" json:"text" example:"Take me to the magic of the moment on a glory night,"`
+}
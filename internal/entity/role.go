@@ -0,0 +1,77 @@
+package entity
+
+import "slices"
+
+// Role определяет, какие операции с библиотекой доступны участнику.
+type Role string
+
+const (
+	RoleViewer    Role = "viewer"
+	RoleEditor    Role = "editor"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+// Permission — отдельная операция, которую проверяет сервисный слой.
+type Permission string
+
+const (
+	PermReadSongs   Permission = "songs:read"
+	PermEditSongs   Permission = "songs:edit"
+	PermEditLyrics  Permission = "lyrics:edit"
+	PermInsertSongs Permission = "songs:insert"
+	PermDeleteSongs Permission = "songs:delete"
+	PermManageRoles Permission = "roles:manage"
+)
+
+// rolePermissions перечисляет разрешения ролей. Каждая следующая роль включает предыдущую.
+var rolePermissions = map[Role][]Permission{
+	RoleViewer:    {PermReadSongs},
+	RoleEditor:    {PermReadSongs, PermEditSongs, PermEditLyrics},
+	RoleModerator: {PermReadSongs, PermEditSongs, PermEditLyrics, PermInsertSongs, PermDeleteSongs},
+	RoleAdmin:     {PermReadSongs, PermEditSongs, PermEditLyrics, PermInsertSongs, PermDeleteSongs, PermManageRoles},
+}
+
+// Roles возвращает все роли от младшей к старшей.
+func Roles() []Role {
+	return []Role{RoleViewer, RoleEditor, RoleModerator, RoleAdmin}
+}
+
+// Valid сообщает, известна ли роль.
+func (r Role) Valid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Can сообщает, разрешена ли роли операция.
+func (r Role) Can(p Permission) bool {
+	return slices.Contains(rolePermissions[r], p)
+}
+
+// Scope возвращает уровень доступа, который нужен роли на маршрутах API.
+// Изменять песни могут начиная с редактора, поэтому ему уже нужен write.
+func (r Role) Scope() Scope {
+	switch r {
+	case RoleAdmin:
+		return ScopeAdmin
+	case RoleEditor, RoleModerator:
+		return ScopeWrite
+	default:
+		return ScopeRead
+	}
+}
+
+// RoleForScopes возвращает роль API-ключа: ключ с write может всё, что мог до появления ролей,
+// то есть добавлять и удалять песни.
+func RoleForScopes(scopes []Scope) Role {
+	role := RoleViewer
+	for _, scope := range scopes {
+		if scope == ScopeAdmin {
+			return RoleAdmin
+		}
+		if scope == ScopeWrite {
+			role = RoleModerator
+		}
+	}
+	return role
+}
@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"regexp"
+	"time"
+)
+
+// DefaultTenantId — библиотека, в которой оказались песни, созданные до появления арендаторов.
+// В неё же попадают запросы, для которых арендатор не указан.
+const DefaultTenantId = 1
+
+var tenantSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// Tenant — отдельная библиотека песен внутри одной установки сервиса.
+// Slug указывается в заголовке X-Tenant или как поддомен.
+type Tenant struct {
+	Id        int       `json:"id" example:"1"`
+	Slug      string    `json:"slug" example:"default"`
+	Name      string    `json:"name" example:"Default"`
+	CreatedAt time.Time `json:"createdAt" example:"2025-05-10T12:00:00Z"`
+}
+
+// ValidTenantSlug сообщает, подходит ли slug для заголовка и поддомена: строчные латинские
+// буквы, цифры и дефис, не больше 63 символов.
+func ValidTenantSlug(slug string) bool {
+	return tenantSlugPattern.MatchString(slug)
+}
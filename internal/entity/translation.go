@@ -0,0 +1,19 @@
+package entity
+
+type Translation struct {
+	SongId         int    `db:"song_id"`
+	Language       string `db:"lang"`
+	SequenceNumber int    `db:"sequence_number"`
+	Text           string `db:"couplet_text"`
+}
+
+type TranslationInfo struct {
+	Language string `db:"lang" json:"lang" example:"en"`
+	Count    int    `db:"count" json:"count" example:"5"`
+}
+
+type AlignedCouplet struct {
+	SequenceNumber int    `json:"number" example:"1"`
+	Original       string `json:"original" example:"Вижу тех, кто меня не видит"`
+	Translation    string `json:"translation" example:"I see those who don't see me"`
+}
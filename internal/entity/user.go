@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+type User struct {
+	Id           int       `json:"id" example:"1"`
+	TenantId     int       `json:"-"`
+	Username     string    `json:"username" example:"kurt"`
+	PasswordHash string    `json:"-"`
+	Role         Role      `json:"role" example:"editor"`
+	CreatedAt    time.Time `json:"createdAt" example:"2025-04-26T14:15:00Z"`
+}
+
+// Session описывает вход пользователя. По refresh-токену сессии выдаются новые
+// access-токены, в базе хранится только его хэш.
+type Session struct {
+	Id        int
+	UserId    int
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+}
+
+// Invite разрешает зарегистрироваться в библиотеке, закрытой для свободной регистрации.
+// Приглашение действует один раз, в базе хранится только хэш кода.
+type Invite struct {
+	Id        int        `json:"id" example:"1"`
+	TenantId  int        `json:"-"`
+	Hash      string     `json:"-"`
+	CreatedAt time.Time  `json:"createdAt" example:"2025-05-12T10:00:00Z"`
+	ExpiresAt time.Time  `json:"expiresAt" example:"2025-05-19T10:00:00Z"`
+	UsedAt    *time.Time `json:"usedAt,omitempty"`
+}
@@ -0,0 +1,75 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrMigrationsNotApplied = errors.New("migrations are not applied")
+	ErrMigrationsDirty      = errors.New("last migration failed, database is dirty")
+	ErrMigrationsOutdated   = errors.New("database schema is outdated")
+)
+
+// PostgresCheck проверяет, что пул может выдать соединение и база отвечает.
+func PostgresCheck(pool *pgxpool.Pool) CheckFunc {
+	return func(ctx context.Context) error {
+		return pool.Ping(ctx)
+	}
+}
+
+// MigrationsCheck сравнивает версию схемы из таблицы golang-migrate с последней известной миграцией.
+func MigrationsCheck(pool *pgxpool.Pool, expected uint) CheckFunc {
+	return func(ctx context.Context) error {
+		var (
+			version int64
+			dirty   bool
+		)
+
+		err := pool.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrMigrationsNotApplied
+			}
+			return err
+		}
+
+		if dirty {
+			return fmt.Errorf("%w: version %d", ErrMigrationsDirty, version)
+		}
+		if uint(version) < expected {
+			return fmt.Errorf("%w: version %d, expected %d", ErrMigrationsOutdated, version, expected)
+		}
+
+		return nil
+	}
+}
+
+// LatestMigration возвращает номер последней миграции в каталоге.
+func LatestMigration(migrations fs.FS) (uint, error) {
+	entries, err := fs.ReadDir(migrations, ".")
+	if err != nil {
+		return 0, fmt.Errorf("health - LatestMigration - fs.ReadDir: %w", err)
+	}
+
+	var latest uint
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		m, err := source.DefaultParse(entry.Name())
+		if err != nil {
+			continue
+		}
+		latest = max(latest, m.Version)
+	}
+
+	return latest, nil
+}
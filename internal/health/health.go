@@ -0,0 +1,126 @@
+// Package health отвечает на проверки живости и готовности сервиса.
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	StatusOK           = "ok"
+	StatusFail         = "fail"
+	StatusShuttingDown = "shutting_down"
+)
+
+const defaultTimeout = 2 * time.Second
+
+// CheckFunc проверяет одну зависимость и возвращает ошибку, если она недоступна.
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// CheckResult описывает результат проверки одной зависимости.
+type CheckResult struct {
+	Status    string `json:"status" example:"ok"`
+	LatencyMs int64  `json:"latencyMs" example:"3"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Report описывает ответ /readyz.
+type Report struct {
+	Status string                 `json:"status" example:"ok"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type Health struct {
+	checks       []check
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+func New(opts ...Option) *Health {
+	h := &Health{timeout: defaultTimeout}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// Add регистрирует проверку зависимости. Сервис готов, только если проходят все проверки.
+func (h *Health) Add(name string, fn CheckFunc) {
+	h.checks = append(h.checks, check{name: name, fn: fn})
+}
+
+// Shutdown переводит сервис в состояние «не готов», чтобы балансировщик перестал отправлять
+// новые запросы до остановки HTTP-сервера. Проверка живости при этом продолжает проходить.
+func (h *Health) Shutdown() {
+	h.shuttingDown.Store(true)
+}
+
+// Check параллельно выполняет все проверки с общим таймаутом.
+func (h *Health) Check(ctx context.Context) Report {
+	if h.shuttingDown.Load() {
+		return Report{Status: StatusShuttingDown}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(h.checks))}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, c := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			start := time.Now()
+			err := c.fn(ctx)
+			result := CheckResult{Status: StatusOK, LatencyMs: time.Since(start).Milliseconds()}
+			if err != nil {
+				result.Status, result.Error = StatusFail, err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[c.name] = result
+			if err != nil {
+				report.Status = StatusFail
+			}
+		}()
+	}
+	wg.Wait()
+
+	return report
+}
+
+// Liveness отвечает, что процесс жив. Зависимости не проверяются, чтобы их недоступность
+// не приводила к перезапуску сервиса.
+func (h *Health) Liveness(c echo.Context) error {
+	return c.JSON(http.StatusOK, Report{Status: StatusOK})
+}
+
+// Readiness отвечает 200, если все зависимости доступны, и 503 в противном случае.
+func (h *Health) Readiness(c echo.Context) error {
+	report := h.Check(c.Request().Context())
+
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	return c.JSON(status, report)
+}
@@ -0,0 +1,12 @@
+package health
+
+import "time"
+
+type Option func(*Health)
+
+// Timeout ограничивает время выполнения всех проверок одного запроса.
+func Timeout(timeout time.Duration) Option {
+	return func(h *Health) {
+		h.timeout = timeout
+	}
+}
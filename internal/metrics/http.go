@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const unknownRoute = "unknown"
+
+// HTTPMiddleware считает запросы и их длительность по шаблону маршрута, а не по пути,
+// чтобы идентификаторы песен не раздували количество рядов.
+func (m *Metrics) HTTPMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			m.httpRequestsInFlight.Inc()
+			defer m.httpRequestsInFlight.Dec()
+
+			start := time.Now()
+			err := next(c)
+			if err != nil {
+				// Ошибку нужно отдать клиенту здесь, иначе статус ответа ещё не известен.
+				c.Error(err)
+			}
+
+			route := c.Path()
+			if len(route) == 0 {
+				route = unknownRoute
+			}
+			labels := []string{c.Request().Method, route, strconv.Itoa(c.Response().Status)}
+
+			m.httpRequests.WithLabelValues(labels...).Inc()
+			m.httpRequestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Package metrics собирает метрики Prometheus для HTTP-обработчиков, пула соединений, внешнего API и бизнес-операций.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "song_library"
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests         *prometheus.CounterVec
+	httpRequestDuration  *prometheus.HistogramVec
+	httpRequestsInFlight prometheus.Gauge
+
+	songInfoRequests        *prometheus.CounterVec
+	songInfoRequestDuration *prometheus.HistogramVec
+
+	songsInserted        prometheus.Counter
+	songInsertsFailed    *prometheus.CounterVec
+	songOperationsFailed *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of HTTP requests by route and status.",
+		}, []string{"method", "route", "status"}),
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		httpRequestsInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_in_flight",
+			Help:      "Number of HTTP requests being served.",
+		}),
+
+		songInfoRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "song_info",
+			Name:      "requests_total",
+			Help:      "Number of requests to the external song info API by outcome.",
+		}, []string{"outcome"}),
+		songInfoRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "song_info",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of requests to the external song info API by outcome.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"outcome"}),
+
+		songsInserted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "songs_inserted_total",
+			Help:      "Number of songs added to the library.",
+		}),
+		songInsertsFailed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "song_inserts_failed_total",
+			Help:      "Number of failed song inserts by reason.",
+		}, []string{"reason"}),
+		songOperationsFailed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "song_operations_failed_total",
+			Help:      "Number of failed song updates and deletes by operation.",
+		}, []string{"operation"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpRequestDuration,
+		m.httpRequestsInFlight,
+		m.songInfoRequests,
+		m.songInfoRequestDuration,
+		m.songsInserted,
+		m.songInsertsFailed,
+		m.songOperationsFailed,
+	)
+
+	return m
+}
+
+// Register добавляет сторонний коллектор, например статистику пула соединений.
+func (m *Metrics) Register(c prometheus.Collector) error {
+	return m.registry.Register(c)
+}
+
+// Handler отдаёт метрики в формате Prometheus.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+
+	"github.com/spanwalla/song-library/internal/service"
+)
+
+// insertFailureReasons сопоставляет ошибки добавления песни с причинами в метрике.
+var insertFailureReasons = []struct {
+	err    error
+	reason string
+}{
+	{service.ErrCannotGetSongInfo, "song_info"},
+	{service.ErrCannotInsertSong, "song_insert"},
+	{service.ErrCannotInsertCouplets, "couplets_insert"},
+}
+
+type songService struct {
+	service.Song
+	m *Metrics
+}
+
+// SongService оборачивает сервис песен и считает бизнес-операции.
+func (m *Metrics) SongService(next service.Song) service.Song {
+	return &songService{Song: next, m: m}
+}
+
+func (s *songService) Insert(ctx context.Context, input service.InsertSongInput) error {
+	err := s.Song.Insert(ctx, input)
+	if err == nil {
+		s.m.songsInserted.Inc()
+		return nil
+	}
+
+	s.m.songInsertsFailed.WithLabelValues(insertFailureReason(err)).Inc()
+	return err
+}
+
+func (s *songService) Import(ctx context.Context, inputs []service.InsertSongInput) ([]int, error) {
+	ids, err := s.Song.Import(ctx, inputs)
+	if err == nil {
+		s.m.songsInserted.Add(float64(len(ids)))
+		return ids, nil
+	}
+
+	s.m.songInsertsFailed.WithLabelValues(insertFailureReason(err)).Add(float64(len(inputs)))
+	return nil, err
+}
+
+func insertFailureReason(err error) string {
+	for _, r := range insertFailureReasons {
+		if errors.Is(err, r.err) {
+			return r.reason
+		}
+	}
+	return "other"
+}
+
+func (s *songService) Update(ctx context.Context, songId int, input service.UpdateSongInput) error {
+	err := s.Song.Update(ctx, songId, input)
+	if err != nil && !errors.Is(err, service.ErrFieldsAreEmpty) {
+		s.m.songOperationsFailed.WithLabelValues("update").Inc()
+	}
+	return err
+}
+
+func (s *songService) UpdateText(ctx context.Context, songId int, text string) error {
+	err := s.Song.UpdateText(ctx, songId, text)
+	if err != nil {
+		s.m.songOperationsFailed.WithLabelValues("update_text").Inc()
+	}
+	return err
+}
+
+func (s *songService) Delete(ctx context.Context, songId int) error {
+	err := s.Song.Delete(ctx, songId)
+	if err != nil {
+		s.m.songOperationsFailed.WithLabelValues("delete").Inc()
+	}
+	return err
+}
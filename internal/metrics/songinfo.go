@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/spanwalla/song-library/internal/webapi"
+)
+
+const (
+	outcomeSuccess  = "success"
+	outcomeError    = "error"
+	outcomeTimeout  = "timeout"
+	outcomeCanceled = "canceled"
+)
+
+type songInfo struct {
+	webapi.SongInfo
+	m *Metrics
+}
+
+// SongInfo оборачивает клиент внешнего API и замеряет длительность и исход каждого запроса.
+func (m *Metrics) SongInfo(next webapi.SongInfo) webapi.SongInfo {
+	return &songInfo{SongInfo: next, m: m}
+}
+
+func (s *songInfo) Get(ctx context.Context, group, song string) (webapi.GetSongInfoOutput, error) {
+	start := time.Now()
+	output, err := s.SongInfo.Get(ctx, group, song)
+
+	outcome := outcomeSuccess
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		outcome = outcomeTimeout
+	case errors.Is(err, context.Canceled):
+		outcome = outcomeCanceled
+	case err != nil:
+		outcome = outcomeError
+	}
+
+	s.m.songInfoRequests.WithLabelValues(outcome).Inc()
+	s.m.songInfoRequestDuration.WithLabelValues(outcome).Observe(time.Since(start).Seconds())
+
+	return output, err
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/tenant"
+	"github.com/spanwalla/song-library/pkg/postgres"
+)
+
+const apiKeyColumns = "id, tenant_id, name, prefix, key_hash, scopes, created_at, expires_at, revoked_at"
+
+type APIKeyRepo struct {
+	*postgres.Postgres
+}
+
+func NewAPIKeyRepo(pg *postgres.Postgres) *APIKeyRepo {
+	return &APIKeyRepo{pg}
+}
+
+func (r *APIKeyRepo) Insert(ctx context.Context, key entity.APIKey) (entity.APIKey, error) {
+	sql, args, _ := r.Builder.
+		Insert("api_keys").
+		Columns("tenant_id, name, prefix, key_hash, scopes, expires_at").
+		Values(tenant.Id(ctx), key.Name, key.Prefix, key.Hash, scopesToStrings(key.Scopes), key.ExpiresAt).
+		Suffix("RETURNING " + apiKeyColumns).
+		ToSql()
+
+	key, err := scanAPIKey(r.GetQueryRunner(ctx).QueryRow(ctx, sql, args...))
+	if err != nil {
+		return entity.APIKey{}, fmt.Errorf("APIKeyRepo.Insert - QueryRow: %w", err)
+	}
+
+	return key, nil
+}
+
+func (r *APIKeyRepo) GetByHash(ctx context.Context, hash string) (entity.APIKey, error) {
+	sql, args, _ := r.Builder.
+		Select(apiKeyColumns).
+		From("api_keys").
+		Where("key_hash = ?", hash).
+		ToSql()
+
+	key, err := scanAPIKey(r.GetQueryRunner(ctx).QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.APIKey{}, ErrNotFound
+		}
+		return entity.APIKey{}, fmt.Errorf("APIKeyRepo.GetByHash - QueryRow: %w", err)
+	}
+
+	return key, nil
+}
+
+func (r *APIKeyRepo) List(ctx context.Context) ([]entity.APIKey, error) {
+	sql, args, _ := r.Builder.
+		Select(apiKeyColumns).
+		From("api_keys").
+		Where("tenant_id = ?", tenant.Id(ctx)).
+		OrderBy("id").
+		ToSql()
+
+	rows, err := r.GetQueryRunner(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("APIKeyRepo.List - Query: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]entity.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("APIKeyRepo.List - Scan: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// Revoke отзывает ключ. Повторный отзыв не меняет время первого.
+func (r *APIKeyRepo) Revoke(ctx context.Context, id int) error {
+	sql, args, _ := r.Builder.
+		Update("api_keys").
+		Set("revoked_at", squirrel.Expr("COALESCE(revoked_at, now())")).
+		Where("id = ? AND tenant_id = ?", id, tenant.Id(ctx)).
+		ToSql()
+
+	cmdTag, err := r.GetQueryRunner(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("APIKeyRepo.Revoke - Exec: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func scanAPIKey(row pgx.Row) (entity.APIKey, error) {
+	var (
+		key    entity.APIKey
+		scopes []string
+	)
+	err := row.Scan(&key.Id, &key.TenantId, &key.Name, &key.Prefix, &key.Hash, &scopes, &key.CreatedAt, &key.ExpiresAt, &key.RevokedAt)
+	if err != nil {
+		return entity.APIKey{}, err
+	}
+
+	key.Scopes = make([]entity.Scope, 0, len(scopes))
+	for _, scope := range scopes {
+		key.Scopes = append(key.Scopes, entity.Scope(scope))
+	}
+
+	return key, nil
+}
+
+func scopesToStrings(scopes []entity.Scope) []string {
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		result = append(result, string(scope))
+	}
+	return result
+}
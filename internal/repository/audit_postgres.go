@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/tenant"
+	"github.com/spanwalla/song-library/pkg/postgres"
+)
+
+const auditColumns = "id, song_id, action, actor, request_id, before, after, created_at"
+
+type AuditRepo struct {
+	*postgres.Postgres
+}
+
+func NewAuditRepo(pg *postgres.Postgres) *AuditRepo {
+	return &AuditRepo{pg}
+}
+
+// Insert добавляет запись в журнал. Вызывается в той же транзакции, что и само изменение.
+func (r *AuditRepo) Insert(ctx context.Context, entry entity.AuditEntry) error {
+	sql, args, _ := r.Builder.
+		Insert("audit_log").
+		Columns("tenant_id, song_id, action, actor, request_id, before, after").
+		Values(tenant.Id(ctx), entry.SongId, string(entry.Action), entry.Actor, nullString(entry.RequestId), nullJSON(entry.Before), nullJSON(entry.After)).
+		ToSql()
+
+	_, err := r.GetQueryRunner(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("AuditRepo.Insert - Exec: %w", err)
+	}
+
+	return nil
+}
+
+// InsertMany добавляет записи журнала для нескольких песен, например при импорте.
+func (r *AuditRepo) InsertMany(ctx context.Context, entries []entity.AuditEntry) error {
+	tenantId := tenant.Id(ctx)
+	rows := make([][]any, 0, len(entries))
+	for _, entry := range entries {
+		rows = append(rows, []any{tenantId, entry.SongId, string(entry.Action), entry.Actor, nullString(entry.RequestId), nullJSON(entry.Before), nullJSON(entry.After)})
+	}
+
+	err := insertRows(ctx, r.Postgres, "audit_log", []string{"tenant_id", "song_id", "action", "actor", "request_id", "before", "after"}, rows)
+	if err != nil {
+		return fmt.Errorf("AuditRepo.InsertMany - insertRows: %w", err)
+	}
+
+	return nil
+}
+
+// List возвращает записи журнала от новых к старым.
+func (r *AuditRepo) List(ctx context.Context, filter AuditFilter) ([]entity.AuditEntry, error) {
+	query := r.Builder.
+		Select(auditColumns).
+		From("audit_log").
+		Where("tenant_id = ?", tenant.Id(ctx))
+
+	if filter.SongId > 0 {
+		query = query.Where("song_id = ?", filter.SongId)
+	}
+	if len(filter.Actor) > 0 {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if len(filter.Action) > 0 {
+		query = query.Where("action = ?", string(filter.Action))
+	}
+	if filter.Since != nil {
+		query = query.Where("created_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		query = query.Where("created_at < ?", *filter.Until)
+	}
+
+	limit := filter.Limit
+	if limit > maxAuditLimit {
+		limit = maxAuditLimit
+	} else if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+
+	offset := max(filter.Offset, 0)
+
+	sql, args, _ := query.OrderBy("id DESC").Offset(uint64(offset)).Limit(uint64(limit)).ToSql()
+
+	rows, err := r.GetReadQueryRunner(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("AuditRepo.List - Query: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]entity.AuditEntry, 0)
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("AuditRepo.List - Scan: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func scanAuditEntry(row pgx.Row) (entity.AuditEntry, error) {
+	var (
+		entry     entity.AuditEntry
+		requestId *string
+	)
+	err := row.Scan(&entry.Id, &entry.SongId, &entry.Action, &entry.Actor, &requestId, &entry.Before, &entry.After, &entry.CreatedAt)
+	if err != nil {
+		return entity.AuditEntry{}, err
+	}
+	if requestId != nil {
+		entry.RequestId = *requestId
+	}
+
+	return entry, nil
+}
+
+func nullString(s string) *string {
+	if len(s) == 0 {
+		return nil
+	}
+	return &s
+}
+
+// nullJSON превращает пустой снимок в NULL, иначе pgx записал бы пустую строку в JSONB.
+func nullJSON(data []byte) any {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
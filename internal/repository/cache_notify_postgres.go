@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/spanwalla/song-library/pkg/postgres"
+)
+
+// cacheChannel — канал NOTIFY, по которому экземпляры сервиса сообщают друг другу
+// об удалённых из кэша ключах.
+const cacheChannel = "song_library_cache"
+
+// CacheNotifier рассылает удалённые из кэша ключи через LISTEN/NOTIFY. Реализует cache.Notifier.
+type CacheNotifier struct {
+	*postgres.Postgres
+}
+
+func NewCacheNotifier(pg *postgres.Postgres) *CacheNotifier {
+	return &CacheNotifier{pg}
+}
+
+// Publish отправляет ключи всем слушателям. Внутри транзакции уведомление уходит при фиксации.
+func (n *CacheNotifier) Publish(ctx context.Context, keys []string) error {
+	payload, err := json.Marshal(keys)
+	if err != nil {
+		return fmt.Errorf("CacheNotifier.Publish - json.Marshal: %w", err)
+	}
+
+	_, err = n.GetQueryRunner(ctx).Exec(ctx, "SELECT pg_notify($1, $2)", cacheChannel, string(payload))
+	if err != nil {
+		return fmt.Errorf("CacheNotifier.Publish - Exec: %w", err)
+	}
+
+	return nil
+}
+
+// Listen открывает отдельное соединение на всё время работы и передаёт в handle ключи
+// из каждого уведомления. Соединение не берётся из пула, чтобы подписка не уменьшала
+// число соединений, доступных запросам.
+func (n *CacheNotifier) Listen(ctx context.Context, handle func(keys []string)) error {
+	conn, err := pgx.ConnectConfig(ctx, n.Pool.Config().ConnConfig)
+	if err != nil {
+		return fmt.Errorf("CacheNotifier.Listen - pgx.ConnectConfig: %w", err)
+	}
+	defer func() {
+		_ = conn.Close(context.WithoutCancel(ctx))
+	}()
+
+	if _, err = conn.Exec(ctx, "LISTEN "+cacheChannel); err != nil {
+		return fmt.Errorf("CacheNotifier.Listen - Exec: %w", err)
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("CacheNotifier.Listen - WaitForNotification: %w", err)
+		}
+
+		var keys []string
+		if err = json.Unmarshal([]byte(notification.Payload), &keys); err != nil {
+			continue
+		}
+		handle(keys)
+	}
+}
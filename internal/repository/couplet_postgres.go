@@ -4,9 +4,9 @@ import (
 	"context"
 	"fmt"
 
-	log "github.com/sirupsen/logrus"
-
 	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/tenant"
+	"github.com/spanwalla/song-library/pkg/logger"
 	"github.com/spanwalla/song-library/pkg/postgres"
 )
 
@@ -18,21 +18,19 @@ func NewCoupletRepo(pg *postgres.Postgres) *CoupletRepo {
 	return &CoupletRepo{pg}
 }
 
+// Insert добавляет куплеты. Большие тексты и импорт нескольких песен записываются через COPY.
 func (r *CoupletRepo) Insert(ctx context.Context, couplets []entity.Couplet) error {
-	query := r.Builder.
-		Insert("couplets").
-		Columns("song_id", "sequence_number", "couplet_text")
-
+	tenantId := tenant.Id(ctx)
+	rows := make([][]any, 0, len(couplets))
 	for _, couplet := range couplets {
-		query = query.Values(couplet.SongId, couplet.SequenceNumber, couplet.Text)
+		rows = append(rows, []any{tenantId, couplet.SongId, couplet.SequenceNumber, couplet.Text})
 	}
 
-	sql, args, _ := query.ToSql()
-	log.Debugf("SongRepo.Insert - ToSql: %s", sql)
+	logger.From(ctx).Debugf("CoupletRepo.Insert - %d couplets", len(rows))
 
-	_, err := r.GetQueryRunner(ctx).Exec(ctx, sql, args...)
+	err := insertRows(ctx, r.Postgres, "couplets", []string{"tenant_id", "song_id", "sequence_number", "couplet_text"}, rows)
 	if err != nil {
-		return fmt.Errorf("SongRepo.Insert - Exec: %w", err)
+		return fmt.Errorf("CoupletRepo.Insert - insertRows: %w", err)
 	}
 
 	return nil
@@ -52,13 +50,13 @@ func (r *CoupletRepo) GetBySongId(ctx context.Context, songId, offset, limit int
 	sql, args, _ := r.Builder.
 		Select("song_id, sequence_number, couplet_text").
 		From("couplets").
-		Where("song_id = ?", songId).
+		Where("song_id = ? AND tenant_id = ?", songId, tenant.Id(ctx)).
 		OrderBy("sequence_number").
 		Offset(uint64(offset)).
 		Limit(uint64(limit)).
 		ToSql()
 
-	cmdTag, err := r.GetQueryRunner(ctx).Query(ctx, sql, args...)
+	cmdTag, err := r.GetReadQueryRunner(ctx).Query(ctx, sql, args...)
 	if err != nil {
 		return nil, fmt.Errorf("CoupletRepo.GetBySongId - Query: %w", err)
 	}
@@ -77,11 +75,81 @@ func (r *CoupletRepo) GetBySongId(ctx context.Context, songId, offset, limit int
 	return couplets, nil
 }
 
+func (r *CoupletRepo) GetAllBySongId(ctx context.Context, songId int) ([]entity.Couplet, error) {
+	sql, args, _ := r.Builder.
+		Select("song_id, sequence_number, couplet_text").
+		From("couplets").
+		Where("song_id = ? AND tenant_id = ?", songId, tenant.Id(ctx)).
+		OrderBy("sequence_number").
+		ToSql()
+
+	cmdTag, err := r.GetReadQueryRunner(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("CoupletRepo.GetAllBySongId - Query: %w", err)
+	}
+	defer cmdTag.Close()
+
+	couplets := make([]entity.Couplet, 0)
+	for cmdTag.Next() {
+		var couplet entity.Couplet
+		err = cmdTag.Scan(&couplet.SongId, &couplet.SequenceNumber, &couplet.Text)
+		if err != nil {
+			return nil, fmt.Errorf("CoupletRepo.GetAllBySongId - Scan: %w", err)
+		}
+		couplets = append(couplets, couplet)
+	}
+
+	return couplets, nil
+}
+
+// GetPageBySongIds возвращает одну и ту же страницу куплетов сразу для нескольких песен одним запросом.
+func (r *CoupletRepo) GetPageBySongIds(ctx context.Context, songIds []int, offset, limit int) ([]entity.Couplet, error) {
+	if limit > maxPaginationLimit {
+		limit = maxPaginationLimit
+	} else if limit <= 0 {
+		limit = defaultPaginationLimit
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	numbered := r.Builder.
+		Select("song_id, sequence_number, couplet_text, ROW_NUMBER() OVER (PARTITION BY song_id ORDER BY sequence_number) AS rn").
+		From("couplets").
+		Where("song_id = ANY(?) AND tenant_id = ?", songIds, tenant.Id(ctx))
+
+	sql, args, _ := r.Builder.
+		Select("song_id, sequence_number, couplet_text").
+		FromSelect(numbered, "numbered").
+		Where("rn > ? AND rn <= ?", offset, offset+limit).
+		OrderBy("song_id", "sequence_number").
+		ToSql()
+
+	cmdTag, err := r.GetReadQueryRunner(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("CoupletRepo.GetPageBySongIds - Query: %w", err)
+	}
+	defer cmdTag.Close()
+
+	couplets := make([]entity.Couplet, 0)
+	for cmdTag.Next() {
+		var couplet entity.Couplet
+		err = cmdTag.Scan(&couplet.SongId, &couplet.SequenceNumber, &couplet.Text)
+		if err != nil {
+			return nil, fmt.Errorf("CoupletRepo.GetPageBySongIds - Scan: %w", err)
+		}
+		couplets = append(couplets, couplet)
+	}
+
+	return couplets, nil
+}
+
 func (r *CoupletRepo) GetAvailableSequenceNumber(ctx context.Context, songId int) (int, error) {
 	sql, args, _ := r.Builder.
 		Select("COALESCE(MAX(sequence_number), 0) + 1").
 		From("couplets").
-		Where("song_id = ?", songId).
+		Where("song_id = ? AND tenant_id = ?", songId, tenant.Id(ctx)).
 		ToSql()
 
 	var sequenceNumber int
@@ -97,11 +165,11 @@ func (r *CoupletRepo) GetCoupletsCount(ctx context.Context, songId int) (int, er
 	sql, args, _ := r.Builder.
 		Select("COUNT(*)").
 		From("couplets").
-		Where("song_id = ?", songId).
+		Where("song_id = ? AND tenant_id = ?", songId, tenant.Id(ctx)).
 		ToSql()
 
 	var count int
-	err := r.GetQueryRunner(ctx).QueryRow(ctx, sql, args...).Scan(&count)
+	err := r.GetReadQueryRunner(ctx).QueryRow(ctx, sql, args...).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("CoupletRepo.GetCoupletsCount - QueryRow: %w", err)
 	}
@@ -109,10 +177,37 @@ func (r *CoupletRepo) GetCoupletsCount(ctx context.Context, songId int) (int, er
 	return count, nil
 }
 
+func (r *CoupletRepo) GetCoupletsCounts(ctx context.Context, songIds []int) (map[int]int, error) {
+	sql, args, _ := r.Builder.
+		Select("song_id, COUNT(*)").
+		From("couplets").
+		Where("song_id = ANY(?) AND tenant_id = ?", songIds, tenant.Id(ctx)).
+		GroupBy("song_id").
+		ToSql()
+
+	cmdTag, err := r.GetReadQueryRunner(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("CoupletRepo.GetCoupletsCounts - Query: %w", err)
+	}
+	defer cmdTag.Close()
+
+	counts := make(map[int]int, len(songIds))
+	for cmdTag.Next() {
+		var songId, count int
+		err = cmdTag.Scan(&songId, &count)
+		if err != nil {
+			return nil, fmt.Errorf("CoupletRepo.GetCoupletsCounts - Scan: %w", err)
+		}
+		counts[songId] = count
+	}
+
+	return counts, nil
+}
+
 func (r *CoupletRepo) DeleteBySongId(ctx context.Context, songId int) error {
 	sql, args, _ := r.Builder.
 		Delete("couplets").
-		Where("song_id = ?", songId).
+		Where("song_id = ? AND tenant_id = ?", songId, tenant.Id(ctx)).
 		ToSql()
 
 	_, err := r.GetQueryRunner(ctx).Exec(ctx, sql, args...)
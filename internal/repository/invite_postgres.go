@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/tenant"
+	"github.com/spanwalla/song-library/pkg/postgres"
+)
+
+const inviteColumns = "id, tenant_id, code_hash, created_at, expires_at, used_at"
+
+type InviteRepo struct {
+	*postgres.Postgres
+}
+
+func NewInviteRepo(pg *postgres.Postgres) *InviteRepo {
+	return &InviteRepo{pg}
+}
+
+// Insert добавляет приглашение в библиотеку арендатора запроса.
+func (r *InviteRepo) Insert(ctx context.Context, invite entity.Invite) (entity.Invite, error) {
+	sql, args, _ := r.Builder.
+		Insert("invites").
+		Columns("tenant_id, code_hash, expires_at").
+		Values(tenant.Id(ctx), invite.Hash, invite.ExpiresAt).
+		Suffix("RETURNING " + inviteColumns).
+		ToSql()
+
+	invite, err := scanInvite(r.GetQueryRunner(ctx).QueryRow(ctx, sql, args...))
+	if err != nil {
+		return entity.Invite{}, fmt.Errorf("InviteRepo.Insert - QueryRow: %w", err)
+	}
+
+	return invite, nil
+}
+
+// Consume отмечает использованным действующее приглашение арендатора запроса с заданным
+// хэшем кода. Одним запросом, чтобы одно приглашение нельзя было использовать дважды параллельно.
+func (r *InviteRepo) Consume(ctx context.Context, hash string) (entity.Invite, error) {
+	sql, args, _ := r.Builder.
+		Update("invites").
+		Set("used_at", squirrel.Expr("now()")).
+		Where("code_hash = ? AND tenant_id = ? AND used_at IS NULL AND expires_at > now()", hash, tenant.Id(ctx)).
+		Suffix("RETURNING " + inviteColumns).
+		ToSql()
+
+	invite, err := scanInvite(r.GetQueryRunner(ctx).QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.Invite{}, ErrNotFound
+		}
+		return entity.Invite{}, fmt.Errorf("InviteRepo.Consume - QueryRow: %w", err)
+	}
+
+	return invite, nil
+}
+
+func scanInvite(row pgx.Row) (entity.Invite, error) {
+	var invite entity.Invite
+	err := row.Scan(&invite.Id, &invite.TenantId, &invite.Hash, &invite.CreatedAt, &invite.ExpiresAt, &invite.UsedAt)
+	return invite, err
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/tenant"
+	"github.com/spanwalla/song-library/pkg/postgres"
+)
+
+type LineTimingRepo struct {
+	*postgres.Postgres
+}
+
+func NewLineTimingRepo(pg *postgres.Postgres) *LineTimingRepo {
+	return &LineTimingRepo{pg}
+}
+
+func (r *LineTimingRepo) Insert(ctx context.Context, timings []entity.LineTiming) error {
+	if len(timings) == 0 {
+		return nil
+	}
+
+	query := r.Builder.
+		Insert("line_timings").
+		Columns("song_id", "sequence_number", "line_number", "start_ms", "end_ms")
+
+	for _, timing := range timings {
+		query = query.Values(timing.SongId, timing.SequenceNumber, timing.LineNumber, timing.StartMs, timing.EndMs)
+	}
+
+	sql, args, _ := query.ToSql()
+
+	_, err := r.GetQueryRunner(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("LineTimingRepo.Insert - Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (r *LineTimingRepo) GetBySongId(ctx context.Context, songId int) ([]entity.LineTiming, error) {
+	sql, args, _ := r.Builder.
+		Select("song_id, sequence_number, line_number, start_ms, end_ms").
+		From("line_timings").
+		Where("song_id = ?", songId).
+		Where(inTenantSongs(ctx)).
+		OrderBy("sequence_number", "line_number").
+		ToSql()
+
+	cmdTag, err := r.GetReadQueryRunner(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("LineTimingRepo.GetBySongId - Query: %w", err)
+	}
+	defer cmdTag.Close()
+
+	timings := make([]entity.LineTiming, 0)
+	for cmdTag.Next() {
+		var timing entity.LineTiming
+		err = cmdTag.Scan(&timing.SongId, &timing.SequenceNumber, &timing.LineNumber, &timing.StartMs, &timing.EndMs)
+		if err != nil {
+			return nil, fmt.Errorf("LineTimingRepo.GetBySongId - Scan: %w", err)
+		}
+		timings = append(timings, timing)
+	}
+
+	return timings, nil
+}
+
+// GetActiveLine возвращает последнюю строку, которая началась не позже positionMs.
+// Если строка уже закончилась, позиция приходится на проигрыш и строки нет.
+func (r *LineTimingRepo) GetActiveLine(ctx context.Context, songId, positionMs int) (entity.SyncedLine, error) {
+	sql, args, _ := r.Builder.
+		Select("lt.sequence_number, lt.line_number, lt.start_ms, lt.end_ms, split_part(c.couplet_text, E'\\n', lt.line_number)").
+		From("line_timings lt").
+		Join("couplets c ON c.song_id = lt.song_id AND c.sequence_number = lt.sequence_number").
+		Where("lt.song_id = ? AND c.tenant_id = ?", songId, tenant.Id(ctx)).
+		Where("lt.start_ms <= ?", positionMs).
+		OrderBy("lt.start_ms DESC", "lt.sequence_number DESC", "lt.line_number DESC").
+		Limit(1).
+		ToSql()
+
+	var (
+		line  entity.SyncedLine
+		endMs *int
+	)
+	err := r.GetReadQueryRunner(ctx).QueryRow(ctx, sql, args...).Scan(
+		&line.SequenceNumber,
+		&line.LineNumber,
+		&line.StartMs,
+		&endMs,
+		&line.Text,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.SyncedLine{}, ErrNotFound
+		}
+		return entity.SyncedLine{}, fmt.Errorf("LineTimingRepo.GetActiveLine - QueryRow: %w", err)
+	}
+	if endMs != nil && *endMs <= positionMs {
+		return entity.SyncedLine{}, ErrNotFound
+	}
+
+	return line, nil
+}
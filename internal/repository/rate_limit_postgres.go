@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spanwalla/song-library/pkg/postgres"
+)
+
+// RateLimitRepo хранит счётчики запросов, общие для всех экземпляров сервиса.
+// Реализует ratelimit.Store.
+type RateLimitRepo struct {
+	*postgres.Postgres
+}
+
+func NewRateLimitRepo(pg *postgres.Postgres) *RateLimitRepo {
+	return &RateLimitRepo{pg}
+}
+
+// Increment не направляет чтение запроса на основной сервер: счётчик запрос не читает.
+func (r *RateLimitRepo) Increment(ctx context.Context, key string, window, expiresAt time.Time) (int, error) {
+	ctx = postgres.SkipReadYourWrites(ctx)
+
+	sql, args, _ := r.Builder.
+		Insert("rate_limits").
+		Columns("key, window_start, hits, expires_at").
+		Values(key, window, 1, expiresAt).
+		Suffix("ON CONFLICT (key, window_start) DO UPDATE SET hits = rate_limits.hits + 1 RETURNING hits").
+		ToSql()
+
+	var hits int
+	err := r.GetQueryRunner(ctx).QueryRow(ctx, sql, args...).Scan(&hits)
+	if err != nil {
+		return 0, fmt.Errorf("RateLimitRepo.Increment - QueryRow: %w", err)
+	}
+
+	return hits, nil
+}
+
+func (r *RateLimitRepo) DeleteExpired(ctx context.Context, now time.Time) error {
+	sql, args, _ := r.Builder.
+		Delete("rate_limits").
+		Where("expires_at <= ?", now).
+		ToSql()
+
+	_, err := r.GetQueryRunner(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("RateLimitRepo.DeleteExpired - Exec: %w", err)
+	}
+
+	return nil
+}
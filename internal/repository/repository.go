@@ -2,9 +2,14 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+
 	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/tenant"
 	"github.com/spanwalla/song-library/pkg/postgres"
 )
 
@@ -13,11 +18,61 @@ import (
 const (
 	maxPaginationLimit     = 10
 	defaultPaginationLimit = 5
+
+	maxAuditLimit     = 100
+	defaultAuditLimit = 20
+
+	// copyThreshold — число строк, начиная с которого insertRows использует COPY. На малых
+	// наборах COPY медленнее INSERT: pgx сначала отдельным запросом узнаёт типы столбцов.
+	// Значение сверяется с BenchmarkInsertRows.
+	copyThreshold = 100
 )
 
-// Transactor определяет интерфейс для работы с транзакциями.
+// Transactor определяет интерфейс для работы с транзакциями. Вложенный вызов выполняется
+// в транзакции внешнего через точку сохранения, конфликты сериализации повторяются.
 type Transactor interface {
-	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error, opts ...postgres.TxOption) error
+}
+
+// inTenantSongs ограничивает выборку из таблиц, которые ссылаются на песню, но не хранят
+// арендатора сами, песнями арендатора запроса.
+func inTenantSongs(ctx context.Context) squirrel.Sqlizer {
+	return squirrel.Expr("song_id IN (SELECT id FROM songs WHERE tenant_id = ?)", tenant.Id(ctx))
+}
+
+// insertRows вставляет строки одним INSERT, а большие наборы — через COPY. INSERT ограничен
+// 65535 параметрами запроса, COPY передаёт строки потоком без ограничения. Оба способа
+// работают внутри транзакции из контекста.
+func insertRows(ctx context.Context, pg *postgres.Postgres, table string, columns []string, rows [][]any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	if len(rows) >= copyThreshold {
+		return copyRows(ctx, pg, table, columns, rows)
+	}
+	return insertValues(ctx, pg, table, columns, rows)
+}
+
+func copyRows(ctx context.Context, pg *postgres.Postgres, table string, columns []string, rows [][]any) error {
+	_, err := pg.GetQueryRunner(ctx).CopyFrom(ctx, pgx.Identifier{table}, columns, pgx.CopyFromRows(rows))
+	if err != nil {
+		return fmt.Errorf("CopyFrom: %w", err)
+	}
+	return nil
+}
+
+func insertValues(ctx context.Context, pg *postgres.Postgres, table string, columns []string, rows [][]any) error {
+	query := pg.Builder.Insert(table).Columns(columns...)
+	for _, row := range rows {
+		query = query.Values(row...)
+	}
+
+	sql, args, _ := query.ToSql()
+	_, err := pg.GetQueryRunner(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("Exec: %w", err)
+	}
+	return nil
 }
 
 type UpdateSongInput struct {
@@ -29,7 +84,9 @@ type UpdateSongInput struct {
 
 type Song interface {
 	Insert(ctx context.Context, song entity.Song) (int, error)
+	InsertMany(ctx context.Context, songs []entity.Song) ([]int, error)
 	GetById(ctx context.Context, songId int) (entity.Song, error)
+	GetByIdForUpdate(ctx context.Context, songId int) (entity.Song, error)
 	Search(ctx context.Context, filters map[string]string, orderBy [][]string, offset, limit int) ([]entity.Song, error)
 	UpdateById(ctx context.Context, songId int, input UpdateSongInput) error
 	DeleteById(ctx context.Context, songId int) error
@@ -38,19 +95,104 @@ type Song interface {
 type Couplet interface {
 	Insert(ctx context.Context, couplets []entity.Couplet) error
 	GetBySongId(ctx context.Context, songId, offset, limit int) ([]entity.Couplet, error)
+	GetAllBySongId(ctx context.Context, songId int) ([]entity.Couplet, error)
+	GetPageBySongIds(ctx context.Context, songIds []int, offset, limit int) ([]entity.Couplet, error)
 	GetAvailableSequenceNumber(ctx context.Context, songId int) (int, error)
 	GetCoupletsCount(ctx context.Context, songId int) (int, error)
+	GetCoupletsCounts(ctx context.Context, songIds []int) (map[int]int, error)
 	DeleteBySongId(ctx context.Context, songId int) error
 }
 
+type LineTiming interface {
+	Insert(ctx context.Context, timings []entity.LineTiming) error
+	GetBySongId(ctx context.Context, songId int) ([]entity.LineTiming, error)
+	GetActiveLine(ctx context.Context, songId, positionMs int) (entity.SyncedLine, error)
+}
+
+type Translation interface {
+	Insert(ctx context.Context, translations []entity.Translation) error
+	GetBySongId(ctx context.Context, songId int, lang string, offset, limit int) ([]entity.Translation, error)
+	GetCount(ctx context.Context, songId int, lang string) (int, error)
+	GetLanguages(ctx context.Context, songId int) ([]entity.TranslationInfo, error)
+	DeleteBySongId(ctx context.Context, songId int, lang string) error
+	DeleteAllBySongId(ctx context.Context, songId int) error
+}
+
+type APIKey interface {
+	Insert(ctx context.Context, key entity.APIKey) (entity.APIKey, error)
+	GetByHash(ctx context.Context, hash string) (entity.APIKey, error)
+	List(ctx context.Context) ([]entity.APIKey, error)
+	Revoke(ctx context.Context, id int) error
+}
+
+type User interface {
+	Insert(ctx context.Context, user entity.User) (entity.User, error)
+	GetById(ctx context.Context, userId int) (entity.User, error)
+	GetByUsername(ctx context.Context, username string) (entity.User, error)
+	List(ctx context.Context) ([]entity.User, error)
+	SetRole(ctx context.Context, userId int, role entity.Role) (entity.User, error)
+}
+
+type Invite interface {
+	Insert(ctx context.Context, invite entity.Invite) (entity.Invite, error)
+	Consume(ctx context.Context, hash string) (entity.Invite, error)
+}
+
+type Session interface {
+	Insert(ctx context.Context, session entity.Session) (int, error)
+	GetByHash(ctx context.Context, hash string) (entity.Session, error)
+	Consume(ctx context.Context, hash string) (entity.Session, error)
+	RevokeByUserId(ctx context.Context, userId int) error
+}
+
+// AuditFilter задаёт условия выборки из журнала аудита. Нулевые значения не ограничивают выборку.
+type AuditFilter struct {
+	SongId int
+	Actor  string
+	Action entity.AuditAction
+	Since  *time.Time
+	Until  *time.Time
+	Offset int
+	Limit  int
+}
+
+type Audit interface {
+	Insert(ctx context.Context, entry entity.AuditEntry) error
+	InsertMany(ctx context.Context, entries []entity.AuditEntry) error
+	List(ctx context.Context, filter AuditFilter) ([]entity.AuditEntry, error)
+}
+
+type Tenant interface {
+	Insert(ctx context.Context, tenant entity.Tenant) (entity.Tenant, error)
+	GetById(ctx context.Context, tenantId int) (entity.Tenant, error)
+	GetBySlug(ctx context.Context, slug string) (entity.Tenant, error)
+	List(ctx context.Context) ([]entity.Tenant, error)
+}
+
 type Repositories struct {
 	Song
 	Couplet
+	LineTiming
+	Translation
+	APIKey
+	User
+	Invite
+	Session
+	Audit
+	Tenant
 }
 
 func NewRepositories(pg *postgres.Postgres) *Repositories {
 	return &Repositories{
-		Song:    NewSongRepo(pg),
-		Couplet: NewCoupletRepo(pg),
+		Song:        NewSongRepo(pg),
+		Couplet:     NewCoupletRepo(pg),
+		LineTiming:  NewLineTimingRepo(pg),
+		Translation: NewTranslationRepo(pg),
+		APIKey:      NewAPIKeyRepo(pg),
+		User:        NewUserRepo(pg),
+		Invite:      NewInviteRepo(pg),
+		Session:     NewSessionRepo(pg),
+		Audit:       NewAuditRepo(pg),
+		Tenant:      NewTenantRepo(pg),
 	}
 }
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/spanwalla/song-library/pkg/postgres"
+)
+
+const benchTable = "bench_insert_rows"
+
+var benchColumns = []string{"song_id", "couplet_num", "text"}
+
+// benchPostgres подключается к базе из PG_URL и создаёт таблицу той же формы, что couplets.
+// Без PG_URL бенчмарк пропускается.
+func benchPostgres(b *testing.B) *postgres.Postgres {
+	b.Helper()
+
+	url := os.Getenv("PG_URL")
+	if len(url) == 0 {
+		b.Skip("PG_URL is not set")
+	}
+
+	pg, err := postgres.New(url, postgres.ConnAttempts(1))
+	require.NoError(b, err)
+	b.Cleanup(pg.Close)
+
+	ctx := context.Background()
+	_, err = pg.Pool.Exec(ctx, "CREATE TABLE IF NOT EXISTS "+benchTable+" (song_id INT NOT NULL, couplet_num INT NOT NULL, text TEXT NOT NULL)")
+	require.NoError(b, err)
+	b.Cleanup(func() {
+		_, _ = pg.Pool.Exec(context.Background(), "DROP TABLE IF EXISTS "+benchTable)
+	})
+
+	return pg
+}
+
+func benchRows(n int) [][]any {
+	text := strings.Repeat("la ", 60)
+	rows := make([][]any, n)
+	for i := range rows {
+		rows[i] = []any{1, i + 1, text}
+	}
+	return rows
+}
+
+type insertFunc func(ctx context.Context, pg *postgres.Postgres, table string, columns []string, rows [][]any) error
+
+// BenchmarkInsertRows сравнивает INSERT с несколькими VALUES и COPY на пакетах разного размера,
+// по результатам выбирается copyThreshold. Запуск: make bench.
+func BenchmarkInsertRows(b *testing.B) {
+	pg := benchPostgres(b)
+	ctx := context.Background()
+
+	methods := []struct {
+		name   string
+		insert insertFunc
+	}{
+		{"insert", insertValues},
+		{"copy", copyRows},
+	}
+
+	for _, size := range []int{10, 100, 1_000, 10_000} {
+		rows := benchRows(size)
+		for _, method := range methods {
+			b.Run(fmt.Sprintf("%s/%d", method.name, size), func(b *testing.B) {
+				for b.Loop() {
+					require.NoError(b, method.insert(ctx, pg, benchTable, benchColumns, rows))
+
+					b.StopTimer()
+					_, err := pg.Pool.Exec(ctx, "TRUNCATE "+benchTable)
+					require.NoError(b, err)
+					b.StartTimer()
+				}
+				b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*size), "ns/row")
+			})
+		}
+	}
+}
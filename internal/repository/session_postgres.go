@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/pkg/postgres"
+)
+
+type SessionRepo struct {
+	*postgres.Postgres
+}
+
+func NewSessionRepo(pg *postgres.Postgres) *SessionRepo {
+	return &SessionRepo{pg}
+}
+
+func (r *SessionRepo) Insert(ctx context.Context, session entity.Session) (int, error) {
+	sql, args, _ := r.Builder.
+		Insert("sessions").
+		Columns("user_id, token_hash, expires_at").
+		Values(session.UserId, session.TokenHash, session.ExpiresAt).
+		Suffix("RETURNING id").
+		ToSql()
+
+	var id int
+	err := r.GetQueryRunner(ctx).QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("SessionRepo.Insert - QueryRow: %w", err)
+	}
+
+	return id, nil
+}
+
+func (r *SessionRepo) GetByHash(ctx context.Context, hash string) (entity.Session, error) {
+	sql, args, _ := r.Builder.
+		Select("id, user_id, token_hash, created_at, expires_at, revoked_at").
+		From("sessions").
+		Where("token_hash = ?", hash).
+		ToSql()
+
+	var session entity.Session
+	err := r.GetQueryRunner(ctx).QueryRow(ctx, sql, args...).Scan(
+		&session.Id,
+		&session.UserId,
+		&session.TokenHash,
+		&session.CreatedAt,
+		&session.ExpiresAt,
+		&session.RevokedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.Session{}, ErrNotFound
+		}
+		return entity.Session{}, fmt.Errorf("SessionRepo.GetByHash - QueryRow: %w", err)
+	}
+
+	return session, nil
+}
+
+// Consume отзывает действующую сессию с заданным хэшем токена и возвращает её.
+// Одним запросом, чтобы один и тот же токен нельзя было использовать дважды параллельно.
+func (r *SessionRepo) Consume(ctx context.Context, hash string) (entity.Session, error) {
+	sql, args, _ := r.Builder.
+		Update("sessions").
+		Set("revoked_at", squirrel.Expr("now()")).
+		Where("token_hash = ? AND revoked_at IS NULL AND expires_at > now()", hash).
+		Suffix("RETURNING id, user_id, token_hash, created_at, expires_at, revoked_at").
+		ToSql()
+
+	var session entity.Session
+	err := r.GetQueryRunner(ctx).QueryRow(ctx, sql, args...).Scan(
+		&session.Id,
+		&session.UserId,
+		&session.TokenHash,
+		&session.CreatedAt,
+		&session.ExpiresAt,
+		&session.RevokedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.Session{}, ErrNotFound
+		}
+		return entity.Session{}, fmt.Errorf("SessionRepo.Consume - QueryRow: %w", err)
+	}
+
+	return session, nil
+}
+
+// RevokeByUserId отзывает все действующие сессии пользователя.
+func (r *SessionRepo) RevokeByUserId(ctx context.Context, userId int) error {
+	sql, args, _ := r.Builder.
+		Update("sessions").
+		Set("revoked_at", squirrel.Expr("now()")).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		ToSql()
+
+	_, err := r.GetQueryRunner(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("SessionRepo.RevokeByUserId - Exec: %w", err)
+	}
+
+	return nil
+}
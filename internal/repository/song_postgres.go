@@ -8,9 +8,10 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/tenant"
+	"github.com/spanwalla/song-library/pkg/logger"
 	"github.com/spanwalla/song-library/pkg/postgres"
 )
 
@@ -25,8 +26,8 @@ func NewSongRepo(pg *postgres.Postgres) *SongRepo {
 func (r *SongRepo) Insert(ctx context.Context, song entity.Song) (int, error) {
 	sql, args, _ := r.Builder.
 		Insert("songs").
-		Columns("song_name, group_name, link, release_date").
-		Values(song.Name, song.Group, song.Link, song.ReleaseDate).
+		Columns("tenant_id, song_name, group_name, link, release_date").
+		Values(tenant.Id(ctx), song.Name, song.Group, song.Link, song.ReleaseDate).
 		Suffix("RETURNING id").
 		ToSql()
 
@@ -45,21 +46,64 @@ func (r *SongRepo) Insert(ctx context.Context, song entity.Song) (int, error) {
 	return id, nil
 }
 
+// InsertMany добавляет песни и возвращает их идентификаторы в порядке songs. Идентификаторы
+// выделяются из последовательности заранее, потому что COPY не умеет возвращать строки.
+func (r *SongRepo) InsertMany(ctx context.Context, songs []entity.Song) ([]int, error) {
+	if len(songs) == 0 {
+		return []int{}, nil
+	}
+
+	ids, err := r.nextIds(ctx, len(songs))
+	if err != nil {
+		return nil, fmt.Errorf("SongRepo.InsertMany - r.nextIds: %w", err)
+	}
+
+	tenantId := tenant.Id(ctx)
+	rows := make([][]any, 0, len(songs))
+	for i, song := range songs {
+		rows = append(rows, []any{ids[i], tenantId, song.Name, song.Group, song.Link, song.ReleaseDate})
+	}
+
+	err = insertRows(ctx, r.Postgres, "songs", []string{"id", "tenant_id", "song_name", "group_name", "link", "release_date"}, rows)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrAlreadyExists
+		}
+		return nil, fmt.Errorf("SongRepo.InsertMany - insertRows: %w", err)
+	}
+
+	return ids, nil
+}
+
+// nextIds выделяет count идентификаторов песен одним запросом.
+func (r *SongRepo) nextIds(ctx context.Context, count int) ([]int, error) {
+	cmdTag, err := r.GetQueryRunner(ctx).Query(ctx, "SELECT nextval(pg_get_serial_sequence('songs', 'id')) FROM generate_series(1, $1)", count)
+	if err != nil {
+		return nil, fmt.Errorf("Query: %w", err)
+	}
+	defer cmdTag.Close()
+
+	ids := make([]int, 0, count)
+	for cmdTag.Next() {
+		var id int
+		if err = cmdTag.Scan(&id); err != nil {
+			return nil, fmt.Errorf("Scan: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, cmdTag.Err()
+}
+
 func (r *SongRepo) GetById(ctx context.Context, songId int) (entity.Song, error) {
 	sql, args, _ := r.Builder.
 		Select("id, song_name, group_name, link, release_date").
 		From("songs").
-		Where("id = ?", songId).
+		Where("id = ? AND tenant_id = ?", songId, tenant.Id(ctx)).
 		ToSql()
 
-	var song entity.Song
-	err := r.GetQueryRunner(ctx).QueryRow(ctx, sql, args...).Scan(
-		&song.Id,
-		&song.Name,
-		&song.Group,
-		&song.Link,
-		&song.ReleaseDate,
-	)
+	song, err := scanSong(r.GetReadQueryRunner(ctx).QueryRow(ctx, sql, args...))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return entity.Song{}, ErrNotFound
@@ -70,6 +114,33 @@ func (r *SongRepo) GetById(ctx context.Context, songId int) (entity.Song, error)
 	return song, nil
 }
 
+// GetByIdForUpdate читает песню и блокирует строку до конца транзакции,
+// чтобы параллельное изменение не вклинилось между чтением и записью.
+func (r *SongRepo) GetByIdForUpdate(ctx context.Context, songId int) (entity.Song, error) {
+	sql, args, _ := r.Builder.
+		Select("id, song_name, group_name, link, release_date").
+		From("songs").
+		Where("id = ? AND tenant_id = ?", songId, tenant.Id(ctx)).
+		Suffix("FOR UPDATE").
+		ToSql()
+
+	song, err := scanSong(r.GetQueryRunner(ctx).QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.Song{}, ErrNotFound
+		}
+		return entity.Song{}, fmt.Errorf("SongRepo.GetByIdForUpdate - QueryRow: %w", err)
+	}
+
+	return song, nil
+}
+
+func scanSong(row pgx.Row) (entity.Song, error) {
+	var song entity.Song
+	err := row.Scan(&song.Id, &song.Name, &song.Group, &song.Link, &song.ReleaseDate)
+	return song, err
+}
+
 func (r *SongRepo) Search(ctx context.Context, filters map[string]string, orderBy [][]string, offset, limit int) ([]entity.Song, error) {
 	validColumnsMapping := map[string]string{
 		"id":          "id",
@@ -81,7 +152,8 @@ func (r *SongRepo) Search(ctx context.Context, filters map[string]string, orderB
 
 	query := r.Builder.
 		Select("id, group_name, song_name, link, release_date").
-		From("songs")
+		From("songs").
+		Where("tenant_id = ?", tenant.Id(ctx))
 
 	for field, value := range filters {
 		if dbColumn, ok := validColumnsMapping[field]; ok {
@@ -108,9 +180,9 @@ func (r *SongRepo) Search(ctx context.Context, filters map[string]string, orderB
 	}
 
 	sql, args, _ := query.Offset(uint64(offset)).Limit(uint64(limit)).ToSql()
-	log.Debugf("SongRepo.Search - sql: %s", sql)
+	logger.From(ctx).Debugf("SongRepo.Search - sql: %s", sql)
 
-	cmdTag, err := r.GetQueryRunner(ctx).Query(ctx, sql, args...)
+	cmdTag, err := r.GetReadQueryRunner(ctx).Query(ctx, sql, args...)
 	if err != nil {
 		return nil, fmt.Errorf("SongRepo.Search - Query: %w", err)
 	}
@@ -154,7 +226,7 @@ func (r *SongRepo) UpdateById(ctx context.Context, songId int, input UpdateSongI
 
 	sql, args, _ := r.Builder.
 		Update("songs").
-		Where("id = ?", songId).
+		Where("id = ? AND tenant_id = ?", songId, tenant.Id(ctx)).
 		SetMap(updates).
 		ToSql()
 
@@ -169,7 +241,7 @@ func (r *SongRepo) UpdateById(ctx context.Context, songId int, input UpdateSongI
 func (r *SongRepo) DeleteById(ctx context.Context, songId int) error {
 	sql, args, _ := r.Builder.
 		Delete("songs").
-		Where("id = ?", songId).
+		Where("id = ? AND tenant_id = ?", songId, tenant.Id(ctx)).
 		ToSql()
 
 	_, err := r.GetQueryRunner(ctx).Exec(ctx, sql, args...)
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/pkg/postgres"
+)
+
+const tenantColumns = "id, slug, name, created_at"
+
+type TenantRepo struct {
+	*postgres.Postgres
+}
+
+func NewTenantRepo(pg *postgres.Postgres) *TenantRepo {
+	return &TenantRepo{pg}
+}
+
+func (r *TenantRepo) Insert(ctx context.Context, t entity.Tenant) (entity.Tenant, error) {
+	sql, args, _ := r.Builder.
+		Insert("tenants").
+		Columns("slug, name").
+		Values(t.Slug, t.Name).
+		Suffix("RETURNING " + tenantColumns).
+		ToSql()
+
+	t, err := scanTenant(r.GetQueryRunner(ctx).QueryRow(ctx, sql, args...))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if ok := errors.As(err, &pgErr); ok {
+			if pgErr.Code == "23505" {
+				return entity.Tenant{}, ErrAlreadyExists
+			}
+		}
+		return entity.Tenant{}, fmt.Errorf("TenantRepo.Insert - QueryRow: %w", err)
+	}
+
+	return t, nil
+}
+
+func (r *TenantRepo) GetById(ctx context.Context, tenantId int) (entity.Tenant, error) {
+	sql, args, _ := r.Builder.
+		Select(tenantColumns).
+		From("tenants").
+		Where("id = ?", tenantId).
+		ToSql()
+
+	t, err := scanTenant(r.GetQueryRunner(ctx).QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.Tenant{}, ErrNotFound
+		}
+		return entity.Tenant{}, fmt.Errorf("TenantRepo.GetById - QueryRow: %w", err)
+	}
+
+	return t, nil
+}
+
+func (r *TenantRepo) GetBySlug(ctx context.Context, slug string) (entity.Tenant, error) {
+	sql, args, _ := r.Builder.
+		Select(tenantColumns).
+		From("tenants").
+		Where("slug = ?", slug).
+		ToSql()
+
+	t, err := scanTenant(r.GetQueryRunner(ctx).QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.Tenant{}, ErrNotFound
+		}
+		return entity.Tenant{}, fmt.Errorf("TenantRepo.GetBySlug - QueryRow: %w", err)
+	}
+
+	return t, nil
+}
+
+func (r *TenantRepo) List(ctx context.Context) ([]entity.Tenant, error) {
+	sql, args, _ := r.Builder.
+		Select(tenantColumns).
+		From("tenants").
+		OrderBy("id").
+		ToSql()
+
+	rows, err := r.GetQueryRunner(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("TenantRepo.List - Query: %w", err)
+	}
+	defer rows.Close()
+
+	tenants := make([]entity.Tenant, 0)
+	for rows.Next() {
+		t, err := scanTenant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("TenantRepo.List - Scan: %w", err)
+		}
+		tenants = append(tenants, t)
+	}
+
+	return tenants, rows.Err()
+}
+
+func scanTenant(row pgx.Row) (entity.Tenant, error) {
+	var t entity.Tenant
+	err := row.Scan(&t.Id, &t.Slug, &t.Name, &t.CreatedAt)
+	return t, err
+}
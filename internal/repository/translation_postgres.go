@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/pkg/postgres"
+)
+
+type TranslationRepo struct {
+	*postgres.Postgres
+}
+
+func NewTranslationRepo(pg *postgres.Postgres) *TranslationRepo {
+	return &TranslationRepo{pg}
+}
+
+func (r *TranslationRepo) Insert(ctx context.Context, translations []entity.Translation) error {
+	if len(translations) == 0 {
+		return nil
+	}
+
+	query := r.Builder.
+		Insert("translations").
+		Columns("song_id", "lang", "sequence_number", "couplet_text")
+
+	for _, translation := range translations {
+		query = query.Values(translation.SongId, translation.Language, translation.SequenceNumber, translation.Text)
+	}
+
+	sql, args, _ := query.ToSql()
+
+	_, err := r.GetQueryRunner(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("TranslationRepo.Insert - Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TranslationRepo) GetBySongId(ctx context.Context, songId int, lang string, offset, limit int) ([]entity.Translation, error) {
+	if limit > maxPaginationLimit {
+		limit = maxPaginationLimit
+	} else if limit <= 0 {
+		limit = defaultPaginationLimit
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	sql, args, _ := r.Builder.
+		Select("song_id, lang, sequence_number, couplet_text").
+		From("translations").
+		Where("song_id = ? AND lang = ?", songId, lang).
+		Where(inTenantSongs(ctx)).
+		OrderBy("sequence_number").
+		Offset(uint64(offset)).
+		Limit(uint64(limit)).
+		ToSql()
+
+	cmdTag, err := r.GetReadQueryRunner(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("TranslationRepo.GetBySongId - Query: %w", err)
+	}
+	defer cmdTag.Close()
+
+	translations := make([]entity.Translation, 0)
+	for cmdTag.Next() {
+		var translation entity.Translation
+		err = cmdTag.Scan(&translation.SongId, &translation.Language, &translation.SequenceNumber, &translation.Text)
+		if err != nil {
+			return nil, fmt.Errorf("TranslationRepo.GetBySongId - Scan: %w", err)
+		}
+		translations = append(translations, translation)
+	}
+
+	return translations, nil
+}
+
+func (r *TranslationRepo) GetCount(ctx context.Context, songId int, lang string) (int, error) {
+	sql, args, _ := r.Builder.
+		Select("COUNT(*)").
+		From("translations").
+		Where("song_id = ? AND lang = ?", songId, lang).
+		Where(inTenantSongs(ctx)).
+		ToSql()
+
+	var count int
+	err := r.GetReadQueryRunner(ctx).QueryRow(ctx, sql, args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("TranslationRepo.GetCount - QueryRow: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *TranslationRepo) GetLanguages(ctx context.Context, songId int) ([]entity.TranslationInfo, error) {
+	sql, args, _ := r.Builder.
+		Select("lang, COUNT(*)").
+		From("translations").
+		Where("song_id = ?", songId).
+		Where(inTenantSongs(ctx)).
+		GroupBy("lang").
+		OrderBy("lang").
+		ToSql()
+
+	cmdTag, err := r.GetReadQueryRunner(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("TranslationRepo.GetLanguages - Query: %w", err)
+	}
+	defer cmdTag.Close()
+
+	infos := make([]entity.TranslationInfo, 0)
+	for cmdTag.Next() {
+		var info entity.TranslationInfo
+		err = cmdTag.Scan(&info.Language, &info.Count)
+		if err != nil {
+			return nil, fmt.Errorf("TranslationRepo.GetLanguages - Scan: %w", err)
+		}
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+func (r *TranslationRepo) DeleteBySongId(ctx context.Context, songId int, lang string) error {
+	sql, args, _ := r.Builder.
+		Delete("translations").
+		Where("song_id = ? AND lang = ?", songId, lang).
+		Where(inTenantSongs(ctx)).
+		ToSql()
+
+	_, err := r.GetQueryRunner(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("TranslationRepo.DeleteBySongId - Exec: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAllBySongId удаляет переводы песни на все языки, например после замены её текста.
+func (r *TranslationRepo) DeleteAllBySongId(ctx context.Context, songId int) error {
+	sql, args, _ := r.Builder.
+		Delete("translations").
+		Where("song_id = ?", songId).
+		Where(inTenantSongs(ctx)).
+		ToSql()
+
+	_, err := r.GetQueryRunner(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("TranslationRepo.DeleteAllBySongId - Exec: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/tenant"
+	"github.com/spanwalla/song-library/pkg/postgres"
+)
+
+const userColumns = "id, tenant_id, username, password_hash, role, created_at"
+
+type UserRepo struct {
+	*postgres.Postgres
+}
+
+func NewUserRepo(pg *postgres.Postgres) *UserRepo {
+	return &UserRepo{pg}
+}
+
+// Insert добавляет пользователя арендатора запроса с ролью по умолчанию, которую назначает база.
+func (r *UserRepo) Insert(ctx context.Context, user entity.User) (entity.User, error) {
+	sql, args, _ := r.Builder.
+		Insert("users").
+		Columns("tenant_id, username, password_hash").
+		Values(tenant.Id(ctx), user.Username, user.PasswordHash).
+		Suffix("RETURNING " + userColumns).
+		ToSql()
+
+	user, err := scanUser(r.GetQueryRunner(ctx).QueryRow(ctx, sql, args...))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if ok := errors.As(err, &pgErr); ok {
+			if pgErr.Code == "23505" {
+				return entity.User{}, ErrAlreadyExists
+			}
+		}
+		return entity.User{}, fmt.Errorf("UserRepo.Insert - QueryRow: %w", err)
+	}
+
+	return user, nil
+}
+
+func (r *UserRepo) GetById(ctx context.Context, userId int) (entity.User, error) {
+	sql, args, _ := r.Builder.
+		Select(userColumns).
+		From("users").
+		Where("id = ?", userId).
+		ToSql()
+
+	user, err := scanUser(r.GetQueryRunner(ctx).QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.User{}, ErrNotFound
+		}
+		return entity.User{}, fmt.Errorf("UserRepo.GetById - QueryRow: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetByUsername ищет пользователя среди пользователей арендатора запроса: в разных
+// библиотеках могут быть пользователи с одинаковым именем.
+func (r *UserRepo) GetByUsername(ctx context.Context, username string) (entity.User, error) {
+	sql, args, _ := r.Builder.
+		Select(userColumns).
+		From("users").
+		Where("tenant_id = ? AND username = ?", tenant.Id(ctx), username).
+		ToSql()
+
+	user, err := scanUser(r.GetQueryRunner(ctx).QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.User{}, ErrNotFound
+		}
+		return entity.User{}, fmt.Errorf("UserRepo.GetByUsername - QueryRow: %w", err)
+	}
+
+	return user, nil
+}
+
+func (r *UserRepo) List(ctx context.Context) ([]entity.User, error) {
+	sql, args, _ := r.Builder.
+		Select(userColumns).
+		From("users").
+		Where("tenant_id = ?", tenant.Id(ctx)).
+		OrderBy("id").
+		ToSql()
+
+	rows, err := r.GetQueryRunner(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("UserRepo.List - Query: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]entity.User, 0)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("UserRepo.List - Scan: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+func (r *UserRepo) SetRole(ctx context.Context, userId int, role entity.Role) (entity.User, error) {
+	sql, args, _ := r.Builder.
+		Update("users").
+		Set("role", string(role)).
+		Where("id = ? AND tenant_id = ?", userId, tenant.Id(ctx)).
+		Suffix("RETURNING " + userColumns).
+		ToSql()
+
+	user, err := scanUser(r.GetQueryRunner(ctx).QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.User{}, ErrNotFound
+		}
+		return entity.User{}, fmt.Errorf("UserRepo.SetRole - QueryRow: %w", err)
+	}
+
+	return user, nil
+}
+
+func scanUser(row pgx.Row) (entity.User, error) {
+	var (
+		user entity.User
+		role string
+	)
+	err := row.Scan(&user.Id, &user.TenantId, &user.Username, &user.PasswordHash, &role, &user.CreatedAt)
+	if err != nil {
+		return entity.User{}, err
+	}
+	user.Role = entity.Role(role)
+
+	return user, nil
+}
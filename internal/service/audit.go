@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/repository"
+	"github.com/spanwalla/song-library/pkg/logger"
+)
+
+// songSnapshot — состояние песни, которое попадает в журнал аудита. Для изменения текста
+// сохраняется только текст, для остальных действий — поля песни, а при добавлении и удалении ещё и текст.
+type songSnapshot struct {
+	*entity.Song
+	Text []string `json:"text,omitempty"`
+}
+
+// writeAudit добавляет запись в журнал. Должна вызываться внутри транзакции изменения,
+// чтобы запись и изменение либо сохранились вместе, либо не сохранились вовсе.
+func (s *SongService) writeAudit(ctx context.Context, songId int, action entity.AuditAction, before, after *songSnapshot) error {
+	entry, err := newAuditEntry(ctx, songId, action, before, after)
+	if err != nil {
+		return err
+	}
+
+	return s.auditRepo.Insert(ctx, entry)
+}
+
+func newAuditEntry(ctx context.Context, songId int, action entity.AuditAction, before, after *songSnapshot) (entity.AuditEntry, error) {
+	entry := entity.AuditEntry{
+		SongId:    songId,
+		Action:    action,
+		Actor:     actor(ctx),
+		RequestId: logger.RequestId(ctx),
+	}
+
+	var err error
+	if before != nil {
+		if entry.Before, err = json.Marshal(before); err != nil {
+			return entity.AuditEntry{}, fmt.Errorf("json.Marshal: %w", err)
+		}
+	}
+	if after != nil {
+		if entry.After, err = json.Marshal(after); err != nil {
+			return entity.AuditEntry{}, fmt.Errorf("json.Marshal: %w", err)
+		}
+	}
+
+	return entry, nil
+}
+
+// currentText возвращает текущий текст песни для снимка.
+func (s *SongService) currentText(ctx context.Context, songId int) ([]string, error) {
+	couplets, err := s.coupletRepo.GetAllBySongId(ctx, songId)
+	if err != nil {
+		return nil, err
+	}
+
+	text := make([]string, 0, len(couplets))
+	for _, couplet := range couplets {
+		text = append(text, couplet.Text)
+	}
+
+	return text, nil
+}
+
+// lockText блокирует песню до конца транзакции и возвращает её текст до изменения.
+// Если песни нет, возвращает ErrSongNotFound.
+func (s *SongService) lockText(ctx context.Context, songId int) ([]string, error) {
+	_, err := s.songRepo.GetByIdForUpdate(ctx, songId)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrSongNotFound
+		}
+		return nil, fmt.Errorf("s.songRepo.GetByIdForUpdate: %w", err)
+	}
+
+	text, err := s.currentText(ctx, songId)
+	if err != nil {
+		return nil, fmt.Errorf("s.currentText: %w", err)
+	}
+
+	return text, nil
+}
+
+type AuditService struct {
+	auditRepo repository.Audit
+}
+
+func NewAuditService(auditRepo repository.Audit) *AuditService {
+	return &AuditService{auditRepo: auditRepo}
+}
+
+func (s *AuditService) List(ctx context.Context, input ListAuditInput) ([]entity.AuditEntry, error) {
+	entries, err := s.auditRepo.List(ctx, repository.AuditFilter{
+		SongId: input.SongId,
+		Actor:  input.Actor,
+		Action: input.Action,
+		Since:  input.Since,
+		Until:  input.Until,
+		Offset: input.Offset,
+		Limit:  input.Limit,
+	})
+	if err != nil {
+		logger.From(ctx).Errorf("AuditService.List - s.auditRepo.List: %v", err)
+		return nil, ErrCannotGetAudit
+	}
+
+	return entries, nil
+}
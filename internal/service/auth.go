@@ -0,0 +1,466 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/repository"
+	"github.com/spanwalla/song-library/internal/tenant"
+	"github.com/spanwalla/song-library/pkg/logger"
+)
+
+const (
+	// apiKeyPrefix отличает ключи библиотеки от других секретов, например, при поиске утечек.
+	apiKeyPrefix      = "sl_"
+	apiKeyIdLength    = 4
+	apiKeySecretBytes = 32
+	inviteCodeBytes   = 24
+	defaultInviteTTL  = 7 * 24 * time.Hour
+)
+
+// dummyPasswordHash сравнивается с паролем, если пользователь не найден, чтобы время ответа
+// не выдавало, существует ли имя.
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("song-library"), bcrypt.DefaultCost)
+	return hash
+})
+
+type AuthService struct {
+	apiKeyRepo  repository.APIKey
+	userRepo    repository.User
+	inviteRepo  repository.Invite
+	sessionRepo repository.Session
+	tenants     Tenant
+	transactor  repository.Transactor
+	config      *AuthConfig
+	now         func() time.Time
+}
+
+func NewAuthService(apiKeyRepo repository.APIKey, userRepo repository.User, inviteRepo repository.Invite, sessionRepo repository.Session, tenants Tenant, transactor repository.Transactor, config *AuthConfig) *AuthService {
+	return &AuthService{
+		apiKeyRepo:  apiKeyRepo,
+		userRepo:    userRepo,
+		inviteRepo:  inviteRepo,
+		sessionRepo: sessionRepo,
+		tenants:     tenants,
+		transactor:  transactor,
+		config:      config,
+		now:         time.Now,
+	}
+}
+
+// IssueKey создаёт ключ и возвращает его вместе с секретом. В базе хранится только хэш,
+// поэтому секрет показывается один раз.
+func (s *AuthService) IssueKey(ctx context.Context, input IssueKeyInput) (IssuedKey, error) {
+	if len(input.Scopes) == 0 {
+		return IssuedKey{}, ErrInvalidScope
+	}
+	for _, scope := range input.Scopes {
+		if !scope.Valid() {
+			return IssuedKey{}, ErrInvalidScope
+		}
+	}
+
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(s.now()) {
+		return IssuedKey{}, ErrInvalidExpiry
+	}
+
+	prefix, secret, err := generateAPIKey()
+	if err != nil {
+		logger.From(ctx).Errorf("AuthService.IssueKey - generateAPIKey: %v", err)
+		return IssuedKey{}, ErrCannotIssueKey
+	}
+
+	key := entity.APIKey{
+		Name:      input.Name,
+		Prefix:    prefix,
+		Hash:      hashToken(secret),
+		Scopes:    input.Scopes,
+		ExpiresAt: input.ExpiresAt,
+	}
+
+	key, err = s.apiKeyRepo.Insert(ctx, key)
+	if err != nil {
+		logger.From(ctx).Errorf("AuthService.IssueKey - s.apiKeyRepo.Insert: %v", err)
+		return IssuedKey{}, ErrCannotIssueKey
+	}
+
+	return IssuedKey{APIKey: key, Secret: secret}, nil
+}
+
+func (s *AuthService) ListKeys(ctx context.Context) ([]entity.APIKey, error) {
+	keys, err := s.apiKeyRepo.List(ctx)
+	if err != nil {
+		logger.From(ctx).Errorf("AuthService.ListKeys - s.apiKeyRepo.List: %v", err)
+		return nil, ErrCannotGetKeys
+	}
+
+	return keys, nil
+}
+
+func (s *AuthService) RevokeKey(ctx context.Context, id int) error {
+	err := s.apiKeyRepo.Revoke(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrKeyNotFound
+		}
+		logger.From(ctx).Errorf("AuthService.RevokeKey - s.apiKeyRepo.Revoke: %v", err)
+		return ErrCannotRevokeKey
+	}
+
+	return nil
+}
+
+// Authenticate проверяет API-ключ или access-токен пользователя и возвращает участника
+// вместе с его арендатором.
+func (s *AuthService) Authenticate(ctx context.Context, secret string) (Principal, error) {
+	var (
+		principal Principal
+		err       error
+	)
+	if strings.HasPrefix(secret, apiKeyPrefix) {
+		principal, err = s.authenticateKey(ctx, secret)
+	} else {
+		principal, err = s.authenticateToken(secret)
+	}
+	if err != nil {
+		return Principal{}, err
+	}
+
+	return s.bindTenant(ctx, principal)
+}
+
+func (s *AuthService) authenticateToken(secret string) (Principal, error) {
+	claims, err := parseAccessToken(s.config.Get(), secret, s.now())
+	if err != nil {
+		return Principal{}, ErrInvalidCredentials
+	}
+	userId, _ := strconv.Atoi(claims.Subject)
+
+	return Principal{
+		UserId:    userId,
+		SessionId: claims.SessionId,
+		Scopes:    []entity.Scope{claims.Role.Scope()},
+		Role:      claims.Role,
+		Tenant:    entity.Tenant{Id: claims.TenantId},
+	}, nil
+}
+
+func (s *AuthService) authenticateKey(ctx context.Context, secret string) (Principal, error) {
+	key, err := s.apiKeyRepo.GetByHash(ctx, hashToken(secret))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return Principal{}, ErrInvalidCredentials
+		}
+		logger.From(ctx).Errorf("AuthService.Authenticate - s.apiKeyRepo.GetByHash: %v", err)
+		return Principal{}, ErrCannotAuthenticate
+	}
+
+	if !key.Active(s.now()) {
+		return Principal{}, ErrInvalidCredentials
+	}
+
+	return Principal{
+		APIKeyId: key.Id,
+		Scopes:   key.Scopes,
+		Role:     entity.RoleForScopes(key.Scopes),
+		Tenant:   entity.Tenant{Id: key.TenantId},
+	}, nil
+}
+
+// bindTenant подставляет арендатора участника. Ключи и токены действуют только в своей
+// библиотеке: если запрос явно указал другую, он отклоняется.
+func (s *AuthService) bindTenant(ctx context.Context, p Principal) (Principal, error) {
+	if requested, ok := tenant.From(ctx); ok && requested.Id != p.Tenant.Id {
+		logger.From(ctx).Infof("AuthService.Authenticate - %s of tenant %d requested tenant %q", p, p.Tenant.Id, requested.Slug)
+		return Principal{}, ErrTenantMismatch
+	}
+
+	t, err := s.tenants.Get(ctx, p.Tenant.Id)
+	if err != nil {
+		if errors.Is(err, ErrTenantNotFound) {
+			return Principal{}, ErrInvalidCredentials
+		}
+		return Principal{}, err
+	}
+	p.Tenant = t
+
+	return p, nil
+}
+
+// Register создаёт пользователя в библиотеке запроса. Без приглашения регистрироваться можно
+// только в библиотеке по умолчанию и в открытых для регистрации, иначе кто угодно мог бы
+// войти в чужую библиотеку, указав её в заголовке. Приглашение действует и при закрытой регистрации.
+func (s *AuthService) Register(ctx context.Context, input RegisterInput) (entity.User, error) {
+	if len(input.Invite) == 0 {
+		settings := s.config.Get()
+		if !settings.AllowRegistration {
+			return entity.User{}, ErrRegistrationDisabled
+		}
+		t, ok := tenant.From(ctx)
+		if !ok {
+			t = entity.Tenant{Id: entity.DefaultTenantId}
+		}
+		if !settings.registrationOpen(t) {
+			return entity.User{}, ErrInviteRequired
+		}
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		logger.From(ctx).Errorf("AuthService.Register - bcrypt.GenerateFromPassword: %v", err)
+		return entity.User{}, ErrCannotRegister
+	}
+
+	var user entity.User
+	// Если имя занято, приглашение остаётся неиспользованным: транзакция откатывается.
+	err = s.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		if len(input.Invite) > 0 {
+			_, err := s.inviteRepo.Consume(txCtx, hashToken(input.Invite))
+			if err != nil {
+				if errors.Is(err, repository.ErrNotFound) {
+					return ErrInvalidInvite
+				}
+				logger.From(ctx).Errorf("AuthService.Register - s.inviteRepo.Consume: %v", err)
+				return ErrCannotRegister
+			}
+		}
+
+		user, err = s.userRepo.Insert(txCtx, entity.User{Username: input.Username, PasswordHash: string(hash)})
+		if err != nil {
+			if errors.Is(err, repository.ErrAlreadyExists) {
+				return ErrUserAlreadyExists
+			}
+			logger.From(ctx).Errorf("AuthService.Register - s.userRepo.Insert: %v", err)
+			return ErrCannotRegister
+		}
+
+		return nil
+	})
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	return user, nil
+}
+
+// IssueInvite создаёт приглашение в библиотеку запроса и возвращает его вместе с кодом.
+// В базе хранится только хэш, поэтому код показывается один раз.
+func (s *AuthService) IssueInvite(ctx context.Context, input IssueInviteInput) (IssuedInvite, error) {
+	expiresAt := s.now().Add(defaultInviteTTL)
+	if input.ExpiresAt != nil {
+		if !input.ExpiresAt.After(s.now()) {
+			return IssuedInvite{}, ErrInvalidExpiry
+		}
+		expiresAt = *input.ExpiresAt
+	}
+
+	b := make([]byte, inviteCodeBytes)
+	if _, err := rand.Read(b); err != nil {
+		logger.From(ctx).Errorf("AuthService.IssueInvite - rand.Read: %v", err)
+		return IssuedInvite{}, ErrCannotIssueInvite
+	}
+	code := base64.RawURLEncoding.EncodeToString(b)
+
+	invite, err := s.inviteRepo.Insert(ctx, entity.Invite{Hash: hashToken(code), ExpiresAt: expiresAt})
+	if err != nil {
+		logger.From(ctx).Errorf("AuthService.IssueInvite - s.inviteRepo.Insert: %v", err)
+		return IssuedInvite{}, ErrCannotIssueInvite
+	}
+
+	return IssuedInvite{Invite: invite, Code: code}, nil
+}
+
+// Login ищет пользователя в библиотеке запроса, без неё — в библиотеке по умолчанию.
+func (s *AuthService) Login(ctx context.Context, input LoginInput) (Tokens, error) {
+	if len(s.config.Get().SigningKeys) == 0 {
+		return Tokens{}, ErrSessionsDisabled
+	}
+
+	user, err := s.userRepo.GetByUsername(ctx, input.Username)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			_ = bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(input.Password))
+			return Tokens{}, ErrInvalidCredentials
+		}
+		logger.From(ctx).Errorf("AuthService.Login - s.userRepo.GetByUsername: %v", err)
+		return Tokens{}, ErrCannotCreateSession
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.Password)); err != nil {
+		return Tokens{}, ErrInvalidCredentials
+	}
+
+	return s.createSession(ctx, user)
+}
+
+// Refresh обменивает refresh-токен на новую пару токенов. Старый токен отзывается.
+// Повторное использование отозванного токена означает, что его украли, поэтому
+// в этом случае отзываются все сессии пользователя.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (Tokens, error) {
+	if len(s.config.Get().SigningKeys) == 0 {
+		return Tokens{}, ErrSessionsDisabled
+	}
+
+	var (
+		tokens Tokens
+		valid  bool
+	)
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		session, err := s.sessionRepo.Consume(ctx, hashToken(refreshToken))
+		if err == nil {
+			// Роль читается заново, чтобы её изменение дошло до пользователя с новым токеном.
+			user, err := s.userRepo.GetById(ctx, session.UserId)
+			if err != nil {
+				logger.From(ctx).Errorf("AuthService.Refresh - s.userRepo.GetById: %v", err)
+				return ErrCannotCreateSession
+			}
+			valid = true
+			tokens, err = s.createSession(ctx, user)
+			return err
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			logger.From(ctx).Errorf("AuthService.Refresh - s.sessionRepo.Consume: %v", err)
+			return ErrCannotCreateSession
+		}
+
+		// Неверный токен не откатывает транзакцию, чтобы отзыв всех сессий сохранился.
+		session, err = s.sessionRepo.GetByHash(ctx, hashToken(refreshToken))
+		if err != nil || session.RevokedAt == nil {
+			return nil
+		}
+		logger.From(ctx).Warnf("AuthService.Refresh - revoked refresh token reused, revoking all sessions of user %d", session.UserId)
+		if err = s.sessionRepo.RevokeByUserId(ctx, session.UserId); err != nil {
+			logger.From(ctx).Errorf("AuthService.Refresh - s.sessionRepo.RevokeByUserId: %v", err)
+			return ErrCannotCreateSession
+		}
+		return nil
+	})
+	if err != nil {
+		return Tokens{}, err
+	}
+	if !valid {
+		return Tokens{}, ErrInvalidCredentials
+	}
+
+	return tokens, nil
+}
+
+func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
+	_, err := s.sessionRepo.Consume(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidCredentials
+		}
+		logger.From(ctx).Errorf("AuthService.Logout - s.sessionRepo.Consume: %v", err)
+		return ErrCannotLogout
+	}
+
+	return nil
+}
+
+func (s *AuthService) createSession(ctx context.Context, user entity.User) (Tokens, error) {
+	settings := s.config.Get()
+	now := s.now()
+
+	refreshToken, err := generateRefreshToken()
+	if err != nil {
+		logger.From(ctx).Errorf("AuthService.createSession - generateRefreshToken: %v", err)
+		return Tokens{}, ErrCannotCreateSession
+	}
+
+	sessionId, err := s.sessionRepo.Insert(ctx, entity.Session{
+		UserId:    user.Id,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: now.Add(settings.RefreshTTL),
+	})
+	if err != nil {
+		logger.From(ctx).Errorf("AuthService.createSession - s.sessionRepo.Insert: %v", err)
+		return Tokens{}, ErrCannotCreateSession
+	}
+
+	accessToken, err := signAccessToken(settings, user, sessionId, now)
+	if err != nil {
+		logger.From(ctx).Errorf("AuthService.createSession - signAccessToken: %v", err)
+		return Tokens{}, ErrCannotCreateSession
+	}
+
+	return Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    settings.AccessTTL,
+	}, nil
+}
+
+func (s *AuthService) ListUsers(ctx context.Context) ([]entity.User, error) {
+	if err := authorize(ctx, entity.PermManageRoles); err != nil {
+		return nil, err
+	}
+
+	users, err := s.userRepo.List(ctx)
+	if err != nil {
+		logger.From(ctx).Errorf("AuthService.ListUsers - s.userRepo.List: %v", err)
+		return nil, ErrCannotGetUsers
+	}
+
+	return users, nil
+}
+
+// AssignRole меняет роль пользователя. Уже выданный access-токен сохраняет прежнюю роль
+// до истечения, новая роль попадёт в токен при обновлении сессии.
+func (s *AuthService) AssignRole(ctx context.Context, userId int, role entity.Role) (entity.User, error) {
+	if err := authorize(ctx, entity.PermManageRoles); err != nil {
+		return entity.User{}, err
+	}
+
+	if !role.Valid() {
+		return entity.User{}, ErrInvalidRole
+	}
+
+	user, err := s.userRepo.SetRole(ctx, userId, role)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return entity.User{}, ErrUserNotFound
+		}
+		logger.From(ctx).Errorf("AuthService.AssignRole - s.userRepo.SetRole: %v", err)
+		return entity.User{}, ErrCannotAssignRole
+	}
+
+	logger.From(ctx).Infof("AuthService.AssignRole - user %d got role %q from %s", userId, role, actor(ctx))
+	return user, nil
+}
+
+// generateAPIKey создаёт ключ вида sl_<id>_<secret>. Открытая часть sl_<id> хранится
+// в базе как есть и помогает найти ключ в списке, не раскрывая его.
+func generateAPIKey() (prefix, secret string, err error) {
+	id := make([]byte, apiKeyIdLength)
+	if _, err = rand.Read(id); err != nil {
+		return "", "", err
+	}
+
+	random := make([]byte, apiKeySecretBytes)
+	if _, err = rand.Read(random); err != nil {
+		return "", "", err
+	}
+
+	prefix = apiKeyPrefix + hex.EncodeToString(id)
+	return prefix, prefix + "_" + base64.RawURLEncoding.EncodeToString(random), nil
+}
+
+// hashToken хэширует API-ключ или refresh-токен. Они случайные и длинные, поэтому медленный
+// хэш, как для паролей, не нужен, а SHA-256 позволяет искать их по индексу.
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -13,4 +13,39 @@ var (
 	ErrCannotUpdateSong     = errors.New("cannot update song")
 	ErrCannotUpdateCouplets = errors.New("cannot update couplets")
 	ErrCannotDeleteSong     = errors.New("cannot delete song")
+	ErrInvalidLRC           = errors.New("invalid lrc")
+	ErrLineNotFound         = errors.New("line not found")
+	ErrTranslationNotFound  = errors.New("translation not found")
+	ErrTranslationMismatch  = errors.New("translation must have the same number of couplets as the original")
+	ErrCannotGetTranslation = errors.New("cannot get translation")
+	ErrCannotPutTranslation = errors.New("cannot save translation")
+	ErrInvalidScope         = errors.New("scopes must be one or more of read, write, admin")
+	ErrInvalidExpiry        = errors.New("expiry must be in the future")
+	ErrCannotIssueKey       = errors.New("cannot issue api key")
+	ErrCannotGetKeys        = errors.New("cannot get api keys")
+	ErrKeyNotFound          = errors.New("api key not found")
+	ErrCannotRevokeKey      = errors.New("cannot revoke api key")
+	ErrInvalidCredentials   = errors.New("invalid credentials")
+	ErrCannotAuthenticate   = errors.New("cannot authenticate")
+	ErrRegistrationDisabled = errors.New("registration is disabled")
+	ErrInviteRequired       = errors.New("registration in this tenant requires an invite")
+	ErrInvalidInvite        = errors.New("invite is invalid, expired or already used")
+	ErrCannotIssueInvite    = errors.New("cannot issue invite")
+	ErrUserAlreadyExists    = errors.New("username is already taken")
+	ErrCannotRegister       = errors.New("cannot register user")
+	ErrSessionsDisabled     = errors.New("user login is not configured")
+	ErrCannotCreateSession  = errors.New("cannot create session")
+	ErrCannotLogout         = errors.New("cannot log out")
+	ErrCannotGetAudit       = errors.New("cannot get audit log")
+	ErrPermissionDenied     = errors.New("operation is not allowed for your role")
+	ErrInvalidRole          = errors.New("role must be one of viewer, editor, moderator, admin")
+	ErrUserNotFound         = errors.New("user not found")
+	ErrCannotGetUsers       = errors.New("cannot get users")
+	ErrCannotAssignRole     = errors.New("cannot assign role")
+	ErrTenantNotFound       = errors.New("tenant not found")
+	ErrCannotGetTenant      = errors.New("cannot get tenant")
+	ErrTenantMismatch       = errors.New("credentials belong to another tenant")
+	ErrInvalidTenantSlug    = errors.New("tenant slug must consist of lowercase letters, digits and hyphens")
+	ErrTenantAlreadyExists  = errors.New("tenant slug is already taken")
+	ErrCannotCreateTenant   = errors.New("cannot create tenant")
 )
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/repository"
+	"github.com/spanwalla/song-library/pkg/logger"
+	"github.com/spanwalla/song-library/pkg/lrc"
+	"github.com/spanwalla/song-library/pkg/postgres"
+)
+
+// ExportLRC читает песню, куплеты и метки времени из одного снимка, чтобы замена текста
+// между запросами не смешала старые метки с новыми куплетами.
+func (s *SongService) ExportLRC(ctx context.Context, songId int) (string, error) {
+	var (
+		song     entity.Song
+		couplets []entity.Couplet
+		timings  []entity.LineTiming
+	)
+	err := s.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		var err error
+		song, err = s.songRepo.GetById(txCtx, songId)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrSongNotFound
+			}
+			logger.From(ctx).Errorf("SongService.ExportLRC - s.songRepo.GetById: %v", err)
+			return ErrCannotGetText
+		}
+
+		couplets, err = s.coupletRepo.GetAllBySongId(txCtx, songId)
+		if err != nil {
+			logger.From(ctx).Errorf("SongService.ExportLRC - s.coupletRepo.GetAllBySongId: %v", err)
+			return ErrCannotGetText
+		}
+
+		timings, err = s.lineTimingRepo.GetBySongId(txCtx, songId)
+		if err != nil {
+			logger.From(ctx).Errorf("SongService.ExportLRC - s.lineTimingRepo.GetBySongId: %v", err)
+			return ErrCannotGetText
+		}
+
+		return nil
+	}, postgres.Isolation(pgx.RepeatableRead), postgres.ReadOnly())
+	if err != nil {
+		return "", err
+	}
+
+	file := lrc.File{
+		Tags: map[string]string{
+			lrc.TagTitle:  song.Name,
+			lrc.TagArtist: song.Group,
+		},
+		Lines: lrcLines(couplets, timings),
+	}
+
+	return lrc.Format(file), nil
+}
+
+// lrcLines превращает куплеты в строки LRC: между куплетами пустая строка без метки,
+// после строки с EndMs — метка без текста.
+func lrcLines(couplets []entity.Couplet, timings []entity.LineTiming) []lrc.Line {
+	byLine := make(map[[2]int]entity.LineTiming, len(timings))
+	for _, timing := range timings {
+		byLine[[2]int{timing.SequenceNumber, timing.LineNumber}] = timing
+	}
+
+	lines := make([]lrc.Line, 0)
+	for i, couplet := range couplets {
+		if i > 0 {
+			lines = append(lines, lrc.Line{})
+		}
+		for j, text := range strings.Split(couplet.Text, "\n") {
+			timing, ok := byLine[[2]int{couplet.SequenceNumber, j + 1}]
+			if !ok {
+				lines = append(lines, lrc.Line{Text: text})
+				continue
+			}
+			start := time.Duration(timing.StartMs) * time.Millisecond
+			lines = append(lines, lrc.Line{Start: &start, Text: text})
+			if timing.EndMs != nil {
+				end := time.Duration(*timing.EndMs) * time.Millisecond
+				lines = append(lines, lrc.Line{Start: &end})
+			}
+		}
+	}
+
+	return lines
+}
+
+// coupletsFromLRC разбивает строки LRC на куплеты по пустым строкам без меток. Метка без текста
+// не начинает новый куплет, а становится временем окончания предыдущей строки, чтобы в проигрыше
+// не показывалась строка перед ним. Если у предыдущей строки нет метки или метка окончания
+// не позже её начала, такая метка отбрасывается.
+func coupletsFromLRC(songId int, file lrc.File) ([]entity.Couplet, []entity.LineTiming) {
+	couplets := make([]entity.Couplet, 0)
+	timings := make([]entity.LineTiming, 0)
+	lines := make([]string, 0)
+	// last — индекс в timings метки последней строки с текстом, -1, если у неё нет метки.
+	last := -1
+
+	flush := func() {
+		if len(lines) == 0 {
+			return
+		}
+		couplets = append(couplets, entity.Couplet{
+			SongId:         songId,
+			SequenceNumber: len(couplets) + 1,
+			Text:           strings.Join(lines, "\n"),
+		})
+		lines = lines[:0]
+	}
+
+	for _, line := range file.Lines {
+		if len(line.Text) == 0 {
+			if line.Start == nil {
+				flush()
+				continue
+			}
+			end := int(line.Start.Milliseconds())
+			if last >= 0 && timings[last].EndMs == nil && end > timings[last].StartMs {
+				timings[last].EndMs = &end
+			}
+			continue
+		}
+
+		lines = append(lines, line.Text)
+		last = -1
+		if line.Start != nil {
+			timings = append(timings, entity.LineTiming{
+				SongId:         songId,
+				SequenceNumber: len(couplets) + 1,
+				LineNumber:     len(lines),
+				StartMs:        int(line.Start.Milliseconds()),
+			})
+			last = len(timings) - 1
+		}
+	}
+	flush()
+
+	return couplets, timings
+}
+
+func (s *SongService) ImportLRC(ctx context.Context, songId int, data string) error {
+	file, err := lrc.Parse(data)
+	if err != nil {
+		logger.From(ctx).Debugf("SongService.ImportLRC - lrc.Parse: %v", err)
+		return ErrInvalidLRC
+	}
+
+	couplets, timings := coupletsFromLRC(songId, file)
+	if len(couplets) == 0 {
+		return ErrInvalidLRC
+	}
+
+	err = s.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		before, err := s.lockText(txCtx, songId)
+		if err != nil {
+			if !errors.Is(err, ErrSongNotFound) {
+				logger.From(ctx).Errorf("SongService.ImportLRC - s.lockText: %v", err)
+				err = ErrCannotUpdateCouplets
+			}
+			return err
+		}
+
+		err = s.coupletRepo.DeleteBySongId(txCtx, songId)
+		if err != nil {
+			logger.From(ctx).Errorf("SongService.ImportLRC - s.coupletRepo.DeleteBySongId: %v", err)
+			return ErrCannotUpdateCouplets
+		}
+
+		err = s.coupletRepo.Insert(txCtx, couplets)
+		if err != nil {
+			logger.From(ctx).Errorf("SongService.ImportLRC - s.coupletRepo.Insert: %v", err)
+			return ErrCannotUpdateCouplets
+		}
+
+		// Переводы сопоставляются с куплетами по номеру и после замены текста не соответствуют ему.
+		err = s.translationRepo.DeleteAllBySongId(txCtx, songId)
+		if err != nil {
+			logger.From(ctx).Errorf("SongService.ImportLRC - s.translationRepo.DeleteAllBySongId: %v", err)
+			return ErrCannotUpdateCouplets
+		}
+
+		err = s.lineTimingRepo.Insert(txCtx, timings)
+		if err != nil {
+			logger.From(ctx).Errorf("SongService.ImportLRC - s.lineTimingRepo.Insert: %v", err)
+			return ErrCannotUpdateCouplets
+		}
+
+		after := make([]string, 0, len(couplets))
+		for _, couplet := range couplets {
+			after = append(after, couplet.Text)
+		}
+
+		err = s.writeAudit(txCtx, songId, entity.AuditUpdateText, &songSnapshot{Text: before}, &songSnapshot{Text: after})
+		if err != nil {
+			logger.From(ctx).Errorf("SongService.ImportLRC - s.writeAudit: %v", err)
+			return ErrCannotUpdateCouplets
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.From(ctx).Infof("SongService.ImportLRC - text of song %d updated by %s", songId, actor(ctx))
+	return nil
+}
+
+func (s *SongService) GetActiveLine(ctx context.Context, songId int, position time.Duration) (entity.SyncedLine, error) {
+	line, err := s.lineTimingRepo.GetActiveLine(ctx, songId, int(position.Milliseconds()))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return entity.SyncedLine{}, ErrLineNotFound
+		}
+		logger.From(ctx).Errorf("SongService.GetActiveLine - s.lineTimingRepo.GetActiveLine: %v", err)
+		return entity.SyncedLine{}, ErrCannotGetText
+	}
+
+	return line, nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/pkg/lrc"
+)
+
+func ms(v int) *int {
+	return &v
+}
+
+func TestCoupletsFromLRC(t *testing.T) {
+	file, err := lrc.Parse("[ti:Song]\n" +
+		"[00:10.00]verse 1\n" +
+		"[00:12.00]verse 1 end\n" +
+		"[00:15.00]\n" +
+		"\n" +
+		"[00:20.00]chorus\n" +
+		"[00:25.00]\n" +
+		"[00:30.00]chorus end\n" +
+		"\n" +
+		"untimed\n" +
+		"[00:40.00]\n" +
+		"[00:45.00]last\n" +
+		"[00:44.00]\n")
+	require.NoError(t, err)
+
+	couplets, timings := coupletsFromLRC(7, file)
+
+	// Метки без текста не разделяют куплеты.
+	assert.Equal(t, []entity.Couplet{
+		{SongId: 7, SequenceNumber: 1, Text: "verse 1\nverse 1 end"},
+		{SongId: 7, SequenceNumber: 2, Text: "chorus\nchorus end"},
+		{SongId: 7, SequenceNumber: 3, Text: "untimed\nlast"},
+	}, couplets)
+
+	// Метка после строки без метки и метка раньше начала строки отбрасываются.
+	assert.Equal(t, []entity.LineTiming{
+		{SongId: 7, SequenceNumber: 1, LineNumber: 1, StartMs: 10_000},
+		{SongId: 7, SequenceNumber: 1, LineNumber: 2, StartMs: 12_000, EndMs: ms(15_000)},
+		{SongId: 7, SequenceNumber: 2, LineNumber: 1, StartMs: 20_000, EndMs: ms(25_000)},
+		{SongId: 7, SequenceNumber: 2, LineNumber: 2, StartMs: 30_000},
+		{SongId: 7, SequenceNumber: 3, LineNumber: 2, StartMs: 45_000},
+	}, timings)
+}
+
+func TestLRCLinesRoundTrip(t *testing.T) {
+	couplets := []entity.Couplet{
+		{SongId: 7, SequenceNumber: 1, Text: "verse\nverse end"},
+		{SongId: 7, SequenceNumber: 2, Text: "chorus\nuntimed"},
+	}
+	timings := []entity.LineTiming{
+		{SongId: 7, SequenceNumber: 1, LineNumber: 1, StartMs: 10_000},
+		{SongId: 7, SequenceNumber: 1, LineNumber: 2, StartMs: 12_000, EndMs: ms(15_000)},
+		{SongId: 7, SequenceNumber: 2, LineNumber: 1, StartMs: 80_000, EndMs: ms(85_000)},
+	}
+
+	data := lrc.Format(lrc.File{Lines: lrcLines(couplets, timings)})
+	assert.Equal(t, "[00:10.00]verse\n[00:12.00]verse end\n[00:15.00]\n\n[01:20.00]chorus\n[01:25.00]\nuntimed\n", data)
+
+	file, err := lrc.Parse(data)
+	require.NoError(t, err)
+	gotCouplets, gotTimings := coupletsFromLRC(7, file)
+	assert.Equal(t, couplets, gotCouplets)
+	assert.Equal(t, timings, gotTimings)
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/tenant"
+	"github.com/spanwalla/song-library/pkg/logger"
+)
+
+// Principal описывает того, от чьего имени выполняется запрос: пользователя, вошедшего
+// по логину и паролю, или клиента с API-ключом. Scopes проверяются на маршрутах API,
+// Role — в сервисах для каждой операции. Tenant — библиотека, к которой относятся
+// учётные данные.
+type Principal struct {
+	UserId    int
+	SessionId int
+	APIKeyId  int
+	Scopes    []entity.Scope
+	Role      entity.Role
+	Tenant    entity.Tenant
+}
+
+// HasScope сообщает, разрешён ли участнику уровень доступа required.
+func (p Principal) HasScope(required entity.Scope) bool {
+	return slices.ContainsFunc(p.Scopes, func(s entity.Scope) bool { return s.Includes(required) })
+}
+
+// String возвращает участника в виде user:<id> или api_key:<id> для журналов.
+func (p Principal) String() string {
+	if p.UserId > 0 {
+		return fmt.Sprintf("user:%d", p.UserId)
+	}
+	return fmt.Sprintf("api_key:%d", p.APIKeyId)
+}
+
+// LogFields возвращает поля журнала, по которым можно найти записи участника.
+func (p Principal) LogFields() log.Fields {
+	if p.UserId > 0 {
+		return log.Fields{logger.FieldUserId: p.UserId, logger.FieldTenant: p.Tenant.Slug}
+	}
+	return log.Fields{logger.FieldAPIKeyId: p.APIKeyId, logger.FieldTenant: p.Tenant.Slug}
+}
+
+type principalKey struct{}
+
+// WithPrincipal возвращает контекст, в котором запрос выполняется от имени p
+// в библиотеке его арендатора.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	ctx = tenant.With(ctx, p.Tenant)
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom возвращает участника, от имени которого выполняется запрос.
+// Если аутентификация отключена или вызов идёт из songctl, участника в контексте нет.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// actor возвращает участника запроса для журнала изменений.
+func actor(ctx context.Context) string {
+	if p, ok := PrincipalFrom(ctx); ok {
+		return p.String()
+	}
+	return "system"
+}
+
+// authorize проверяет, разрешена ли операция участнику запроса. Без участника запрос
+// выполняется от имени системы (аутентификация отключена или вызов из songctl) и разрешён.
+func authorize(ctx context.Context, permission entity.Permission) error {
+	p, ok := PrincipalFrom(ctx)
+	if !ok || p.Role.Can(permission) {
+		return nil
+	}
+
+	logger.From(ctx).Infof("authorize - %s with role %q is not allowed to %s", p, p.Role, permission)
+	return ErrPermissionDenied
+}
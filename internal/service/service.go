@@ -2,10 +2,12 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/spanwalla/song-library/internal/entity"
 	"github.com/spanwalla/song-library/internal/repository"
 	"github.com/spanwalla/song-library/internal/webapi"
+	"github.com/spanwalla/song-library/pkg/cache"
 )
 
 //go:generate mockgen -source=service.go -destination=../mocks/service/mock.go -package=servicemocks
@@ -23,9 +25,27 @@ type UpdateSongInput struct {
 }
 
 type GetTextInput struct {
-	SongId int
-	Offset int
-	Limit  int
+	SongId   int
+	Language string
+	Offset   int
+	Limit    int
+}
+
+type GetTextsInput struct {
+	SongIds []int
+	Offset  int
+	Limit   int
+}
+
+type Text struct {
+	Couplets []string
+	Count    int
+}
+
+type PutTranslationInput struct {
+	SongId   int
+	Language string
+	Text     string
 }
 
 type SearchSongInput struct {
@@ -35,28 +55,140 @@ type SearchSongInput struct {
 	Limit   int
 }
 
+type IssueKeyInput struct {
+	Name      string
+	Scopes    []entity.Scope
+	ExpiresAt *time.Time
+}
+
+// IssuedKey содержит созданный ключ и его секрет, который больше нигде не хранится.
+type IssuedKey struct {
+	entity.APIKey
+	Secret string
+}
+
+// RegisterInput: Invite нужен для регистрации в библиотеке, закрытой для свободной регистрации.
+type RegisterInput struct {
+	Username string
+	Password string
+	Invite   string
+}
+
+// IssueInviteInput: без ExpiresAt приглашение действует неделю.
+type IssueInviteInput struct {
+	ExpiresAt *time.Time
+}
+
+// IssuedInvite содержит созданное приглашение и его код, который больше нигде не хранится.
+type IssuedInvite struct {
+	entity.Invite
+	Code string
+}
+
+type LoginInput struct {
+	Username string
+	Password string
+}
+
+// Tokens выдаются при входе и обновлении сессии.
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    time.Duration
+}
+
+type CreateTenantInput struct {
+	Slug string
+	Name string
+}
+
+// ListAuditInput задаёт фильтры журнала аудита. Нулевые значения не ограничивают выборку.
+type ListAuditInput struct {
+	SongId int
+	Actor  string
+	Action entity.AuditAction
+	Since  *time.Time
+	Until  *time.Time
+	Offset int
+	Limit  int
+}
+
 type Song interface {
 	Insert(ctx context.Context, input InsertSongInput) error
+	Import(ctx context.Context, inputs []InsertSongInput) ([]int, error)
 	Search(ctx context.Context, input SearchSongInput) ([]entity.Song, error)
 	Get(ctx context.Context, songId int) (entity.Song, error)
 	GetText(ctx context.Context, input GetTextInput) ([]string, int, error)
+	GetTexts(ctx context.Context, input GetTextsInput) (map[int]Text, error)
 	Update(ctx context.Context, songId int, input UpdateSongInput) error
 	UpdateText(ctx context.Context, songId int, text string) error
 	Delete(ctx context.Context, songId int) error
+	ExportLRC(ctx context.Context, songId int) (string, error)
+	ImportLRC(ctx context.Context, songId int, data string) error
+	GetActiveLine(ctx context.Context, songId int, position time.Duration) (entity.SyncedLine, error)
+	PutTranslation(ctx context.Context, input PutTranslationInput) error
+	ListTranslations(ctx context.Context, songId int) ([]entity.TranslationInfo, error)
+	GetSideBySide(ctx context.Context, input GetTextInput) ([]entity.AlignedCouplet, int, error)
+}
+
+type Auth interface {
+	IssueKey(ctx context.Context, input IssueKeyInput) (IssuedKey, error)
+	ListKeys(ctx context.Context) ([]entity.APIKey, error)
+	RevokeKey(ctx context.Context, id int) error
+	Authenticate(ctx context.Context, secret string) (Principal, error)
+	Register(ctx context.Context, input RegisterInput) (entity.User, error)
+	IssueInvite(ctx context.Context, input IssueInviteInput) (IssuedInvite, error)
+	Login(ctx context.Context, input LoginInput) (Tokens, error)
+	Refresh(ctx context.Context, refreshToken string) (Tokens, error)
+	Logout(ctx context.Context, refreshToken string) error
+	ListUsers(ctx context.Context) ([]entity.User, error)
+	AssignRole(ctx context.Context, userId int, role entity.Role) (entity.User, error)
+}
+
+type Audit interface {
+	List(ctx context.Context, input ListAuditInput) ([]entity.AuditEntry, error)
+}
+
+type Tenant interface {
+	Resolve(ctx context.Context, slug string) (entity.Tenant, error)
+	Get(ctx context.Context, tenantId int) (entity.Tenant, error)
+	Create(ctx context.Context, input CreateTenantInput) (entity.Tenant, error)
+	List(ctx context.Context) ([]entity.Tenant, error)
 }
 
 type Services struct {
 	Song
+	Auth
+	Audit
+	Tenant
 }
 
 type Dependencies struct {
 	Repos      *repository.Repositories
 	SongInfo   webapi.SongInfo
 	Transactor repository.Transactor
+	AuthConfig *AuthConfig
+	// Cache включает кэш песен и текстов, если задан. Записи живут CacheTTL.
+	Cache    cache.Cache
+	CacheTTL time.Duration
+	// Primary возвращает контекст чтения с основного сервера. Им заполняется кэш, чтобы
+	// данные отстающей реплики не хранились в нём до CacheTTL.
+	Primary func(ctx context.Context) context.Context
 }
 
 func NewServices(deps Dependencies) *Services {
+	tenants := NewTenantService(deps.Repos.Tenant)
+
+	var song Song = NewSongService(deps.Repos.Song, deps.Repos.Couplet, deps.Repos.LineTiming, deps.Repos.Translation, deps.Repos.Audit, deps.Transactor, deps.SongInfo)
+	// Кэш стоит под проверкой роли, чтобы ответ из кэша не обходил её.
+	if deps.Cache != nil {
+		song = newSongCache(song, deps.Cache, deps.CacheTTL, deps.Primary)
+	}
+
 	return &Services{
-		Song: NewSongService(deps.Repos.Song, deps.Repos.Couplet, deps.Transactor, deps.SongInfo),
+		Song:   newSongTracing(newSongAccess(song)),
+		Auth:   NewAuthService(deps.Repos.APIKey, deps.Repos.User, deps.Repos.Invite, deps.Repos.Session, tenants, deps.Transactor, deps.AuthConfig),
+		Audit:  NewAuditService(deps.Repos.Audit),
+		Tenant: tenants,
 	}
 }
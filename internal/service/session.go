@@ -0,0 +1,142 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/spanwalla/song-library/internal/entity"
+)
+
+const (
+	tokenIssuer        = "song-library"
+	refreshTokenLength = 32
+)
+
+// SigningKey — ключ подписи access-токенов. Идентификатор попадает в заголовок kid,
+// чтобы после ротации токены, подписанные прежним ключом, оставались действительными.
+type SigningKey struct {
+	Id     string
+	Secret []byte
+}
+
+// AuthSettings содержит настройки входа пользователей, которые можно менять без перезапуска.
+type AuthSettings struct {
+	// AllowRegistration открывает свободную регистрацию в библиотеке по умолчанию
+	// и в библиотеках из RegistrationTenants. В остальные регистрируются по приглашению.
+	AllowRegistration   bool
+	RegistrationTenants []string
+	// SigningKeys: первым ключом подписываются новые токены, остальные только проверяют
+	// уже выданные. Пустой список отключает вход пользователей.
+	SigningKeys []SigningKey
+	AccessTTL   time.Duration
+	RefreshTTL  time.Duration
+}
+
+// registrationOpen сообщает, можно ли зарегистрироваться в библиотеке t без приглашения.
+func (s AuthSettings) registrationOpen(t entity.Tenant) bool {
+	return s.AllowRegistration && (t.Id == entity.DefaultTenantId || slices.Contains(s.RegistrationTenants, t.Slug))
+}
+
+// AuthConfig хранит текущие AuthSettings и позволяет заменить их при перезагрузке конфигурации.
+type AuthConfig struct {
+	settings atomic.Pointer[AuthSettings]
+}
+
+func NewAuthConfig(settings AuthSettings) *AuthConfig {
+	c := &AuthConfig{}
+	c.Set(settings)
+	return c
+}
+
+func (c *AuthConfig) Set(settings AuthSettings) {
+	c.settings.Store(&settings)
+}
+
+func (c *AuthConfig) Get() AuthSettings {
+	return *c.settings.Load()
+}
+
+// accessClaims содержит роль и арендатора пользователя, чтобы не читать их из базы на каждый
+// запрос. Поэтому новая роль начинает действовать после обновления токена.
+type accessClaims struct {
+	jwt.RegisteredClaims
+	SessionId int         `json:"sid"`
+	Role      entity.Role `json:"role"`
+	TenantId  int         `json:"tid"`
+}
+
+func signAccessToken(settings AuthSettings, user entity.User, sessionId int, now time.Time) (string, error) {
+	if len(settings.SigningKeys) == 0 {
+		return "", ErrSessionsDisabled
+	}
+	key := settings.SigningKeys[0]
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tokenIssuer,
+			Subject:   strconv.Itoa(user.Id),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(settings.AccessTTL)),
+		},
+		SessionId: sessionId,
+		Role:      user.Role,
+		TenantId:  user.TenantId,
+	})
+	token.Header["kid"] = key.Id
+
+	return token.SignedString(key.Secret)
+}
+
+// parseAccessToken проверяет подпись и срок действия токена и возвращает его утверждения.
+func parseAccessToken(settings AuthSettings, token string, now time.Time) (accessClaims, error) {
+	var claims accessClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		for _, key := range settings.SigningKeys {
+			if key.Id == kid {
+				return key.Secret, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(tokenIssuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(func() time.Time { return now }),
+	)
+	if err != nil {
+		return accessClaims{}, err
+	}
+
+	if _, err = strconv.Atoi(claims.Subject); err != nil {
+		return accessClaims{}, errors.New("subject is not a user id")
+	}
+
+	// Токены, выданные до появления ролей, роли не содержат, и их нужно обновить.
+	if !claims.Role.Valid() {
+		return accessClaims{}, fmt.Errorf("unknown role %q", claims.Role)
+	}
+
+	// То же с токенами, выданными до появления арендаторов.
+	if claims.TenantId <= 0 {
+		return accessClaims{}, errors.New("tenant is missing")
+	}
+
+	return claims, nil
+}
+
+func generateRefreshToken() (string, error) {
+	b := make([]byte, refreshTokenLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
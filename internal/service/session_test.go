@@ -0,0 +1,25 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/spanwalla/song-library/internal/entity"
+)
+
+func TestRegistrationOpen(t *testing.T) {
+	defaultTenant := entity.Tenant{Id: entity.DefaultTenantId, Slug: "default"}
+	openTenant := entity.Tenant{Id: entity.DefaultTenantId + 1, Slug: "team-a"}
+	closedTenant := entity.Tenant{Id: entity.DefaultTenantId + 2, Slug: "team-b"}
+
+	settings := AuthSettings{AllowRegistration: true, RegistrationTenants: []string{"team-a"}}
+	assert.True(t, settings.registrationOpen(defaultTenant))
+	assert.True(t, settings.registrationOpen(openTenant))
+	assert.False(t, settings.registrationOpen(closedTenant))
+
+	// Закрытая регистрация не открывается переопределениями арендаторов.
+	settings.AllowRegistration = false
+	assert.False(t, settings.registrationOpen(defaultTenant))
+	assert.False(t, settings.registrationOpen(openTenant))
+}
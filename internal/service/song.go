@@ -3,67 +3,162 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
-	log "github.com/sirupsen/logrus"
-
 	"github.com/spanwalla/song-library/internal/entity"
 	"github.com/spanwalla/song-library/internal/repository"
 	"github.com/spanwalla/song-library/internal/webapi"
+	"github.com/spanwalla/song-library/pkg/logger"
 )
 
 type SongService struct {
-	songRepo    repository.Song
-	coupletRepo repository.Couplet
-	transactor  repository.Transactor
-	songInfo    webapi.SongInfo
+	songRepo        repository.Song
+	coupletRepo     repository.Couplet
+	lineTimingRepo  repository.LineTiming
+	translationRepo repository.Translation
+	auditRepo       repository.Audit
+	transactor      repository.Transactor
+	songInfo        webapi.SongInfo
 }
 
-func NewSongService(songRepo repository.Song, coupletRepo repository.Couplet, transactor repository.Transactor, songInfo webapi.SongInfo) *SongService {
+func NewSongService(songRepo repository.Song, coupletRepo repository.Couplet, lineTimingRepo repository.LineTiming, translationRepo repository.Translation, auditRepo repository.Audit, transactor repository.Transactor, songInfo webapi.SongInfo) *SongService {
 	return &SongService{
-		songRepo:    songRepo,
-		coupletRepo: coupletRepo,
-		transactor:  transactor,
-		songInfo:    songInfo,
+		songRepo:        songRepo,
+		coupletRepo:     coupletRepo,
+		lineTimingRepo:  lineTimingRepo,
+		translationRepo: translationRepo,
+		auditRepo:       auditRepo,
+		transactor:      transactor,
+		songInfo:        songInfo,
 	}
 }
 
 func (s *SongService) Insert(ctx context.Context, input InsertSongInput) error {
 	info, err := s.songInfo.Get(ctx, input.Group, input.Song)
 	if err != nil {
-		log.Errorf("SongService.Insert - s.songInfo.Get: %v", err)
+		logger.From(ctx).Errorf("SongService.Insert - s.songInfo.Get: %v", err)
 		return ErrCannotGetSongInfo
 	}
 
-	songId, err := s.songRepo.Insert(ctx, entity.Song{
+	song := entity.Song{
 		Name:        input.Song,
 		Group:       input.Group,
 		Link:        info.Link,
 		ReleaseDate: info.ReleaseDate,
+	}
+	text := strings.Split(info.Text, "\n\n")
+
+	err = s.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		song.Id, err = s.songRepo.Insert(txCtx, song)
+		if err != nil {
+			logger.From(ctx).Errorf("SongService.Insert - s.songRepo.Insert: %v", err)
+			return ErrCannotInsertSong
+		}
+
+		var couplets []entity.Couplet
+		for i, piece := range text {
+			couplets = append(couplets, entity.Couplet{
+				SongId:         song.Id,
+				SequenceNumber: i + 1,
+				Text:           piece,
+			})
+		}
+
+		err = s.coupletRepo.Insert(txCtx, couplets)
+		if err != nil {
+			logger.From(ctx).Errorf("SongService.Insert - s.coupletRepo.Insert: %v", err)
+			return ErrCannotInsertCouplets
+		}
+
+		err = s.writeAudit(txCtx, song.Id, entity.AuditInsert, nil, &songSnapshot{Song: &song, Text: text})
+		if err != nil {
+			logger.From(ctx).Errorf("SongService.Insert - s.writeAudit: %v", err)
+			return ErrCannotInsertSong
+		}
+
+		return nil
 	})
 	if err != nil {
-		log.Errorf("SongService.Insert - s.songRepo.Insert: %v", err)
-		return ErrCannotInsertSong
+		return err
 	}
 
-	var couplets []entity.Couplet
-	splitText := strings.Split(info.Text, "\n\n")
-	for i, piece := range splitText {
-		couplets = append(couplets, entity.Couplet{
-			SongId:         songId,
-			SequenceNumber: i + 1,
-			Text:           piece,
+	logger.From(ctx).Infof("SongService.Insert - song %d inserted by %s", song.Id, actor(ctx))
+	return nil
+}
+
+// Import добавляет несколько песен в одной транзакции. Песни, куплеты и записи журнала
+// вставляются пакетами, большие пакеты — через COPY. Если информацию хотя бы об одной песне
+// получить не удалось, не добавляется ни одна.
+func (s *SongService) Import(ctx context.Context, inputs []InsertSongInput) ([]int, error) {
+	songs := make([]entity.Song, 0, len(inputs))
+	texts := make([][]string, 0, len(inputs))
+	for _, input := range inputs {
+		info, err := s.songInfo.Get(ctx, input.Group, input.Song)
+		if err != nil {
+			logger.From(ctx).Errorf("SongService.Import - s.songInfo.Get: %v", err)
+			return nil, fmt.Errorf("%w: %s - %s", ErrCannotGetSongInfo, input.Group, input.Song)
+		}
+
+		songs = append(songs, entity.Song{
+			Name:        input.Song,
+			Group:       input.Group,
+			Link:        info.Link,
+			ReleaseDate: info.ReleaseDate,
 		})
+		texts = append(texts, strings.Split(info.Text, "\n\n"))
 	}
 
-	err = s.coupletRepo.Insert(ctx, couplets)
+	var ids []int
+	err := s.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		var err error
+		ids, err = s.songRepo.InsertMany(txCtx, songs)
+		if err != nil {
+			logger.From(ctx).Errorf("SongService.Import - s.songRepo.InsertMany: %v", err)
+			return ErrCannotInsertSong
+		}
+
+		var couplets []entity.Couplet
+		entries := make([]entity.AuditEntry, 0, len(songs))
+		for i := range songs {
+			songs[i].Id = ids[i]
+			for j, piece := range texts[i] {
+				couplets = append(couplets, entity.Couplet{
+					SongId:         ids[i],
+					SequenceNumber: j + 1,
+					Text:           piece,
+				})
+			}
+
+			entry, err := newAuditEntry(txCtx, ids[i], entity.AuditInsert, nil, &songSnapshot{Song: &songs[i], Text: texts[i]})
+			if err != nil {
+				logger.From(ctx).Errorf("SongService.Import - newAuditEntry: %v", err)
+				return ErrCannotInsertSong
+			}
+			entries = append(entries, entry)
+		}
+
+		err = s.coupletRepo.Insert(txCtx, couplets)
+		if err != nil {
+			logger.From(ctx).Errorf("SongService.Import - s.coupletRepo.Insert: %v", err)
+			return ErrCannotInsertCouplets
+		}
+
+		err = s.auditRepo.InsertMany(txCtx, entries)
+		if err != nil {
+			logger.From(ctx).Errorf("SongService.Import - s.auditRepo.InsertMany: %v", err)
+			return ErrCannotInsertSong
+		}
+
+		return nil
+	})
 	if err != nil {
-		log.Errorf("SongService.Insert - s.coupletRepo.Insert: %v", err)
-		return ErrCannotInsertCouplets
+		return nil, err
 	}
 
-	return nil
+	logger.From(ctx).Infof("SongService.Import - %d songs imported by %s", len(ids), actor(ctx))
+	return ids, nil
 }
 
 func (s *SongService) Get(ctx context.Context, songId int) (entity.Song, error) {
@@ -72,7 +167,7 @@ func (s *SongService) Get(ctx context.Context, songId int) (entity.Song, error)
 		if errors.Is(err, repository.ErrNotFound) {
 			return entity.Song{}, ErrSongNotFound
 		}
-		log.Errorf("SongService.Get - s.songRepo.GetById: %v", err)
+		logger.From(ctx).Errorf("SongService.Get - s.songRepo.GetById: %v", err)
 		return entity.Song{}, ErrCannotGetSong
 	}
 
@@ -82,7 +177,7 @@ func (s *SongService) Get(ctx context.Context, songId int) (entity.Song, error)
 func (s *SongService) Search(ctx context.Context, input SearchSongInput) ([]entity.Song, error) {
 	songs, err := s.songRepo.Search(ctx, input.Filters, input.OrderBy, input.Offset, input.Limit)
 	if err != nil {
-		log.Errorf("SongService.Search - s.songRepo.Search: %v", err)
+		logger.From(ctx).Errorf("SongService.Search - s.songRepo.Search: %v", err)
 		return []entity.Song{}, ErrCannotGetSong
 	}
 
@@ -90,9 +185,13 @@ func (s *SongService) Search(ctx context.Context, input SearchSongInput) ([]enti
 }
 
 func (s *SongService) GetText(ctx context.Context, input GetTextInput) ([]string, int, error) {
+	if len(input.Language) > 0 {
+		return s.getTranslationText(ctx, input)
+	}
+
 	count, err := s.coupletRepo.GetCoupletsCount(ctx, input.SongId)
 	if err != nil {
-		log.Errorf("SongService.GetText - s.coupletRepo.GetCoupletsCount: %v", err)
+		logger.From(ctx).Errorf("SongService.GetText - s.coupletRepo.GetCoupletsCount: %v", err)
 		return []string{}, 0, ErrCannotGetText
 	}
 
@@ -102,7 +201,7 @@ func (s *SongService) GetText(ctx context.Context, input GetTextInput) ([]string
 
 	couplets, err := s.coupletRepo.GetBySongId(ctx, input.SongId, input.Offset, input.Limit)
 	if err != nil {
-		log.Errorf("SongService.GetText - s.coupletRepo.GetBySongId: %v", err)
+		logger.From(ctx).Errorf("SongService.GetText - s.coupletRepo.GetBySongId: %v", err)
 		return []string{}, 0, ErrCannotGetText
 	}
 
@@ -114,6 +213,38 @@ func (s *SongService) GetText(ctx context.Context, input GetTextInput) ([]string
 	return text, count, nil
 }
 
+// GetTexts возвращает одну и ту же страницу текста для нескольких песен.
+// Песни без куплетов в результат не попадают.
+func (s *SongService) GetTexts(ctx context.Context, input GetTextsInput) (map[int]Text, error) {
+	texts := make(map[int]Text, len(input.SongIds))
+	if len(input.SongIds) == 0 {
+		return texts, nil
+	}
+
+	counts, err := s.coupletRepo.GetCoupletsCounts(ctx, input.SongIds)
+	if err != nil {
+		logger.From(ctx).Errorf("SongService.GetTexts - s.coupletRepo.GetCoupletsCounts: %v", err)
+		return nil, ErrCannotGetText
+	}
+
+	couplets, err := s.coupletRepo.GetPageBySongIds(ctx, input.SongIds, input.Offset, input.Limit)
+	if err != nil {
+		logger.From(ctx).Errorf("SongService.GetTexts - s.coupletRepo.GetPageBySongIds: %v", err)
+		return nil, ErrCannotGetText
+	}
+
+	for songId, count := range counts {
+		texts[songId] = Text{Couplets: make([]string, 0), Count: count}
+	}
+	for _, couplet := range couplets {
+		text := texts[couplet.SongId]
+		text.Couplets = append(text.Couplets, couplet.Text)
+		texts[couplet.SongId] = text
+	}
+
+	return texts, nil
+}
+
 func (s *SongService) Update(ctx context.Context, songId int, input UpdateSongInput) error {
 	if input.Name == nil && input.Group == nil && input.Link == nil && input.ReleaseDate == nil {
 		return ErrFieldsAreEmpty
@@ -124,22 +255,52 @@ func (s *SongService) Update(ctx context.Context, songId int, input UpdateSongIn
 	if input.ReleaseDate != nil {
 		parsedDate, err := time.Parse("2006-01-02", *input.ReleaseDate)
 		if err != nil {
-			log.Errorf("SongService.Update - time.Parse: %v", err)
+			logger.From(ctx).Errorf("SongService.Update - time.Parse: %v", err)
 			return ErrCannotUpdateSong
 		}
 		releaseDate = &parsedDate
 	}
 
-	err := s.songRepo.UpdateById(ctx, songId, repository.UpdateSongInput{
-		Name:        input.Name,
-		Group:       input.Group,
-		Link:        input.Link,
-		ReleaseDate: releaseDate,
+	err := s.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		before, err := s.songRepo.GetByIdForUpdate(txCtx, songId)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrSongNotFound
+			}
+			logger.From(ctx).Errorf("SongService.Update - s.songRepo.GetByIdForUpdate: %v", err)
+			return ErrCannotUpdateSong
+		}
+
+		err = s.songRepo.UpdateById(txCtx, songId, repository.UpdateSongInput{
+			Name:        input.Name,
+			Group:       input.Group,
+			Link:        input.Link,
+			ReleaseDate: releaseDate,
+		})
+		if err != nil {
+			logger.From(ctx).Errorf("SongService.Update - s.songRepo.UpdateById: %v", err)
+			return ErrCannotUpdateSong
+		}
+
+		after, err := s.songRepo.GetById(txCtx, songId)
+		if err != nil {
+			logger.From(ctx).Errorf("SongService.Update - s.songRepo.GetById: %v", err)
+			return ErrCannotUpdateSong
+		}
+
+		err = s.writeAudit(txCtx, songId, entity.AuditUpdate, &songSnapshot{Song: &before}, &songSnapshot{Song: &after})
+		if err != nil {
+			logger.From(ctx).Errorf("SongService.Update - s.writeAudit: %v", err)
+			return ErrCannotUpdateSong
+		}
+
+		return nil
 	})
 	if err != nil {
-		log.Errorf("SongService.Update - s.songRepo.UpdateById: %v", err)
-		return ErrCannotUpdateSong
+		return err
 	}
+
+	logger.From(ctx).Infof("SongService.Update - song %d updated by %s", songId, actor(ctx))
 	return nil
 }
 
@@ -151,29 +312,86 @@ func (s *SongService) UpdateText(ctx context.Context, songId int, text string) e
 		couplets = append(couplets, entity.Couplet{SongId: songId, SequenceNumber: i + 1, Text: val})
 	}
 
-	return s.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
-		err := s.coupletRepo.DeleteBySongId(txCtx, songId)
+	err := s.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		before, err := s.lockText(txCtx, songId)
+		if err != nil {
+			if !errors.Is(err, ErrSongNotFound) {
+				logger.From(ctx).Errorf("SongService.UpdateText - s.lockText: %v", err)
+				err = ErrCannotUpdateCouplets
+			}
+			return err
+		}
+
+		err = s.coupletRepo.DeleteBySongId(txCtx, songId)
 		if err != nil {
-			log.Errorf("SongService.UpdateText - s.coupletRepo.DeleteBySongId: %v", err)
+			logger.From(ctx).Errorf("SongService.UpdateText - s.coupletRepo.DeleteBySongId: %v", err)
 			return ErrCannotUpdateCouplets
 		}
 
 		err = s.coupletRepo.Insert(txCtx, couplets)
 		if err != nil {
-			log.Errorf("SongService.UpdateText - s.coupletRepo.Insert: %v", err)
+			logger.From(ctx).Errorf("SongService.UpdateText - s.coupletRepo.Insert: %v", err)
+			return ErrCannotUpdateCouplets
+		}
+
+		// Переводы сопоставляются с куплетами по номеру и после замены текста не соответствуют ему.
+		err = s.translationRepo.DeleteAllBySongId(txCtx, songId)
+		if err != nil {
+			logger.From(ctx).Errorf("SongService.UpdateText - s.translationRepo.DeleteAllBySongId: %v", err)
+			return ErrCannotUpdateCouplets
+		}
+
+		err = s.writeAudit(txCtx, songId, entity.AuditUpdateText, &songSnapshot{Text: before}, &songSnapshot{Text: coupletsStr})
+		if err != nil {
+			logger.From(ctx).Errorf("SongService.UpdateText - s.writeAudit: %v", err)
 			return ErrCannotUpdateCouplets
 		}
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	logger.From(ctx).Infof("SongService.UpdateText - text of song %d updated by %s", songId, actor(ctx))
+	return nil
 }
 
 func (s *SongService) Delete(ctx context.Context, songId int) error {
-	err := s.songRepo.DeleteById(ctx, songId)
+	err := s.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		song, err := s.songRepo.GetByIdForUpdate(txCtx, songId)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrSongNotFound
+			}
+			logger.From(ctx).Errorf("SongService.Delete - s.songRepo.GetByIdForUpdate: %v", err)
+			return ErrCannotDeleteSong
+		}
+
+		text, err := s.currentText(txCtx, songId)
+		if err != nil {
+			logger.From(ctx).Errorf("SongService.Delete - s.currentText: %v", err)
+			return ErrCannotDeleteSong
+		}
+
+		err = s.songRepo.DeleteById(txCtx, songId)
+		if err != nil {
+			logger.From(ctx).Errorf("SongService.Delete - s.songRepo.DeleteById: %v", err)
+			return ErrCannotDeleteSong
+		}
+
+		err = s.writeAudit(txCtx, songId, entity.AuditDelete, &songSnapshot{Song: &song, Text: text}, nil)
+		if err != nil {
+			logger.From(ctx).Errorf("SongService.Delete - s.writeAudit: %v", err)
+			return ErrCannotDeleteSong
+		}
+
+		return nil
+	})
 	if err != nil {
-		log.Errorf("SongService.Delete - s.songRepo.DeleteById: %v", err)
-		return ErrCannotDeleteSong
+		return err
 	}
 
+	logger.From(ctx).Infof("SongService.Delete - song %d deleted by %s", songId, actor(ctx))
 	return nil
 }
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/spanwalla/song-library/internal/entity"
+)
+
+// songAccess проверяет роль участника перед каждой операцией сервиса песен. Проверка
+// стоит в сервисе, а не в обработчиках, чтобы HTTP, GraphQL и gRPC запрещали одно и то же.
+type songAccess struct {
+	next Song
+}
+
+func newSongAccess(next Song) *songAccess {
+	return &songAccess{next: next}
+}
+
+func (s *songAccess) Insert(ctx context.Context, input InsertSongInput) error {
+	if err := authorize(ctx, entity.PermInsertSongs); err != nil {
+		return err
+	}
+	return s.next.Insert(ctx, input)
+}
+
+func (s *songAccess) Import(ctx context.Context, inputs []InsertSongInput) ([]int, error) {
+	if err := authorize(ctx, entity.PermInsertSongs); err != nil {
+		return nil, err
+	}
+	return s.next.Import(ctx, inputs)
+}
+
+func (s *songAccess) Search(ctx context.Context, input SearchSongInput) ([]entity.Song, error) {
+	if err := authorize(ctx, entity.PermReadSongs); err != nil {
+		return nil, err
+	}
+	return s.next.Search(ctx, input)
+}
+
+func (s *songAccess) Get(ctx context.Context, songId int) (entity.Song, error) {
+	if err := authorize(ctx, entity.PermReadSongs); err != nil {
+		return entity.Song{}, err
+	}
+	return s.next.Get(ctx, songId)
+}
+
+func (s *songAccess) GetText(ctx context.Context, input GetTextInput) ([]string, int, error) {
+	if err := authorize(ctx, entity.PermReadSongs); err != nil {
+		return nil, 0, err
+	}
+	return s.next.GetText(ctx, input)
+}
+
+func (s *songAccess) GetTexts(ctx context.Context, input GetTextsInput) (map[int]Text, error) {
+	if err := authorize(ctx, entity.PermReadSongs); err != nil {
+		return nil, err
+	}
+	return s.next.GetTexts(ctx, input)
+}
+
+func (s *songAccess) Update(ctx context.Context, songId int, input UpdateSongInput) error {
+	if err := authorize(ctx, entity.PermEditSongs); err != nil {
+		return err
+	}
+	return s.next.Update(ctx, songId, input)
+}
+
+func (s *songAccess) UpdateText(ctx context.Context, songId int, text string) error {
+	if err := authorize(ctx, entity.PermEditLyrics); err != nil {
+		return err
+	}
+	return s.next.UpdateText(ctx, songId, text)
+}
+
+func (s *songAccess) Delete(ctx context.Context, songId int) error {
+	if err := authorize(ctx, entity.PermDeleteSongs); err != nil {
+		return err
+	}
+	return s.next.Delete(ctx, songId)
+}
+
+func (s *songAccess) ExportLRC(ctx context.Context, songId int) (string, error) {
+	if err := authorize(ctx, entity.PermReadSongs); err != nil {
+		return "", err
+	}
+	return s.next.ExportLRC(ctx, songId)
+}
+
+func (s *songAccess) ImportLRC(ctx context.Context, songId int, data string) error {
+	if err := authorize(ctx, entity.PermEditLyrics); err != nil {
+		return err
+	}
+	return s.next.ImportLRC(ctx, songId, data)
+}
+
+func (s *songAccess) GetActiveLine(ctx context.Context, songId int, position time.Duration) (entity.SyncedLine, error) {
+	if err := authorize(ctx, entity.PermReadSongs); err != nil {
+		return entity.SyncedLine{}, err
+	}
+	return s.next.GetActiveLine(ctx, songId, position)
+}
+
+func (s *songAccess) PutTranslation(ctx context.Context, input PutTranslationInput) error {
+	if err := authorize(ctx, entity.PermEditLyrics); err != nil {
+		return err
+	}
+	return s.next.PutTranslation(ctx, input)
+}
+
+func (s *songAccess) ListTranslations(ctx context.Context, songId int) ([]entity.TranslationInfo, error) {
+	if err := authorize(ctx, entity.PermReadSongs); err != nil {
+		return nil, err
+	}
+	return s.next.ListTranslations(ctx, songId)
+}
+
+func (s *songAccess) GetSideBySide(ctx context.Context, input GetTextInput) ([]entity.AlignedCouplet, int, error) {
+	if err := authorize(ctx, entity.PermReadSongs); err != nil {
+		return nil, 0, err
+	}
+	return s.next.GetSideBySide(ctx, input)
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/tenant"
+	"github.com/spanwalla/song-library/pkg/cache"
+)
+
+// songCache отдаёт песни и страницы текста из кэша, а после изменения песни удаляет её записи.
+// Промах читается через primary с основного сервера: реплика может ещё не получить изменение,
+// из-за которого запись удалили. Записи удаляются после фиксации транзакции, поэтому чтение,
+// начатое до фиксации, может положить в кэш старые данные. Такие записи живут не дольше ttl.
+type songCache struct {
+	next    Song
+	cache   cache.Cache
+	ttl     time.Duration
+	primary func(ctx context.Context) context.Context
+}
+
+func newSongCache(next Song, c cache.Cache, ttl time.Duration, primary func(ctx context.Context) context.Context) *songCache {
+	if primary == nil {
+		primary = func(ctx context.Context) context.Context { return ctx }
+	}
+	return &songCache{next: next, cache: c, ttl: ttl, primary: primary}
+}
+
+// songKey — ключ песни. Идентификаторы песен уникальны и между арендаторами, но арендатор
+// входит в ключ, чтобы песня другого арендатора не попала в ответ из кэша.
+func songKey(ctx context.Context, songId int) string {
+	return fmt.Sprintf("song:%d:%d", tenant.Id(ctx), songId)
+}
+
+// textVersionKey — ключ версии текста песни. Страницы текста хранятся под ключами с версией,
+// поэтому при изменении достаточно удалить версию, не перечисляя все страницы.
+func textVersionKey(ctx context.Context, songId int) string {
+	return fmt.Sprintf("text:%d:%d", tenant.Id(ctx), songId)
+}
+
+func (s *songCache) get(ctx context.Context, key string, v any) bool {
+	data, ok := s.cache.Get(ctx, key)
+	return ok && json.Unmarshal(data, v) == nil
+}
+
+func (s *songCache) set(ctx context.Context, key string, v any) {
+	if data, err := json.Marshal(v); err == nil {
+		s.cache.Set(ctx, key, data, s.ttl)
+	}
+}
+
+// invalidate не зависит от отмены запроса: изменение уже могло быть зафиксировано.
+func (s *songCache) invalidate(ctx context.Context, songId int) {
+	s.cache.Delete(context.WithoutCancel(ctx), songKey(ctx, songId), textVersionKey(ctx, songId))
+}
+
+// textVersion возвращает текущую версию текста песни или создаёт новую.
+func (s *songCache) textVersion(ctx context.Context, songId int) string {
+	key := textVersionKey(ctx, songId)
+	if version, ok := s.cache.Get(ctx, key); ok {
+		return string(version)
+	}
+
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	version := hex.EncodeToString(b)
+	s.cache.Set(ctx, key, []byte(version), s.ttl)
+
+	return version
+}
+
+func (s *songCache) Get(ctx context.Context, songId int) (entity.Song, error) {
+	key := songKey(ctx, songId)
+
+	var song entity.Song
+	if s.get(ctx, key, &song) {
+		return song, nil
+	}
+
+	song, err := s.next.Get(s.primary(ctx), songId)
+	if err != nil {
+		return entity.Song{}, err
+	}
+
+	s.set(ctx, key, song)
+	return song, nil
+}
+
+// GetText кэширует только оригинальный текст: переводы меняются отдельно от песни.
+func (s *songCache) GetText(ctx context.Context, input GetTextInput) ([]string, int, error) {
+	if len(input.Language) > 0 {
+		return s.next.GetText(ctx, input)
+	}
+
+	key := fmt.Sprintf("%s:%s:%d:%d", textVersionKey(ctx, input.SongId), s.textVersion(ctx, input.SongId), input.Offset, input.Limit)
+
+	var text Text
+	if s.get(ctx, key, &text) {
+		return text.Couplets, text.Count, nil
+	}
+
+	couplets, count, err := s.next.GetText(s.primary(ctx), input)
+	if err != nil {
+		return couplets, count, err
+	}
+
+	s.set(ctx, key, Text{Couplets: couplets, Count: count})
+	return couplets, count, nil
+}
+
+func (s *songCache) Update(ctx context.Context, songId int, input UpdateSongInput) error {
+	defer s.invalidate(ctx, songId)
+	return s.next.Update(ctx, songId, input)
+}
+
+func (s *songCache) UpdateText(ctx context.Context, songId int, text string) error {
+	defer s.invalidate(ctx, songId)
+	return s.next.UpdateText(ctx, songId, text)
+}
+
+func (s *songCache) Delete(ctx context.Context, songId int) error {
+	defer s.invalidate(ctx, songId)
+	return s.next.Delete(ctx, songId)
+}
+
+func (s *songCache) ImportLRC(ctx context.Context, songId int, data string) error {
+	defer s.invalidate(ctx, songId)
+	return s.next.ImportLRC(ctx, songId, data)
+}
+
+func (s *songCache) Insert(ctx context.Context, input InsertSongInput) error {
+	return s.next.Insert(ctx, input)
+}
+
+func (s *songCache) Import(ctx context.Context, inputs []InsertSongInput) ([]int, error) {
+	return s.next.Import(ctx, inputs)
+}
+
+func (s *songCache) Search(ctx context.Context, input SearchSongInput) ([]entity.Song, error) {
+	return s.next.Search(ctx, input)
+}
+
+func (s *songCache) GetTexts(ctx context.Context, input GetTextsInput) (map[int]Text, error) {
+	return s.next.GetTexts(ctx, input)
+}
+
+func (s *songCache) ExportLRC(ctx context.Context, songId int) (string, error) {
+	return s.next.ExportLRC(ctx, songId)
+}
+
+func (s *songCache) GetActiveLine(ctx context.Context, songId int, position time.Duration) (entity.SyncedLine, error) {
+	return s.next.GetActiveLine(ctx, songId, position)
+}
+
+func (s *songCache) PutTranslation(ctx context.Context, input PutTranslationInput) error {
+	return s.next.PutTranslation(ctx, input)
+}
+
+func (s *songCache) ListTranslations(ctx context.Context, songId int) ([]entity.TranslationInfo, error) {
+	return s.next.ListTranslations(ctx, songId)
+}
+
+func (s *songCache) GetSideBySide(ctx context.Context, input GetTextInput) ([]entity.AlignedCouplet, int, error) {
+	return s.next.GetSideBySide(ctx, input)
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/spanwalla/song-library/internal/entity"
+)
+
+const tracerName = "github.com/spanwalla/song-library/internal/service"
+
+// songTracing оборачивает каждый метод сервиса песен в span, чтобы в трассе было видно,
+// сколько времени заняла бизнес-логика относительно запросов к БД и внешнему API.
+type songTracing struct {
+	next   Song
+	tracer trace.Tracer
+}
+
+func newSongTracing(next Song) *songTracing {
+	return &songTracing{next: next, tracer: otel.Tracer(tracerName)}
+}
+
+func (s *songTracing) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, "SongService."+method, trace.WithAttributes(attrs...))
+}
+
+func finish(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func songIdAttr(songId int) attribute.KeyValue {
+	return attribute.Int("song.id", songId)
+}
+
+func (s *songTracing) Insert(ctx context.Context, input InsertSongInput) (err error) {
+	ctx, span := s.start(ctx, "Insert", attribute.String("song.group", input.Group), attribute.String("song.name", input.Song))
+	defer func() { finish(span, err) }()
+	return s.next.Insert(ctx, input)
+}
+
+func (s *songTracing) Import(ctx context.Context, inputs []InsertSongInput) (ids []int, err error) {
+	ctx, span := s.start(ctx, "Import", attribute.Int("songs.count", len(inputs)))
+	defer func() { finish(span, err) }()
+	return s.next.Import(ctx, inputs)
+}
+
+func (s *songTracing) Search(ctx context.Context, input SearchSongInput) (songs []entity.Song, err error) {
+	ctx, span := s.start(ctx, "Search", attribute.Int("offset", input.Offset), attribute.Int("limit", input.Limit))
+	defer func() { finish(span, err) }()
+	return s.next.Search(ctx, input)
+}
+
+func (s *songTracing) Get(ctx context.Context, songId int) (song entity.Song, err error) {
+	ctx, span := s.start(ctx, "Get", songIdAttr(songId))
+	defer func() { finish(span, err) }()
+	return s.next.Get(ctx, songId)
+}
+
+func (s *songTracing) GetText(ctx context.Context, input GetTextInput) (couplets []string, count int, err error) {
+	ctx, span := s.start(ctx, "GetText", songIdAttr(input.SongId), attribute.String("lang", input.Language))
+	defer func() { finish(span, err) }()
+	return s.next.GetText(ctx, input)
+}
+
+func (s *songTracing) GetTexts(ctx context.Context, input GetTextsInput) (texts map[int]Text, err error) {
+	ctx, span := s.start(ctx, "GetTexts", attribute.IntSlice("song.ids", input.SongIds))
+	defer func() { finish(span, err) }()
+	return s.next.GetTexts(ctx, input)
+}
+
+func (s *songTracing) Update(ctx context.Context, songId int, input UpdateSongInput) (err error) {
+	ctx, span := s.start(ctx, "Update", songIdAttr(songId))
+	defer func() { finish(span, err) }()
+	return s.next.Update(ctx, songId, input)
+}
+
+func (s *songTracing) UpdateText(ctx context.Context, songId int, text string) (err error) {
+	ctx, span := s.start(ctx, "UpdateText", songIdAttr(songId))
+	defer func() { finish(span, err) }()
+	return s.next.UpdateText(ctx, songId, text)
+}
+
+func (s *songTracing) Delete(ctx context.Context, songId int) (err error) {
+	ctx, span := s.start(ctx, "Delete", songIdAttr(songId))
+	defer func() { finish(span, err) }()
+	return s.next.Delete(ctx, songId)
+}
+
+func (s *songTracing) ExportLRC(ctx context.Context, songId int) (data string, err error) {
+	ctx, span := s.start(ctx, "ExportLRC", songIdAttr(songId))
+	defer func() { finish(span, err) }()
+	return s.next.ExportLRC(ctx, songId)
+}
+
+func (s *songTracing) ImportLRC(ctx context.Context, songId int, data string) (err error) {
+	ctx, span := s.start(ctx, "ImportLRC", songIdAttr(songId))
+	defer func() { finish(span, err) }()
+	return s.next.ImportLRC(ctx, songId, data)
+}
+
+func (s *songTracing) GetActiveLine(ctx context.Context, songId int, position time.Duration) (line entity.SyncedLine, err error) {
+	ctx, span := s.start(ctx, "GetActiveLine", songIdAttr(songId), attribute.Int64("position_ms", position.Milliseconds()))
+	defer func() { finish(span, err) }()
+	return s.next.GetActiveLine(ctx, songId, position)
+}
+
+func (s *songTracing) PutTranslation(ctx context.Context, input PutTranslationInput) (err error) {
+	ctx, span := s.start(ctx, "PutTranslation", songIdAttr(input.SongId), attribute.String("lang", input.Language))
+	defer func() { finish(span, err) }()
+	return s.next.PutTranslation(ctx, input)
+}
+
+func (s *songTracing) ListTranslations(ctx context.Context, songId int) (translations []entity.TranslationInfo, err error) {
+	ctx, span := s.start(ctx, "ListTranslations", songIdAttr(songId))
+	defer func() { finish(span, err) }()
+	return s.next.ListTranslations(ctx, songId)
+}
+
+func (s *songTracing) GetSideBySide(ctx context.Context, input GetTextInput) (couplets []entity.AlignedCouplet, count int, err error) {
+	ctx, span := s.start(ctx, "GetSideBySide", songIdAttr(input.SongId), attribute.String("lang", input.Language))
+	defer func() { finish(span, err) }()
+	return s.next.GetSideBySide(ctx, input)
+}
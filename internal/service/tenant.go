@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/repository"
+	"github.com/spanwalla/song-library/pkg/logger"
+)
+
+// TenantService находит арендаторов по slug и идентификатору. Арендатор нужен почти
+// каждому запросу, а меняется редко, поэтому найденные арендаторы запоминаются.
+// Отсутствие арендатора не запоминается: его могли создать в songctl уже после запроса.
+type TenantService struct {
+	tenantRepo repository.Tenant
+
+	mu     sync.RWMutex
+	byId   map[int]entity.Tenant
+	bySlug map[string]entity.Tenant
+}
+
+func NewTenantService(tenantRepo repository.Tenant) *TenantService {
+	return &TenantService{
+		tenantRepo: tenantRepo,
+		byId:       make(map[int]entity.Tenant),
+		bySlug:     make(map[string]entity.Tenant),
+	}
+}
+
+func (s *TenantService) Resolve(ctx context.Context, slug string) (entity.Tenant, error) {
+	s.mu.RLock()
+	t, ok := s.bySlug[slug]
+	s.mu.RUnlock()
+	if ok {
+		return t, nil
+	}
+
+	if !entity.ValidTenantSlug(slug) {
+		return entity.Tenant{}, ErrTenantNotFound
+	}
+
+	t, err := s.tenantRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return entity.Tenant{}, ErrTenantNotFound
+		}
+		logger.From(ctx).Errorf("TenantService.Resolve - s.tenantRepo.GetBySlug: %v", err)
+		return entity.Tenant{}, ErrCannotGetTenant
+	}
+
+	s.remember(t)
+	return t, nil
+}
+
+func (s *TenantService) Get(ctx context.Context, tenantId int) (entity.Tenant, error) {
+	s.mu.RLock()
+	t, ok := s.byId[tenantId]
+	s.mu.RUnlock()
+	if ok {
+		return t, nil
+	}
+
+	t, err := s.tenantRepo.GetById(ctx, tenantId)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return entity.Tenant{}, ErrTenantNotFound
+		}
+		logger.From(ctx).Errorf("TenantService.Get - s.tenantRepo.GetById: %v", err)
+		return entity.Tenant{}, ErrCannotGetTenant
+	}
+
+	s.remember(t)
+	return t, nil
+}
+
+func (s *TenantService) Create(ctx context.Context, input CreateTenantInput) (entity.Tenant, error) {
+	if !entity.ValidTenantSlug(input.Slug) {
+		return entity.Tenant{}, ErrInvalidTenantSlug
+	}
+
+	t, err := s.tenantRepo.Insert(ctx, entity.Tenant{Slug: input.Slug, Name: input.Name})
+	if err != nil {
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return entity.Tenant{}, ErrTenantAlreadyExists
+		}
+		logger.From(ctx).Errorf("TenantService.Create - s.tenantRepo.Insert: %v", err)
+		return entity.Tenant{}, ErrCannotCreateTenant
+	}
+
+	s.remember(t)
+	return t, nil
+}
+
+func (s *TenantService) List(ctx context.Context) ([]entity.Tenant, error) {
+	tenants, err := s.tenantRepo.List(ctx)
+	if err != nil {
+		logger.From(ctx).Errorf("TenantService.List - s.tenantRepo.List: %v", err)
+		return nil, ErrCannotGetTenant
+	}
+
+	return tenants, nil
+}
+
+func (s *TenantService) remember(t entity.Tenant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.byId[t.Id] = t
+	s.bySlug[t.Slug] = t
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/repository"
+	"github.com/spanwalla/song-library/pkg/logger"
+)
+
+// PutTranslation заменяет перевод песни на язык. Число куплетов проверяется под блокировкой
+// песни, поэтому одновременная замена текста не может изменить его до записи перевода.
+func (s *SongService) PutTranslation(ctx context.Context, input PutTranslationInput) error {
+	pieces := strings.Split(input.Text, "\n\n")
+
+	translations := make([]entity.Translation, 0, len(pieces))
+	for i, piece := range pieces {
+		translations = append(translations, entity.Translation{
+			SongId:         input.SongId,
+			Language:       input.Language,
+			SequenceNumber: i + 1,
+			Text:           piece,
+		})
+	}
+
+	return s.transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		_, err := s.songRepo.GetByIdForUpdate(txCtx, input.SongId)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrSongNotFound
+			}
+			logger.From(ctx).Errorf("SongService.PutTranslation - s.songRepo.GetByIdForUpdate: %v", err)
+			return ErrCannotPutTranslation
+		}
+
+		count, err := s.coupletRepo.GetCoupletsCount(txCtx, input.SongId)
+		if err != nil {
+			logger.From(ctx).Errorf("SongService.PutTranslation - s.coupletRepo.GetCoupletsCount: %v", err)
+			return ErrCannotPutTranslation
+		}
+
+		if count == 0 {
+			return ErrSongNotFound
+		}
+		if len(pieces) != count {
+			return ErrTranslationMismatch
+		}
+
+		err = s.translationRepo.DeleteBySongId(txCtx, input.SongId, input.Language)
+		if err != nil {
+			logger.From(ctx).Errorf("SongService.PutTranslation - s.translationRepo.DeleteBySongId: %v", err)
+			return ErrCannotPutTranslation
+		}
+
+		err = s.translationRepo.Insert(txCtx, translations)
+		if err != nil {
+			logger.From(ctx).Errorf("SongService.PutTranslation - s.translationRepo.Insert: %v", err)
+			return ErrCannotPutTranslation
+		}
+
+		return nil
+	})
+}
+
+func (s *SongService) ListTranslations(ctx context.Context, songId int) ([]entity.TranslationInfo, error) {
+	infos, err := s.translationRepo.GetLanguages(ctx, songId)
+	if err != nil {
+		logger.From(ctx).Errorf("SongService.ListTranslations - s.translationRepo.GetLanguages: %v", err)
+		return []entity.TranslationInfo{}, ErrCannotGetTranslation
+	}
+
+	return infos, nil
+}
+
+func (s *SongService) GetSideBySide(ctx context.Context, input GetTextInput) ([]entity.AlignedCouplet, int, error) {
+	count, err := s.coupletRepo.GetCoupletsCount(ctx, input.SongId)
+	if err != nil {
+		logger.From(ctx).Errorf("SongService.GetSideBySide - s.coupletRepo.GetCoupletsCount: %v", err)
+		return []entity.AlignedCouplet{}, 0, ErrCannotGetText
+	}
+
+	if count == 0 {
+		return []entity.AlignedCouplet{}, 0, ErrSongNotFound
+	}
+
+	couplets, err := s.coupletRepo.GetBySongId(ctx, input.SongId, input.Offset, input.Limit)
+	if err != nil {
+		logger.From(ctx).Errorf("SongService.GetSideBySide - s.coupletRepo.GetBySongId: %v", err)
+		return []entity.AlignedCouplet{}, 0, ErrCannotGetText
+	}
+
+	translations, err := s.translationRepo.GetBySongId(ctx, input.SongId, input.Language, input.Offset, input.Limit)
+	if err != nil {
+		logger.From(ctx).Errorf("SongService.GetSideBySide - s.translationRepo.GetBySongId: %v", err)
+		return []entity.AlignedCouplet{}, 0, ErrCannotGetTranslation
+	}
+
+	if len(translations) == 0 && len(couplets) > 0 {
+		return []entity.AlignedCouplet{}, 0, ErrTranslationNotFound
+	}
+
+	translated := make(map[int]string, len(translations))
+	for _, translation := range translations {
+		translated[translation.SequenceNumber] = translation.Text
+	}
+
+	aligned := make([]entity.AlignedCouplet, 0, len(couplets))
+	for _, couplet := range couplets {
+		aligned = append(aligned, entity.AlignedCouplet{
+			SequenceNumber: couplet.SequenceNumber,
+			Original:       couplet.Text,
+			Translation:    translated[couplet.SequenceNumber],
+		})
+	}
+
+	return aligned, count, nil
+}
+
+func (s *SongService) getTranslationText(ctx context.Context, input GetTextInput) ([]string, int, error) {
+	count, err := s.translationRepo.GetCount(ctx, input.SongId, input.Language)
+	if err != nil {
+		logger.From(ctx).Errorf("SongService.GetText - s.translationRepo.GetCount: %v", err)
+		return []string{}, 0, ErrCannotGetTranslation
+	}
+
+	if count == 0 {
+		return []string{}, 0, ErrTranslationNotFound
+	}
+
+	translations, err := s.translationRepo.GetBySongId(ctx, input.SongId, input.Language, input.Offset, input.Limit)
+	if err != nil {
+		logger.From(ctx).Errorf("SongService.GetText - s.translationRepo.GetBySongId: %v", err)
+		return []string{}, 0, ErrCannotGetTranslation
+	}
+
+	text := make([]string, 0)
+	for _, translation := range translations {
+		text = append(text, translation.Text)
+	}
+
+	return text, count, nil
+}
@@ -0,0 +1,417 @@
+package songctl
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/service"
+	"github.com/spanwalla/song-library/pkg/query"
+)
+
+// pageSize совпадает с максимальным размером страницы в репозиториях.
+const pageSize = 10
+
+// parseFlags разбирает флаги команды и проверяет формат вывода.
+func parseFlags(fs *flag.FlagSet, output *string, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := checkFormat(*output); err != nil {
+		fs.Usage()
+		return err
+	}
+	return nil
+}
+
+func runSearch(ctx context.Context, e *env, args []string) error {
+	fs, output := e.newFlagSet("search", "")
+	var filters, orderBy multiFlag
+	fs.Var(&filters, "filter", "exact match filter field=value, may be repeated (fields: id, song, group, link, releaseDate)")
+	fs.Var(&orderBy, "order", "sort field[:asc|desc], may be repeated")
+	offset := fs.Int("offset", 0, "number of songs to skip")
+	limit := fs.Int("limit", pageSize, fmt.Sprintf("number of songs to show, at most %d", pageSize))
+	all := fs.Bool("all", false, "show all matching songs, ignoring -limit")
+	if err := parseFlags(fs, output, args); err != nil {
+		return err
+	}
+
+	input := service.SearchSongInput{Filters: make(map[string]string), Offset: *offset, Limit: *limit}
+	for _, filter := range filters {
+		field, value, ok := strings.Cut(filter, "=")
+		if !ok {
+			fs.Usage()
+			return fmt.Errorf("%w: filter %q must be field=value", errUsage, filter)
+		}
+		input.Filters[field] = value
+	}
+	for _, criteria := range orderBy {
+		field, order, _ := strings.Cut(criteria, ":")
+		if len(order) == 0 {
+			order = query.AscendingSortOrder
+		}
+		input.OrderBy = append(input.OrderBy, []string{field, order})
+	}
+
+	ctx, err := e.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !*all {
+		songs, err := e.songs.Search(ctx, input)
+		if err != nil {
+			return err
+		}
+		return writeSongs(e.stdout, *output, songs)
+	}
+
+	var songs []entity.Song
+	input.Limit = pageSize
+	for {
+		page, err := e.songs.Search(ctx, input)
+		if err != nil {
+			return err
+		}
+		songs = append(songs, page...)
+		if len(page) < pageSize {
+			break
+		}
+		input.Offset += len(page)
+	}
+
+	return writeSongs(e.stdout, *output, songs)
+}
+
+func runGet(ctx context.Context, e *env, args []string) error {
+	fs, output := e.newFlagSet("get", "<id>...")
+	if err := parseFlags(fs, output, args); err != nil {
+		return err
+	}
+
+	ids, err := parseIds(fs.Args())
+	if err == nil && len(ids) == 0 {
+		err = errUsage
+	}
+	if err != nil {
+		fs.Usage()
+		return err
+	}
+
+	if ctx, err = e.connect(ctx); err != nil {
+		return err
+	}
+
+	songs := make([]entity.Song, 0, len(ids))
+	for _, id := range ids {
+		song, err := e.songs.Get(ctx, id)
+		if err != nil {
+			return fmt.Errorf("song %d: %w", id, err)
+		}
+		songs = append(songs, song)
+	}
+
+	return writeSongs(e.stdout, *output, songs)
+}
+
+type textOutput struct {
+	Id       int      `json:"id"`
+	Lang     string   `json:"lang,omitempty"`
+	Count    int      `json:"count"`
+	Couplets []string `json:"couplets"`
+}
+
+func runText(ctx context.Context, e *env, args []string) error {
+	fs, output := e.newFlagSet("text", "<id>")
+	lang := fs.String("lang", "", "show the translation instead of the original")
+	if err := parseFlags(fs, output, args); err != nil {
+		return err
+	}
+
+	ids, err := parseIds(fs.Args())
+	if err == nil && len(ids) != 1 {
+		err = errUsage
+	}
+	if err != nil {
+		fs.Usage()
+		return err
+	}
+
+	if ctx, err = e.connect(ctx); err != nil {
+		return err
+	}
+
+	text := textOutput{Id: ids[0], Lang: *lang, Couplets: []string{}}
+	for offset := 0; ; offset += pageSize {
+		couplets, count, err := e.songs.GetText(ctx, service.GetTextInput{
+			SongId:   text.Id,
+			Language: text.Lang,
+			Offset:   offset,
+			Limit:    pageSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		text.Count = count
+		text.Couplets = append(text.Couplets, couplets...)
+		if len(couplets) == 0 || len(text.Couplets) >= count {
+			break
+		}
+	}
+
+	if *output == formatJSON {
+		return writeJSON(e.stdout, text)
+	}
+
+	// Куплеты разделяются пустой строкой, как при вводе текста, поэтому вывод
+	// можно отредактировать и передать обратно в set-text.
+	_, err = fmt.Fprintln(e.stdout, strings.Join(text.Couplets, "\n\n"))
+	return err
+}
+
+type setTextInput struct {
+	Id   int    `json:"id" validate:"number,gt=0"`
+	Text string `json:"text" validate:"required"`
+}
+
+func runSetText(ctx context.Context, e *env, args []string) error {
+	fs, output := e.newFlagSet("set-text", "<id>")
+	file := fs.String("f", stdinName, `file with the text, couplets separated by an empty line ("-" for stdin)`)
+	if err := parseFlags(fs, output, args); err != nil {
+		return err
+	}
+
+	ids, err := parseIds(fs.Args())
+	if err == nil && len(ids) != 1 {
+		err = errUsage
+	}
+	if err != nil {
+		fs.Usage()
+		return err
+	}
+
+	data, err := e.readInput(*file)
+	if err != nil {
+		return err
+	}
+
+	input := setTextInput{Id: ids[0], Text: strings.TrimSpace(strings.ReplaceAll(string(data), "\r\n", "\n"))}
+	if err = e.validator.Validate(input); err != nil {
+		return err
+	}
+
+	if ctx, err = e.connect(ctx); err != nil {
+		return err
+	}
+
+	// Без проверки UpdateText молча создал бы куплеты несуществующей песни.
+	if _, err = e.songs.Get(ctx, input.Id); err != nil {
+		return err
+	}
+
+	r := newResult(e.songs.UpdateText(ctx, input.Id, input.Text))
+	r.Id = input.Id
+	return writeResults(e.stdout, *output, []result{r})
+}
+
+// insertInput описывает песню для добавления. Поля link, releaseDate и text
+// используются только с -no-lookup.
+type insertInput struct {
+	Group       string `json:"group" validate:"required,max=128"`
+	Song        string `json:"song" validate:"required,max=128"`
+	Link        string `json:"link" validate:"omitempty,max=128,uri"`
+	ReleaseDate string `json:"releaseDate" validate:"omitempty,date"`
+	Text        string `json:"text"`
+}
+
+func runInsert(ctx context.Context, e *env, args []string) error {
+	fs, output := e.newFlagSet("insert", "")
+	var single insertInput
+	fs.StringVar(&single.Group, "group", "", "group name")
+	fs.StringVar(&single.Song, "song", "", "song name")
+	fs.StringVar(&single.Link, "link", "", "link, with -no-lookup")
+	fs.StringVar(&single.ReleaseDate, "release-date", "", "release date 2006-01-02, with -no-lookup")
+	textFile := fs.String("text-file", "", `file with the text, with -no-lookup ("-" for stdin)`)
+	file := fs.String("f", "", `JSON file with an array of songs {group, song, link, releaseDate, text} ("-" for stdin)`)
+	noLookup := fs.Bool("no-lookup", false, "do not query the external API, take link, release date and text from the input")
+	bulk := fs.Bool("bulk", false, "insert all songs in one transaction using COPY, nothing is inserted if any song fails")
+	if err := parseFlags(fs, output, args); err != nil {
+		return err
+	}
+
+	var inputs []insertInput
+	switch {
+	case len(*file) > 0:
+		data, err := e.readInput(*file)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(data, &inputs); err != nil {
+			return fmt.Errorf("parse %s: %w", *file, err)
+		}
+	case len(single.Group) > 0 || len(single.Song) > 0:
+		if len(*textFile) > 0 {
+			data, err := e.readInput(*textFile)
+			if err != nil {
+				return err
+			}
+			single.Text = strings.TrimSpace(string(data))
+		}
+		inputs = []insertInput{single}
+	default:
+		fs.Usage()
+		return errUsage
+	}
+
+	info := make(manualSongInfo)
+	for _, input := range inputs {
+		if err := e.validator.Validate(input); err != nil {
+			return fmt.Errorf("%s - %s: %w", input.Group, input.Song, err)
+		}
+		if !*noLookup {
+			continue
+		}
+		if err := info.add(input.Group, input.Song, input.Link, input.ReleaseDate, input.Text); err != nil {
+			return fmt.Errorf("%s - %s: %w", input.Group, input.Song, err)
+		}
+	}
+
+	ctx, err := e.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	songs := e.songs
+	if *noLookup {
+		songs = e.newSongService(info)
+	}
+
+	if *bulk {
+		return writeResults(e.stdout, *output, importSongs(ctx, songs, inputs))
+	}
+
+	results := make([]result, 0, len(inputs))
+	for _, input := range inputs {
+		r := newResult(songs.Insert(ctx, service.InsertSongInput{Group: input.Group, Song: input.Song}))
+		r.Group, r.Song = input.Group, input.Song
+		results = append(results, r)
+	}
+
+	return writeResults(e.stdout, *output, results)
+}
+
+// importSongs добавляет песни одним импортом. Импорт либо удаётся целиком,
+// либо не добавляет ничего, поэтому ошибка выводится для каждой песни.
+func importSongs(ctx context.Context, songs service.Song, inputs []insertInput) []result {
+	songInputs := make([]service.InsertSongInput, 0, len(inputs))
+	for _, input := range inputs {
+		songInputs = append(songInputs, service.InsertSongInput{Group: input.Group, Song: input.Song})
+	}
+
+	ids, err := songs.Import(ctx, songInputs)
+
+	results := make([]result, 0, len(inputs))
+	for i, input := range inputs {
+		r := newResult(err)
+		if err == nil {
+			r.Id = ids[i]
+		}
+		r.Group, r.Song = input.Group, input.Song
+		results = append(results, r)
+	}
+
+	return results
+}
+
+type updateInput struct {
+	Id          int     `json:"id" validate:"number,gt=0"`
+	Group       *string `json:"group" validate:"omitempty,max=128"`
+	Song        *string `json:"song" validate:"omitempty,max=128"`
+	Link        *string `json:"link" validate:"omitempty,max=128,uri"`
+	ReleaseDate *string `json:"releaseDate" validate:"omitempty,date"`
+}
+
+func runUpdate(ctx context.Context, e *env, args []string) error {
+	fs, output := e.newFlagSet("update", "<id>")
+	var group, song, link, releaseDate optionalFlag
+	fs.Var(&group, "group", "new group name")
+	fs.Var(&song, "song", "new song name")
+	fs.Var(&link, "link", "new link")
+	fs.Var(&releaseDate, "release-date", "new release date 2006-01-02")
+	if err := parseFlags(fs, output, args); err != nil {
+		return err
+	}
+
+	ids, err := parseIds(fs.Args())
+	if err == nil && len(ids) != 1 {
+		err = errUsage
+	}
+	if err != nil {
+		fs.Usage()
+		return err
+	}
+
+	input := updateInput{Id: ids[0], Group: group.value, Song: song.value, Link: link.value, ReleaseDate: releaseDate.value}
+	if err = e.validator.Validate(input); err != nil {
+		return err
+	}
+
+	if ctx, err = e.connect(ctx); err != nil {
+		return err
+	}
+
+	if _, err = e.songs.Get(ctx, input.Id); err != nil {
+		return err
+	}
+
+	r := newResult(e.songs.Update(ctx, input.Id, service.UpdateSongInput{
+		Name:        input.Song,
+		Group:       input.Group,
+		Link:        input.Link,
+		ReleaseDate: input.ReleaseDate,
+	}))
+	r.Id = input.Id
+	return writeResults(e.stdout, *output, []result{r})
+}
+
+func runDelete(ctx context.Context, e *env, args []string) error {
+	fs, output := e.newFlagSet("delete", "[<id>...]")
+	file := fs.String("f", "", `file with song ids, one or more per line ("-" for stdin)`)
+	if err := parseFlags(fs, output, args); err != nil {
+		return err
+	}
+
+	ids, err := parseIds(fs.Args())
+	if err != nil {
+		fs.Usage()
+		return err
+	}
+	if len(*file) > 0 {
+		fromFile, err := e.readIds(*file)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, fromFile...)
+	}
+	if len(ids) == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	if ctx, err = e.connect(ctx); err != nil {
+		return err
+	}
+
+	results := make([]result, 0, len(ids))
+	for _, id := range ids {
+		r := newResult(e.songs.Delete(ctx, id))
+		r.Id = id
+		results = append(results, r)
+	}
+
+	return writeResults(e.stdout, *output, results)
+}
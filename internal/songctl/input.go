@@ -0,0 +1,82 @@
+package songctl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const stdinName = "-"
+
+// readInput читает файл целиком, "-" означает стандартный ввод.
+func (e *env) readInput(name string) ([]byte, error) {
+	if name == stdinName {
+		return io.ReadAll(e.stdin)
+	}
+	return os.ReadFile(name)
+}
+
+// parseIds разбирает идентификаторы песен. Во входных данных они разделяются пробелами
+// или переводами строк, строки, начинающиеся с #, пропускаются.
+func parseIds(values []string) ([]int, error) {
+	ids := make([]int, 0, len(values))
+	for _, value := range values {
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("%w: invalid song id %q", errUsage, value)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (e *env) readIds(name string) ([]int, error) {
+	data, err := e.readInput(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []string
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		values = append(values, strings.Fields(line)...)
+	}
+
+	return parseIds(values)
+}
+
+// multiFlag собирает значения флага, указанного несколько раз.
+type multiFlag []string
+
+func (f *multiFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *multiFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// optionalFlag запоминает, был ли флаг указан, чтобы отличить пустое значение от отсутствующего.
+type optionalFlag struct {
+	value *string
+}
+
+func (f *optionalFlag) String() string {
+	if f.value == nil {
+		return ""
+	}
+	return *f.value
+}
+
+func (f *optionalFlag) Set(value string) error {
+	f.value = &value
+	return nil
+}
@@ -0,0 +1,178 @@
+package songctl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/service"
+)
+
+const keysUsage = `usage: songctl keys <command> [flags] [args]
+
+commands:
+  issue     create a key and print its secret once
+  list      show all keys with scopes, expiry and revocation time
+  revoke    revoke keys by id`
+
+// runKeys управляет API-ключами. Первый ключ с уровнем admin можно выпустить только здесь:
+// эндпоинты /api/v1/keys сами требуют такого ключа.
+func runKeys(ctx context.Context, e *env, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintln(e.stderr, keysUsage)
+		return errUsage
+	}
+
+	switch args[0] {
+	case "issue":
+		return runKeysIssue(ctx, e, args[1:])
+	case "list":
+		return runKeysList(ctx, e, args[1:])
+	case "revoke":
+		return runKeysRevoke(ctx, e, args[1:])
+	default:
+		fmt.Fprintln(e.stderr, keysUsage)
+		return fmt.Errorf("%w: unknown keys command %q", errUsage, args[0])
+	}
+}
+
+type issueKeyInput struct {
+	Name   string   `json:"name" validate:"required,max=128"`
+	Scopes []string `json:"scope" validate:"required,min=1,dive,oneof=read write admin"`
+}
+
+type issuedKeyOutput struct {
+	entity.APIKey
+	Key string `json:"key"`
+}
+
+func runKeysIssue(ctx context.Context, e *env, args []string) error {
+	fs, output := e.newFlagSet("keys issue", "")
+	name := fs.String("name", "", "key name, e.g. the client that uses it")
+	var scopes multiFlag
+	fs.Var(&scopes, "scope", "scope: read, write or admin, may be repeated")
+	ttl := fs.Duration("ttl", 0, "key lifetime, e.g. 720h; 0 means the key does not expire")
+	if err := parseFlags(fs, output, args); err != nil {
+		return err
+	}
+
+	if err := e.validator.Validate(issueKeyInput{Name: *name, Scopes: scopes}); err != nil {
+		return err
+	}
+
+	input := service.IssueKeyInput{Name: *name}
+	for _, scope := range scopes {
+		input.Scopes = append(input.Scopes, entity.Scope(scope))
+	}
+	if *ttl > 0 {
+		expiresAt := time.Now().Add(*ttl)
+		input.ExpiresAt = &expiresAt
+	}
+
+	ctx, err := e.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	key, err := e.auth.IssueKey(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	if *output == formatJSON {
+		return writeJSON(e.stdout, issuedKeyOutput{APIKey: key.APIKey, Key: key.Secret})
+	}
+
+	fmt.Fprintf(e.stdout, "id:      %d\nname:    %s\nscopes:  %s\nexpires: %s\nkey:     %s\n",
+		key.Id, key.Name, joinScopes(key.Scopes), formatTime(key.ExpiresAt), key.Secret)
+	fmt.Fprintln(e.stderr, "The key is shown only once, store it now.")
+	return nil
+}
+
+func runKeysList(ctx context.Context, e *env, args []string) error {
+	fs, output := e.newFlagSet("keys list", "")
+	if err := parseFlags(fs, output, args); err != nil {
+		return err
+	}
+
+	ctx, err := e.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys, err := e.auth.ListKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	return writeKeys(e.stdout, *output, keys)
+}
+
+func runKeysRevoke(ctx context.Context, e *env, args []string) error {
+	fs, output := e.newFlagSet("keys revoke", "<id>...")
+	if err := parseFlags(fs, output, args); err != nil {
+		return err
+	}
+
+	ids, err := parseIds(fs.Args())
+	if err == nil && len(ids) == 0 {
+		err = errUsage
+	}
+	if err != nil {
+		fs.Usage()
+		return err
+	}
+
+	if ctx, err = e.connect(ctx); err != nil {
+		return err
+	}
+
+	results := make([]result, 0, len(ids))
+	for _, id := range ids {
+		r := newResult(e.auth.RevokeKey(ctx, id))
+		r.Id = id
+		results = append(results, r)
+	}
+
+	return writeResults(e.stdout, *output, results)
+}
+
+func writeKeys(w io.Writer, format string, keys []entity.APIKey) error {
+	if format == formatJSON {
+		return writeJSON(w, keys)
+	}
+
+	now := time.Now()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tPREFIX\tSCOPES\tCREATED\tEXPIRES\tSTATUS")
+	for _, key := range keys {
+		status := "active"
+		switch {
+		case key.RevokedAt != nil:
+			status = "revoked " + key.RevokedAt.Format(time.DateTime)
+		case !key.Active(now):
+			status = "expired"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", key.Id, key.Name, key.Prefix, joinScopes(key.Scopes),
+			key.CreatedAt.Format(time.DateTime), formatTime(key.ExpiresAt), status)
+	}
+	return tw.Flush()
+}
+
+func joinScopes(scopes []entity.Scope) string {
+	var result multiFlag
+	for _, scope := range scopes {
+		result = append(result, string(scope))
+	}
+	return result.String()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.DateTime)
+}
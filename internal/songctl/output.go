@@ -0,0 +1,104 @@
+package songctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spanwalla/song-library/internal/entity"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+func checkFormat(format string) error {
+	if format != formatTable && format != formatJSON {
+		return fmt.Errorf("%w: unknown output format %q", errUsage, format)
+	}
+	return nil
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeSongs(w io.Writer, format string, songs []entity.Song) error {
+	if format == formatJSON {
+		return writeJSON(w, songs)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tGROUP\tSONG\tRELEASE DATE\tLINK")
+	for _, song := range songs {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", song.Id, song.Group, song.Name, song.ReleaseDate.Format(time.DateOnly), song.Link)
+	}
+	return tw.Flush()
+}
+
+// result описывает исход операции над одной песней в пакетных командах.
+type result struct {
+	Id     int    `json:"id,omitempty"`
+	Group  string `json:"group,omitempty"`
+	Song   string `json:"song,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+const (
+	statusOK     = "ok"
+	statusFailed = "failed"
+)
+
+func newResult(err error) result {
+	if err != nil {
+		return result{Status: statusFailed, Error: err.Error()}
+	}
+	return result{Status: statusOK}
+}
+
+// writeResults выводит результаты и возвращает ошибку, если хотя бы одна операция не удалась,
+// чтобы скрипт мог проверить код завершения.
+func writeResults(w io.Writer, format string, results []result) error {
+	var err error
+	if format == formatJSON {
+		err = writeJSON(w, results)
+	} else {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tGROUP\tSONG\tSTATUS\tERROR")
+		for _, r := range results {
+			id := "-"
+			if r.Id > 0 {
+				id = fmt.Sprint(r.Id)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", id, dash(r.Group), dash(r.Song), r.Status, dash(r.Error))
+		}
+		err = tw.Flush()
+	}
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Status == statusFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d operations failed", failed, len(results))
+	}
+	return nil
+}
+
+func dash(s string) string {
+	if len(s) == 0 {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,233 @@
+// Package songctl реализует консольную утилиту для обслуживания библиотеки без HTTP API.
+package songctl
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/spanwalla/song-library/config"
+	"github.com/spanwalla/song-library/internal/repository"
+	"github.com/spanwalla/song-library/internal/service"
+	"github.com/spanwalla/song-library/internal/tenant"
+	"github.com/spanwalla/song-library/internal/webapi"
+	"github.com/spanwalla/song-library/pkg/cache"
+	"github.com/spanwalla/song-library/pkg/postgres"
+	"github.com/spanwalla/song-library/pkg/validator"
+)
+
+const usage = `usage: songctl [-config path] [-tenant slug] <command> [flags] [args]
+
+commands:
+  search       list songs with filters, sorting and pagination
+  get          show songs by id
+  text         show song text
+  set-text     replace song text from a file or stdin
+  insert       add songs, with or without the external lookup
+  update       change song fields
+  delete       delete songs by id
+  keys         issue, list and revoke API keys
+  users        list users and assign roles
+  tenants      list and create tenants
+
+Run "songctl <command> -h" for command flags.
+Configuration is read from -config or CONFIG_PATH, the same as for the server.
+Commands work with the library of -tenant or SONGCTL_TENANT, the default tenant if unset.`
+
+// errUsage означает, что аргументы неверны и справка уже выведена.
+var errUsage = errors.New("invalid arguments")
+
+// env содержит всё, что нужно командам: сервис, валидатор и ввод-вывод.
+// Подключение к базе создаётся в connect, после разбора флагов команды,
+// чтобы справка и ошибки в аргументах не требовали доступной базы.
+type env struct {
+	configPath string
+	tenantSlug string
+
+	pg        *postgres.Postgres
+	repos     *repository.Repositories
+	songInfo  webapi.SongInfo
+	cache     cache.Cache
+	songs     service.Song
+	auth      service.Auth
+	tenants   service.Tenant
+	validator *validator.CustomValidator
+
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+type command struct {
+	name string
+	run  func(ctx context.Context, e *env, args []string) error
+}
+
+var commands = []command{
+	{"search", runSearch},
+	{"get", runGet},
+	{"text", runText},
+	{"set-text", runSetText},
+	{"insert", runInsert},
+	{"update", runUpdate},
+	{"delete", runDelete},
+	{"keys", runKeys},
+	{"users", runUsers},
+	{"tenants", runTenants},
+}
+
+// Run выполняет команду и возвращает код завершения процесса.
+func Run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("songctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprintln(stderr, usage) }
+	configPath := fs.String("config", os.Getenv("CONFIG_PATH"), "path to the config file")
+	tenantSlug := fs.String("tenant", os.Getenv("SONGCTL_TENANT"), "tenant slug, the default tenant if empty")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == fs.Arg(0) {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fs.Usage()
+		return 2
+	}
+
+	e := &env{
+		configPath: *configPath,
+		tenantSlug: *tenantSlug,
+		validator:  validator.NewCustomValidator(),
+		stdin:      stdin,
+		stdout:     stdout,
+		stderr:     stderr,
+	}
+	defer e.close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := cmd.run(ctx, e, fs.Args()[1:])
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		return 2
+	case errors.Is(err, errUsage):
+		if !errors.Is(errUsage, err) {
+			fmt.Fprintf(stderr, "songctl %s: %v\n", cmd.name, err)
+		}
+		return 2
+	case err != nil:
+		fmt.Fprintf(stderr, "songctl %s: %v\n", cmd.name, err)
+		return 1
+	}
+
+	return 0
+}
+
+// connect читает конфигурацию, подключается к базе и возвращает контекст
+// с арендатором, указанным в -tenant.
+func (e *env) connect(ctx context.Context) (context.Context, error) {
+	if len(e.configPath) == 0 {
+		return nil, errors.New("config path is empty, set -config or CONFIG_PATH")
+	}
+
+	cfg, err := config.New(e.configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Журнал сервиса пишется в stderr, чтобы не смешиваться с выводом команд.
+	log.SetOutput(e.stderr)
+	if level, err := log.ParseLevel(cfg.Log.Level); err == nil {
+		log.SetLevel(level)
+	}
+
+	// Утилиту запускают вручную, поэтому ждать подъёма базы, как сервер, не нужно.
+	e.pg, err = postgres.New(cfg.PG.URL, postgres.MaxPoolSize(cfg.PG.PoolMax), postgres.ConnAttempts(1))
+	if err != nil {
+		return nil, err
+	}
+
+	e.repos = repository.NewRepositories(e.pg)
+	e.songInfo = newSongInfo(cfg)
+	// Утилита работает недолго, и кэш ей почти не нужен, но её изменения должны удалить записи
+	// из кэшей запущенных экземпляров сервиса.
+	if cfg.Cache.Enabled && cfg.Cache.Invalidation == "postgres" {
+		e.cache = cache.NewBroadcast(cache.NewLRU(1), repository.NewCacheNotifier(e.pg))
+	}
+	e.songs = e.newSongService(e.songInfo)
+	e.tenants = service.NewTenantService(e.repos.Tenant)
+	// Утилита управляет только API-ключами и ролями, настройки входа пользователей ей не нужны.
+	e.auth = service.NewAuthService(e.repos.APIKey, e.repos.User, e.repos.Invite, e.repos.Session, e.tenants, e.pg, service.NewAuthConfig(service.AuthSettings{}))
+
+	if len(e.tenantSlug) == 0 {
+		return ctx, nil
+	}
+	t, err := e.tenants.Resolve(ctx, e.tenantSlug)
+	if err != nil {
+		return nil, fmt.Errorf("tenant %q: %w", e.tenantSlug, err)
+	}
+
+	return tenant.With(ctx, t), nil
+}
+
+// newSongInfo создаёт клиент внешнего API с адресами арендаторов из tenants.overrides.
+func newSongInfo(cfg *config.Config) webapi.SongInfo {
+	fallback := webapi.NewSongInfoWebAPI(cfg.SongAPI.URL, cfg.SongAPI.Timeout)
+
+	apis := cfg.Tenants.SongAPIs(cfg.SongAPI)
+	if len(apis) == 0 {
+		return fallback
+	}
+
+	tenants := make(map[string]webapi.SongInfo, len(apis))
+	for slug, api := range apis {
+		tenants[slug] = webapi.NewSongInfoWebAPI(api.URL, api.Timeout)
+	}
+	return webapi.NewTenantSongInfo(fallback, tenants)
+}
+
+func (e *env) close() {
+	if e.pg != nil {
+		e.pg.Close()
+	}
+}
+
+// newSongService создаёт сервис с заданным источником информации о песнях.
+// Команда insert с -no-lookup подставляет данные из ввода вместо внешнего API.
+func (e *env) newSongService(songInfo webapi.SongInfo) service.Song {
+	return service.NewServices(service.Dependencies{
+		Repos:      e.repos,
+		SongInfo:   songInfo,
+		Transactor: e.pg,
+		Cache:      e.cache,
+	}).Song
+}
+
+// newFlagSet создаёт набор флагов команды с общим флагом формата вывода.
+func (e *env) newFlagSet(name, args string) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(e.stderr, "usage: songctl %s [flags] %s\n\nflags:\n", name, args)
+		fs.PrintDefaults()
+	}
+	output := fs.String("o", formatTable, "output format: table or json")
+	return fs, output
+}
@@ -0,0 +1,37 @@
+package songctl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spanwalla/song-library/internal/webapi"
+)
+
+var errNoManualInfo = errors.New("no data for song")
+
+// manualSongInfo подменяет внешний API данными, переданными в команду insert -no-lookup.
+type manualSongInfo map[string]webapi.GetSongInfoOutput
+
+func songKey(group, song string) string {
+	return group + "\x00" + song
+}
+
+func (m manualSongInfo) add(group, song, link, releaseDate, text string) error {
+	date, err := time.Parse(time.DateOnly, releaseDate)
+	if err != nil {
+		return fmt.Errorf("release date %q must be in format 2006-01-02", releaseDate)
+	}
+
+	m[songKey(group, song)] = webapi.GetSongInfoOutput{ReleaseDate: date, Text: text, Link: link}
+	return nil
+}
+
+func (m manualSongInfo) Get(_ context.Context, group, song string) (webapi.GetSongInfoOutput, error) {
+	output, ok := m[songKey(group, song)]
+	if !ok {
+		return webapi.GetSongInfoOutput{}, fmt.Errorf("%w: %s - %s", errNoManualInfo, group, song)
+	}
+	return output, nil
+}
@@ -0,0 +1,99 @@
+package songctl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spanwalla/song-library/internal/entity"
+	"github.com/spanwalla/song-library/internal/service"
+)
+
+const tenantsUsage = `usage: songctl tenants <command> [flags] [args]
+
+commands:
+  list      show all tenants
+  create    add a tenant with its own song library`
+
+// runTenants управляет арендаторами. HTTP API для этого нет: администратор арендатора
+// управляет только своей библиотекой.
+func runTenants(ctx context.Context, e *env, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintln(e.stderr, tenantsUsage)
+		return errUsage
+	}
+
+	switch args[0] {
+	case "list":
+		return runTenantsList(ctx, e, args[1:])
+	case "create":
+		return runTenantsCreate(ctx, e, args[1:])
+	default:
+		fmt.Fprintln(e.stderr, tenantsUsage)
+		return fmt.Errorf("%w: unknown tenants command %q", errUsage, args[0])
+	}
+}
+
+type createTenantInput struct {
+	Slug string `json:"slug" validate:"required,max=63"`
+	Name string `json:"name" validate:"required,max=128"`
+}
+
+func runTenantsList(ctx context.Context, e *env, args []string) error {
+	fs, output := e.newFlagSet("tenants list", "")
+	if err := parseFlags(fs, output, args); err != nil {
+		return err
+	}
+
+	ctx, err := e.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	tenants, err := e.tenants.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	return writeTenants(e.stdout, *output, tenants)
+}
+
+func runTenantsCreate(ctx context.Context, e *env, args []string) error {
+	fs, output := e.newFlagSet("tenants create", "")
+	slug := fs.String("slug", "", "tenant slug for the X-Tenant header and subdomain: lowercase letters, digits and hyphens")
+	name := fs.String("name", "", "human readable tenant name")
+	if err := parseFlags(fs, output, args); err != nil {
+		return err
+	}
+
+	if err := e.validator.Validate(createTenantInput{Slug: *slug, Name: *name}); err != nil {
+		return err
+	}
+
+	ctx, err := e.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	t, err := e.tenants.Create(ctx, service.CreateTenantInput{Slug: *slug, Name: *name})
+	if err != nil {
+		return err
+	}
+
+	return writeTenants(e.stdout, *output, []entity.Tenant{t})
+}
+
+func writeTenants(w io.Writer, format string, tenants []entity.Tenant) error {
+	if format == formatJSON {
+		return writeJSON(w, tenants)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSLUG\tNAME\tCREATED")
+	for _, t := range tenants {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", t.Id, t.Slug, t.Name, t.CreatedAt.Format(time.DateTime))
+	}
+	return tw.Flush()
+}
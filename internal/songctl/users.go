@@ -0,0 +1,107 @@
+package songctl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spanwalla/song-library/internal/entity"
+)
+
+const usersUsage = `usage: songctl users <command> [flags] [args]
+
+commands:
+  list      show all users with their roles
+  role      assign a role to users by id`
+
+// runUsers управляет ролями пользователей. Утилита работает от имени системы,
+// поэтому может назначить первого администратора.
+func runUsers(ctx context.Context, e *env, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintln(e.stderr, usersUsage)
+		return errUsage
+	}
+
+	switch args[0] {
+	case "list":
+		return runUsersList(ctx, e, args[1:])
+	case "role":
+		return runUsersRole(ctx, e, args[1:])
+	default:
+		fmt.Fprintln(e.stderr, usersUsage)
+		return fmt.Errorf("%w: unknown users command %q", errUsage, args[0])
+	}
+}
+
+type assignRoleInput struct {
+	Role string `json:"role" validate:"required,oneof=viewer editor moderator admin"`
+}
+
+func runUsersList(ctx context.Context, e *env, args []string) error {
+	fs, output := e.newFlagSet("users list", "")
+	if err := parseFlags(fs, output, args); err != nil {
+		return err
+	}
+
+	ctx, err := e.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	users, err := e.auth.ListUsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	return writeUsers(e.stdout, *output, users)
+}
+
+func runUsersRole(ctx context.Context, e *env, args []string) error {
+	fs, output := e.newFlagSet("users role", "<id>...")
+	role := fs.String("role", "", "role: viewer, editor, moderator or admin")
+	if err := parseFlags(fs, output, args); err != nil {
+		return err
+	}
+
+	if err := e.validator.Validate(assignRoleInput{Role: *role}); err != nil {
+		return err
+	}
+
+	ids, err := parseIds(fs.Args())
+	if err == nil && len(ids) == 0 {
+		err = errUsage
+	}
+	if err != nil {
+		fs.Usage()
+		return err
+	}
+
+	if ctx, err = e.connect(ctx); err != nil {
+		return err
+	}
+
+	results := make([]result, 0, len(ids))
+	for _, id := range ids {
+		_, err := e.auth.AssignRole(ctx, id, entity.Role(*role))
+		r := newResult(err)
+		r.Id = id
+		results = append(results, r)
+	}
+
+	return writeResults(e.stdout, *output, results)
+}
+
+func writeUsers(w io.Writer, format string, users []entity.User) error {
+	if format == formatJSON {
+		return writeJSON(w, users)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tUSERNAME\tROLE\tCREATED")
+	for _, user := range users {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", user.Id, user.Username, user.Role, user.CreatedAt.Format(time.DateTime))
+	}
+	return tw.Flush()
+}
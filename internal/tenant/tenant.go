@@ -0,0 +1,30 @@
+// Package tenant передаёт арендатора запроса через контекст от контроллеров до репозиториев.
+package tenant
+
+import (
+	"context"
+
+	"github.com/spanwalla/song-library/internal/entity"
+)
+
+type tenantKey struct{}
+
+// With возвращает контекст, в котором запрос выполняется в библиотеке t.
+func With(ctx context.Context, t entity.Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey{}, t)
+}
+
+// From возвращает арендатора запроса, если он был указан.
+func From(ctx context.Context) (entity.Tenant, bool) {
+	t, ok := ctx.Value(tenantKey{}).(entity.Tenant)
+	return t, ok
+}
+
+// Id возвращает идентификатор арендатора запроса. Если арендатор не указан, например,
+// аутентификация отключена и заголовка нет, используется арендатор по умолчанию.
+func Id(ctx context.Context) int {
+	if t, ok := From(ctx); ok {
+		return t.Id
+	}
+	return entity.DefaultTenantId
+}
@@ -7,9 +7,12 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"sync/atomic"
 	"time"
 
-	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/spanwalla/song-library/pkg/logger"
 )
 
 type SongInfoBody struct {
@@ -18,21 +21,41 @@ type SongInfoBody struct {
 	Link        string `json:"link"`
 }
 
+// songInfoSettings хранит параметры, которые можно менять без перезапуска.
+type songInfoSettings struct {
+	baseURL string
+	timeout time.Duration
+}
+
 type SongInfoWebAPI struct {
-	client  *http.Client
-	BaseURL string
+	client   *http.Client
+	settings atomic.Pointer[songInfoSettings]
 }
 
-func NewSongInfoWebAPI(url string) *SongInfoWebAPI {
-	return &SongInfoWebAPI{
-		BaseURL: url,
-		client:  &http.Client{},
+func NewSongInfoWebAPI(url string, timeout time.Duration) *SongInfoWebAPI {
+	siw := &SongInfoWebAPI{
+		// Транспорт создаёт клиентский span и передаёт контекст трассировки во внешний сервис.
+		client: &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)},
 	}
+	siw.Configure(url, timeout)
+
+	return siw
+}
+
+// Configure меняет адрес сервиса и таймаут запроса. Запросы, которые уже выполняются,
+// завершаются со старыми параметрами.
+func (siw *SongInfoWebAPI) Configure(url string, timeout time.Duration) {
+	siw.settings.Store(&songInfoSettings{baseURL: url, timeout: timeout})
 }
 
 func (siw *SongInfoWebAPI) Get(ctx context.Context, group, song string) (GetSongInfoOutput, error) {
 	const endpoint = "/info"
-	baseURL, err := url.Parse(siw.BaseURL + endpoint)
+	settings := siw.settings.Load()
+
+	ctx, cancel := context.WithTimeout(ctx, settings.timeout)
+	defer cancel()
+
+	baseURL, err := url.Parse(settings.baseURL + endpoint)
 	if err != nil {
 		return GetSongInfoOutput{}, err
 	}
@@ -42,7 +65,7 @@ func (siw *SongInfoWebAPI) Get(ctx context.Context, group, song string) (GetSong
 	params.Set("song", song)
 	baseURL.RawQuery = params.Encode()
 
-	log.Debugf("SongInfoWebApi.Get - baseURL.String(): %s", baseURL.String())
+	logger.From(ctx).Debugf("SongInfoWebApi.Get - baseURL.String(): %s", baseURL.String())
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL.String(), nil)
 	if err != nil {
@@ -56,7 +79,7 @@ func (siw *SongInfoWebAPI) Get(ctx context.Context, group, song string) (GetSong
 	defer func(Body io.ReadCloser) {
 		err = Body.Close()
 		if err != nil {
-			log.Errorf("SongInfoWebAPI.Get - Body.Close(): %v", err)
+			logger.From(ctx).Errorf("SongInfoWebAPI.Get - Body.Close(): %v", err)
 		}
 	}(resp.Body)
 
@@ -85,3 +108,24 @@ func (siw *SongInfoWebAPI) Get(ctx context.Context, group, song string) (GetSong
 		Link:        result.Link,
 	}, nil
 }
+
+// Ping проверяет, что внешний сервис отвечает. Любой ответ без ошибки сервера считается успешным,
+// так как у сервиса нет отдельного эндпоинта для проверки.
+func (siw *SongInfoWebAPI) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, siw.settings.Load().baseURL, nil)
+	if err != nil {
+		return fmt.Errorf("SongInfoWebAPI.Ping - http.NewRequestWithContext: %w", err)
+	}
+
+	resp, err := siw.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("SongInfoWebAPI.Ping - siw.client.Do: %w", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("SongInfoWebAPI.Ping - bad status: %s", resp.Status)
+	}
+
+	return nil
+}
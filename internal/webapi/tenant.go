@@ -0,0 +1,29 @@
+package webapi
+
+import (
+	"context"
+
+	"github.com/spanwalla/song-library/internal/tenant"
+)
+
+// TenantSongInfo выбирает источник информации о песнях по арендатору запроса.
+// Арендаторы без собственного источника используют общий.
+type TenantSongInfo struct {
+	fallback SongInfo
+	tenants  map[string]SongInfo
+}
+
+// NewTenantSongInfo создаёт источник, который передаёт запросы арендатора с slug из tenants
+// его собственному источнику, а остальные — fallback.
+func NewTenantSongInfo(fallback SongInfo, tenants map[string]SongInfo) *TenantSongInfo {
+	return &TenantSongInfo{fallback: fallback, tenants: tenants}
+}
+
+func (t *TenantSongInfo) Get(ctx context.Context, group, song string) (GetSongInfoOutput, error) {
+	if current, ok := tenant.From(ctx); ok {
+		if songInfo, ok := t.tenants[current.Slug]; ok {
+			return songInfo.Get(ctx, group, song)
+		}
+	}
+	return t.fallback.Get(ctx, group, song)
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_line_timings_song_id_start_ms;
+
+DROP TABLE IF EXISTS line_timings;
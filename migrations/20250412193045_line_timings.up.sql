@@ -0,0 +1,10 @@
+CREATE TABLE line_timings(
+    song_id INTEGER NOT NULL,
+    sequence_number INTEGER NOT NULL,
+    line_number INTEGER NOT NULL,
+    start_ms INTEGER NOT NULL CHECK (start_ms >= 0),
+    PRIMARY KEY (song_id, sequence_number, line_number),
+    FOREIGN KEY (song_id, sequence_number) REFERENCES couplets(song_id, sequence_number) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_line_timings_song_id_start_ms ON line_timings (song_id, start_ms);
@@ -0,0 +1,7 @@
+CREATE TABLE translations(
+    song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    lang VARCHAR(16) NOT NULL,
+    sequence_number INTEGER NOT NULL,
+    couplet_text BPCHAR NOT NULL,
+    PRIMARY KEY (song_id, lang, sequence_number)
+);
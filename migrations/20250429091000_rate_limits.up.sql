@@ -0,0 +1,10 @@
+-- Счётчики живут не дольше окна, поэтому журнал WAL для них не нужен.
+CREATE UNLOGGED TABLE rate_limits(
+    key VARCHAR(256) NOT NULL,
+    window_start TIMESTAMPTZ NOT NULL,
+    hits INTEGER NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (key, window_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limits_expires_at ON rate_limits (expires_at);
@@ -0,0 +1,13 @@
+CREATE TABLE audit_log(
+    id BIGSERIAL PRIMARY KEY,
+    song_id INTEGER NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    actor VARCHAR(64) NOT NULL,
+    request_id VARCHAR(64),
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_song_id ON audit_log (song_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log (created_at);
@@ -0,0 +1,4 @@
+-- Существующие пользователи могли добавлять и удалять песни, поэтому получают роль moderator.
+-- Новые пользователи по умолчанию только читают.
+ALTER TABLE users ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'moderator';
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'viewer';
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_audit_log_tenant_id;
+DROP INDEX IF EXISTS idx_couplets_tenant_id;
+DROP INDEX IF EXISTS idx_songs_tenant_id;
+
+ALTER TABLE users DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE audit_log DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE couplets DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE songs DROP COLUMN IF EXISTS tenant_id;
+
+DROP TABLE IF EXISTS tenants;
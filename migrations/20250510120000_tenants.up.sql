@@ -0,0 +1,28 @@
+CREATE TABLE tenants(
+    id SERIAL PRIMARY KEY,
+    slug VARCHAR(64) NOT NULL UNIQUE,
+    name VARCHAR(128) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Всё, что было создано до появления арендаторов, принадлежит арендатору по умолчанию.
+INSERT INTO tenants (id, slug, name) VALUES (1, 'default', 'Default');
+SELECT setval('tenants_id_seq', 1);
+
+ALTER TABLE songs ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id);
+ALTER TABLE couplets ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id);
+ALTER TABLE audit_log ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id);
+ALTER TABLE api_keys ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id);
+ALTER TABLE users ADD COLUMN tenant_id INTEGER NOT NULL DEFAULT 1 REFERENCES tenants(id);
+
+-- Новые строки должны явно указывать арендатора, чтобы ошибка в запросе не складывала
+-- чужие данные в библиотеку по умолчанию.
+ALTER TABLE songs ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE couplets ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE audit_log ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE api_keys ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN tenant_id DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS idx_songs_tenant_id ON songs (tenant_id, id);
+CREATE INDEX IF NOT EXISTS idx_couplets_tenant_id ON couplets (tenant_id, song_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_tenant_id ON audit_log (tenant_id, id);
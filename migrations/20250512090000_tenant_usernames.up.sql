@@ -0,0 +1,4 @@
+-- Имя пользователя уникально только внутри арендатора, иначе регистрация в одной библиотеке
+-- выдаёт, какие имена заняты в других.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_username_key;
+ALTER TABLE users ADD CONSTRAINT users_tenant_id_username_key UNIQUE (tenant_id, username);
@@ -0,0 +1 @@
+ALTER TABLE line_timings DROP COLUMN IF EXISTS end_ms;
@@ -0,0 +1,2 @@
+-- Время окончания строки из LRC-метки без текста: после него до следующей строки идёт проигрыш.
+ALTER TABLE line_timings ADD COLUMN IF NOT EXISTS end_ms INTEGER CHECK (end_ms > start_ms);
@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/spanwalla/song-library/pkg/logger"
+)
+
+const defaultRetryInterval = 5 * time.Second
+
+// Notifier delivers deleted keys between replicas.
+type Notifier interface {
+	// Publish sends keys to all listening replicas, including this one.
+	Publish(ctx context.Context, keys []string) error
+	// Listen calls handle with the keys published by any replica. It blocks until ctx
+	// is done or the connection fails.
+	Listen(ctx context.Context, handle func(keys []string)) error
+}
+
+// Broadcast is an LRU whose deletes reach the LRUs of other replicas through a Notifier.
+// Run must be started to receive their deletes.
+type Broadcast struct {
+	local         *LRU
+	notifier      Notifier
+	retryInterval time.Duration
+}
+
+func NewBroadcast(local *LRU, notifier Notifier, opts ...Option) *Broadcast {
+	b := &Broadcast{
+		local:         local,
+		notifier:      notifier,
+		retryInterval: defaultRetryInterval,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+func (b *Broadcast) Get(ctx context.Context, key string) ([]byte, bool) {
+	return b.local.Get(ctx, key)
+}
+
+func (b *Broadcast) Set(ctx context.Context, key string, value []byte, ttl time.Duration) {
+	b.local.Set(ctx, key, value, ttl)
+}
+
+// Delete removes keys locally and publishes them. If publishing fails, other replicas
+// keep the values until they expire.
+func (b *Broadcast) Delete(ctx context.Context, keys ...string) {
+	b.local.Delete(ctx, keys...)
+
+	if err := b.notifier.Publish(ctx, keys); err != nil {
+		logger.From(ctx).Errorf("cache - Broadcast.Delete - b.notifier.Publish: %v", err)
+	}
+}
+
+// Run receives deletes from other replicas until ctx is done. Deletes published while
+// the connection is down are lost, so the local cache is purged before reconnecting.
+func (b *Broadcast) Run(ctx context.Context) {
+	for {
+		err := b.notifier.Listen(ctx, func(keys []string) {
+			b.local.Delete(ctx, keys...)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		logger.From(ctx).Errorf("cache - Broadcast.Run - b.notifier.Listen: %v, retrying in %s", err, b.retryInterval)
+		b.local.Purge()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(b.retryInterval):
+		}
+	}
+}
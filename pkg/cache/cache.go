@@ -0,0 +1,18 @@
+// Package cache implements caches for serialized values behind a common interface,
+// so that the in-process LRU can be replaced with a shared cache.
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache stores serialized values by key. Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value of key if it is present and has not expired.
+	Get(ctx context.Context, key string) ([]byte, bool)
+	// Set stores value under key for ttl. Zero ttl keeps the value until it is evicted.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration)
+	// Delete removes keys. Missing keys are ignored.
+	Delete(ctx context.Context, keys ...string)
+}
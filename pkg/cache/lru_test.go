@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func get(c *LRU, key string) string {
+	value, ok := c.Get(context.Background(), key)
+	if !ok {
+		return "<miss>"
+	}
+	return string(value)
+}
+
+func TestLRUEviction(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2)
+
+	c.Set(ctx, "a", []byte("1"), 0)
+	c.Set(ctx, "b", []byte("2"), 0)
+	// Reading a makes b the least recently used value.
+	assert.Equal(t, "1", get(c, "a"))
+
+	c.Set(ctx, "c", []byte("3"), 0)
+	assert.Equal(t, 2, c.Len())
+	assert.Equal(t, "<miss>", get(c, "b"))
+	assert.Equal(t, "1", get(c, "a"))
+	assert.Equal(t, "3", get(c, "c"))
+
+	// Overwriting a value does not grow the cache and refreshes its position.
+	c.Set(ctx, "a", []byte("10"), 0)
+	c.Set(ctx, "d", []byte("4"), 0)
+	assert.Equal(t, 2, c.Len())
+	assert.Equal(t, "10", get(c, "a"))
+	assert.Equal(t, "<miss>", get(c, "c"))
+	assert.Equal(t, "4", get(c, "d"))
+}
+
+func TestLRUMinimumCapacity(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(0)
+
+	c.Set(ctx, "a", []byte("1"), 0)
+	c.Set(ctx, "b", []byte("2"), 0)
+	assert.Equal(t, 1, c.Len())
+	assert.Equal(t, "2", get(c, "b"))
+}
+
+func TestLRUTTL(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := NewLRU(10)
+	c.now = func() time.Time { return now }
+
+	c.Set(ctx, "short", []byte("1"), time.Second)
+	c.Set(ctx, "forever", []byte("2"), 0)
+
+	now = now.Add(999 * time.Millisecond)
+	assert.Equal(t, "1", get(c, "short"))
+
+	now = now.Add(time.Millisecond)
+	assert.Equal(t, "<miss>", get(c, "short"))
+	assert.Equal(t, 1, c.Len(), "expired value is evicted on read")
+
+	now = now.Add(24 * time.Hour)
+	assert.Equal(t, "2", get(c, "forever"))
+
+	// Overwriting a value resets its TTL.
+	c.Set(ctx, "short", []byte("3"), time.Second)
+	now = now.Add(500 * time.Millisecond)
+	c.Set(ctx, "short", []byte("4"), time.Second)
+	now = now.Add(900 * time.Millisecond)
+	assert.Equal(t, "4", get(c, "short"))
+}
+
+func TestLRUDeleteAndPurge(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(10)
+
+	c.Set(ctx, "a", []byte("1"), 0)
+	c.Set(ctx, "b", []byte("2"), 0)
+	c.Set(ctx, "c", []byte("3"), 0)
+
+	c.Delete(ctx, "a", "b", "missing")
+	assert.Equal(t, "<miss>", get(c, "a"))
+	assert.Equal(t, "<miss>", get(c, "b"))
+	assert.Equal(t, "3", get(c, "c"))
+
+	c.Purge()
+	assert.Equal(t, 0, c.Len())
+	assert.Equal(t, "<miss>", get(c, "c"))
+}
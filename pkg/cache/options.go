@@ -0,0 +1,12 @@
+package cache
+
+import "time"
+
+type Option func(*Broadcast)
+
+// RetryInterval sets how long Broadcast.Run waits before listening again after a failure.
+func RetryInterval(interval time.Duration) Option {
+	return func(b *Broadcast) {
+		b.retryInterval = interval
+	}
+}
@@ -0,0 +1,28 @@
+package grpcserver
+
+import (
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+type Option func(*Server)
+
+func Port(port string) Option {
+	return func(s *Server) {
+		s.addr = net.JoinHostPort("", port)
+	}
+}
+
+func ShutdownTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.shutdownTimeout = timeout
+	}
+}
+
+func ServerOptions(opts ...grpc.ServerOption) Option {
+	return func(s *Server) {
+		s.serverOptions = append(s.serverOptions, opts...)
+	}
+}
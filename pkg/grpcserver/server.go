@@ -0,0 +1,93 @@
+// Package grpcserver implements gRPC server with health checks and reflection.
+package grpcserver
+
+import (
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+const (
+	defaultAddr            = ":9090"
+	defaultShutdownTimeout = 3 * time.Second
+)
+
+type Server struct {
+	server          *grpc.Server
+	health          *health.Server
+	serverOptions   []grpc.ServerOption
+	addr            string
+	notify          chan error
+	shutdownTimeout time.Duration
+}
+
+// New registers services with register and starts serving right away.
+func New(register func(s *grpc.Server), opts ...Option) *Server {
+	s := &Server{
+		health:          health.NewServer(),
+		addr:            defaultAddr,
+		notify:          make(chan error, 1),
+		shutdownTimeout: defaultShutdownTimeout,
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.server = grpc.NewServer(s.serverOptions...)
+	register(s.server)
+	healthpb.RegisterHealthServer(s.server, s.health)
+	reflection.Register(s.server)
+
+	for name := range s.server.GetServiceInfo() {
+		s.health.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+
+	s.start()
+
+	return s
+}
+
+func (s *Server) start() {
+	go func() {
+		listener, err := net.Listen("tcp", s.addr)
+		if err != nil {
+			s.notify <- err
+			close(s.notify)
+			return
+		}
+
+		s.notify <- s.server.Serve(listener)
+		close(s.notify)
+	}()
+}
+
+func (s *Server) Notify() <-chan error {
+	return s.notify
+}
+
+// Shutdown reports NOT_SERVING to health checks and waits for active calls to finish.
+// Connections are closed forcibly if that takes longer than shutdownTimeout.
+func (s *Server) Shutdown() error {
+	s.health.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(s.shutdownTimeout):
+		s.server.Stop()
+	}
+
+	return nil
+}
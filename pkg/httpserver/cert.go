@@ -0,0 +1,79 @@
+package httpserver
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// certCheckInterval limits how often the certificate files are checked for changes.
+const certCheckInterval = 10 * time.Second
+
+// certReloader serves the certificate from disk and reloads it once the cert or key file
+// modification time changes. If a reload fails, the previous certificate keeps being served.
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return nil, err
+	}
+	if err = r.load(modTime); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *certReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("httpserver - certReloader - os.Stat: %w", err)
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func (r *certReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("httpserver - certReloader - tls.LoadX509KeyPair: %w", err)
+	}
+
+	r.cert, r.modTime = &cert, modTime
+	return nil
+}
+
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.checkedAt) < certCheckInterval {
+		return r.cert, nil
+	}
+	r.checkedAt = time.Now()
+
+	// The files may be mid-update; keep serving the old certificate and retry on the next check.
+	if modTime, err := r.latestModTime(); err == nil && modTime.After(r.modTime) {
+		_ = r.load(modTime)
+	}
+
+	return r.cert, nil
+}
@@ -1,7 +1,6 @@
 package httpserver
 
 import (
-	"net"
 	"time"
 )
 
@@ -9,7 +8,14 @@ type Option func(*Server)
 
 func Port(port string) Option {
 	return func(s *Server) {
-		s.server.Addr = net.JoinHostPort("", port)
+		s.port = port
+	}
+}
+
+// Host sets the address to bind to. Empty host listens on all interfaces.
+func Host(host string) Option {
+	return func(s *Server) {
+		s.host = host
 	}
 }
 
@@ -19,14 +25,55 @@ func ReadTimeout(timeout time.Duration) Option {
 	}
 }
 
+func ReadHeaderTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.server.ReadHeaderTimeout = timeout
+	}
+}
+
 func WriteTimeout(timeout time.Duration) Option {
 	return func(s *Server) {
 		s.server.WriteTimeout = timeout
 	}
 }
 
+func IdleTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.server.IdleTimeout = timeout
+	}
+}
+
+func MaxHeaderBytes(size int) Option {
+	return func(s *Server) {
+		s.server.MaxHeaderBytes = size
+	}
+}
+
 func ShutdownTimeout(timeout time.Duration) Option {
 	return func(s *Server) {
 		s.shutdownTimeout = timeout
 	}
 }
+
+// TLS serves HTTPS with the given certificate and key. The files are re-read
+// when they change on disk, so certificates can be renewed without a restart.
+func TLS(certFile, keyFile string) Option {
+	return func(s *Server) {
+		s.certFile, s.keyFile = certFile, keyFile
+	}
+}
+
+// HTTP2 enables or disables HTTP/2 over TLS. It is enabled by default.
+func HTTP2(enabled bool) Option {
+	return func(s *Server) {
+		s.http2 = enabled
+	}
+}
+
+// H2C enables HTTP/2 without TLS (prior knowledge), for internal clients behind a proxy
+// or service mesh that terminates TLS. It has no effect when TLS is configured.
+func H2C(enabled bool) Option {
+	return func(s *Server) {
+		s.h2c = enabled
+	}
+}
@@ -3,6 +3,8 @@ package httpserver
 
 import (
 	"context"
+	"crypto/tls"
+	"net"
 	"net/http"
 	"time"
 )
@@ -10,7 +12,7 @@ import (
 const (
 	defaultReadTimeout     = 5 * time.Second
 	defaultWriteTimeout    = 5 * time.Second
-	defaultAddr            = ":8080"
+	defaultPort            = "8080"
 	defaultShutdownTimeout = 3 * time.Second
 )
 
@@ -18,6 +20,13 @@ type Server struct {
 	server          *http.Server
 	notify          chan error
 	shutdownTimeout time.Duration
+
+	host     string
+	port     string
+	certFile string
+	keyFile  string
+	http2    bool
+	h2c      bool
 }
 
 func New(handler http.Handler, opts ...Option) *Server {
@@ -25,13 +34,14 @@ func New(handler http.Handler, opts ...Option) *Server {
 		Handler:      handler,
 		ReadTimeout:  defaultReadTimeout,
 		WriteTimeout: defaultWriteTimeout,
-		Addr:         defaultAddr,
 	}
 
 	s := &Server{
 		server:          httpServer,
 		notify:          make(chan error, 1),
 		shutdownTimeout: defaultShutdownTimeout,
+		port:            defaultPort,
+		http2:           true,
 	}
 
 	// Custom options
@@ -39,18 +49,51 @@ func New(handler http.Handler, opts ...Option) *Server {
 		opt(s)
 	}
 
+	s.server.Addr = net.JoinHostPort(s.host, s.port)
+	s.server.Protocols = s.protocols()
+
 	s.start()
 
 	return s
 }
 
+func (s *Server) tlsEnabled() bool {
+	return len(s.certFile) > 0 || len(s.keyFile) > 0
+}
+
+func (s *Server) protocols() *http.Protocols {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(s.http2 && s.tlsEnabled())
+	protocols.SetUnencryptedHTTP2(s.h2c && !s.tlsEnabled())
+	return protocols
+}
+
 func (s *Server) start() {
 	go func() {
-		s.notify <- s.server.ListenAndServe()
+		s.notify <- s.listenAndServe()
 		close(s.notify)
 	}()
 }
 
+func (s *Server) listenAndServe() error {
+	if !s.tlsEnabled() {
+		return s.server.ListenAndServe()
+	}
+
+	reloader, err := newCertReloader(s.certFile, s.keyFile)
+	if err != nil {
+		return err
+	}
+
+	s.server.TLSConfig = &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	return s.server.ListenAndServeTLS("", "")
+}
+
 func (s *Server) Notify() <-chan error {
 	return s.notify
 }
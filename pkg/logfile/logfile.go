@@ -0,0 +1,182 @@
+// Package logfile implements a log file writer with size and age based rotation
+// that can be reopened after an external tool such as logrotate moved the file.
+package logfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	backupTimeFormat = "20060102T150405.000"
+	filePerm         = 0o644
+	dirPerm          = 0o755
+)
+
+type Writer struct {
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	now        func() time.Time
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+// Open opens or creates the file at path, creating missing directories.
+func Open(path string, opts ...Option) (*Writer, error) {
+	w := &Writer{path: path, now: time.Now}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), dirPerm); err != nil {
+		return nil, fmt.Errorf("logfile - Open - os.MkdirAll: %w", err)
+	}
+
+	file, size, err := w.open()
+	if err != nil {
+		return nil, err
+	}
+	w.file, w.size, w.openedAt = file, size, w.now()
+
+	return w, nil
+}
+
+// open opens path without touching the current file, so a failure leaves the writer usable.
+func (w *Writer) open() (*os.File, int64, error) {
+	file, err := os.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, filePerm)
+	if err != nil {
+		return nil, 0, fmt.Errorf("logfile - open - os.OpenFile: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, 0, fmt.Errorf("logfile - open - file.Stat: %w", err)
+	}
+
+	return file, info.Size(), nil
+}
+
+// swap replaces the current file with file and closes the old one.
+func (w *Writer) swap(file *os.File, size int64) error {
+	old := w.file
+	w.file, w.size, w.openedAt = file, size, w.now()
+
+	return old.Close()
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// A failed rotation does not lose the entry: it goes to the current file
+	// and the error is reported alongside it.
+	var rotateErr error
+	if w.shouldRotate(int64(len(p))) {
+		rotateErr = w.rotate()
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+
+	return n, errors.Join(rotateErr, err)
+}
+
+func (w *Writer) shouldRotate(next int64) bool {
+	if w.size == 0 {
+		return false
+	}
+	if w.maxSize > 0 && w.size+next > w.maxSize {
+		return true
+	}
+	return w.maxAge > 0 && w.now().Sub(w.openedAt) > w.maxAge
+}
+
+// rotate renames the current file with a timestamp suffix and starts a new one.
+// The current file stays open until the new one is ready: if rotation fails,
+// writes keep going to it and the next write tries again.
+func (w *Writer) rotate() error {
+	backup := w.path + "." + w.now().Format(backupTimeFormat)
+	if err := os.Rename(w.path, backup); err != nil {
+		return fmt.Errorf("logfile - rotate - os.Rename: %w", err)
+	}
+
+	file, size, err := w.open()
+	if err != nil {
+		// Return the file to its path so that the writer and logrotate still agree on where it is.
+		if renameErr := os.Rename(backup, w.path); renameErr != nil {
+			return fmt.Errorf("logfile - rotate - os.Rename: %w", renameErr)
+		}
+		return err
+	}
+
+	if err = w.swap(file, size); err != nil {
+		return fmt.Errorf("logfile - rotate - file.Close: %w", err)
+	}
+
+	return w.removeOldBackups()
+}
+
+func (w *Writer) removeOldBackups() error {
+	if w.maxBackups <= 0 {
+		return nil
+	}
+
+	backups, err := filepath.Glob(w.path + ".*")
+	if err != nil {
+		return fmt.Errorf("logfile - removeOldBackups - filepath.Glob: %w", err)
+	}
+	backups = slices.DeleteFunc(backups, func(name string) bool {
+		_, err := time.Parse(backupTimeFormat, strings.TrimPrefix(name, w.path+"."))
+		return err != nil
+	})
+
+	// Timestamp suffixes sort chronologically, so the oldest files come first.
+	slices.Sort(backups)
+	for len(backups) > w.maxBackups {
+		if err = os.Remove(backups[0]); err != nil {
+			return fmt.Errorf("logfile - removeOldBackups - os.Remove: %w", err)
+		}
+		backups = backups[1:]
+	}
+
+	return nil
+}
+
+// Reopen opens path again and closes the previous file. Call it on SIGHUP after
+// logrotate has moved the file away. If path cannot be opened, writes keep going
+// to the previous file and Reopen can be retried.
+func (w *Writer) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	file, size, err := w.open()
+	if err != nil {
+		return err
+	}
+
+	if err = w.swap(file, size); err != nil {
+		return fmt.Errorf("logfile - Reopen - file.Close: %w", err)
+	}
+
+	return nil
+}
+
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.file.Close()
+}
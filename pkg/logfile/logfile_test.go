@@ -0,0 +1,146 @@
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type clock struct {
+	now time.Time
+}
+
+// tick advances the clock so that every rotation gets its own backup name.
+func (c *clock) tick() time.Time {
+	c.now = c.now.Add(time.Second)
+	return c.now
+}
+
+func open(t *testing.T, opts ...Option) (*Writer, string, *clock) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "logs", "access.log")
+	w, err := Open(path, opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = w.Close() })
+
+	c := &clock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	w.now, w.openedAt = c.tick, c.now
+
+	return w, path, c
+}
+
+func read(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func backups(t *testing.T, path string) []string {
+	t.Helper()
+
+	names, err := filepath.Glob(path + ".*")
+	require.NoError(t, err)
+	return names
+}
+
+func write(t *testing.T, w *Writer, s string) {
+	t.Helper()
+
+	n, err := w.Write([]byte(s))
+	require.NoError(t, err)
+	require.Equal(t, len(s), n)
+}
+
+func TestRotateBySize(t *testing.T) {
+	w, path, _ := open(t, MaxSize(10))
+
+	write(t, w, "12345678\n")
+	assert.Empty(t, backups(t, path))
+
+	write(t, w, "abc\n")
+	names := backups(t, path)
+	require.Len(t, names, 1)
+	assert.Equal(t, "12345678\n", read(t, names[0]))
+	assert.Equal(t, "abc\n", read(t, path))
+
+	// An entry larger than the limit is still written whole into an empty file.
+	write(t, w, "0123456789abcdef\n")
+	assert.Len(t, backups(t, path), 2)
+	assert.Equal(t, "0123456789abcdef\n", read(t, path))
+}
+
+func TestRotateByAge(t *testing.T) {
+	w, path, c := open(t, MaxAge(time.Minute))
+
+	write(t, w, "first\n")
+	c.now = c.now.Add(time.Hour)
+	write(t, w, "second\n")
+
+	names := backups(t, path)
+	require.Len(t, names, 1)
+	assert.Equal(t, "first\n", read(t, names[0]))
+	assert.Equal(t, "second\n", read(t, path))
+}
+
+func TestMaxBackups(t *testing.T) {
+	w, path, _ := open(t, MaxSize(1), MaxBackups(2))
+
+	// Files that do not look like backups are never removed.
+	require.NoError(t, os.WriteFile(path+".keep", []byte("x"), filePerm))
+
+	for _, s := range []string{"1\n", "2\n", "3\n", "4\n", "5\n"} {
+		write(t, w, s)
+	}
+
+	names := backups(t, path)
+	require.Len(t, names, 3)
+	assert.Equal(t, "3\n", read(t, names[0]))
+	assert.Equal(t, "4\n", read(t, names[1]))
+	assert.Equal(t, path+".keep", names[2])
+	assert.Equal(t, "5\n", read(t, path))
+}
+
+func TestReopenRecovers(t *testing.T) {
+	w, path, _ := open(t)
+	write(t, w, "before\n")
+
+	// logrotate moved the file away, and path cannot be opened.
+	moved := path + ".1"
+	require.NoError(t, os.Rename(path, moved))
+	require.NoError(t, os.Mkdir(path, dirPerm))
+
+	require.Error(t, w.Reopen())
+	write(t, w, "during\n")
+	assert.Equal(t, "before\nduring\n", read(t, moved))
+
+	require.NoError(t, os.Remove(path))
+	require.NoError(t, w.Reopen())
+	write(t, w, "after\n")
+	assert.Equal(t, "after\n", read(t, path))
+	assert.Equal(t, "before\nduring\n", read(t, moved))
+}
+
+func TestRotateFailureKeepsWriting(t *testing.T) {
+	w, path, _ := open(t, MaxSize(10))
+	write(t, w, "12345678\n")
+
+	// Without the file at path the rename fails, the entry goes to the open file.
+	moved := path + ".1"
+	require.NoError(t, os.Rename(path, moved))
+
+	n, err := w.Write([]byte("abc\n"))
+	require.Error(t, err)
+	assert.Equal(t, 4, n)
+	assert.Equal(t, "12345678\nabc\n", read(t, moved))
+
+	require.NoError(t, w.Reopen())
+	write(t, w, "def\n")
+	assert.Equal(t, "def\n", read(t, path))
+}
@@ -0,0 +1,26 @@
+package logfile
+
+import "time"
+
+type Option func(*Writer)
+
+// MaxSize rotates the file once it grows past size bytes. Zero disables size-based rotation.
+func MaxSize(size int64) Option {
+	return func(w *Writer) {
+		w.maxSize = size
+	}
+}
+
+// MaxAge rotates the file once it has been written to for longer than age. Zero disables age-based rotation.
+func MaxAge(age time.Duration) Option {
+	return func(w *Writer) {
+		w.maxAge = age
+	}
+}
+
+// MaxBackups limits how many rotated files are kept. Zero keeps all of them.
+func MaxBackups(n int) Option {
+	return func(w *Writer) {
+		w.maxBackups = n
+	}
+}
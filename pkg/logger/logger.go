@@ -0,0 +1,46 @@
+// Package logger хранит в контексте логгер с полями текущего запроса.
+package logger
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Поля, которые добавляются к записям в рамках запроса.
+const (
+	FieldRequestId = "request_id"
+	FieldRoute     = "route"
+	FieldMethod    = "method"
+	FieldSongId    = "song_id"
+	FieldAPIKeyId  = "api_key_id"
+	FieldUserId    = "user_id"
+	FieldTenant    = "tenant"
+)
+
+type ctxKey struct{}
+
+// WithEntry кладёт логгер в контекст.
+func WithEntry(ctx context.Context, entry *log.Entry) context.Context {
+	return context.WithValue(ctx, ctxKey{}, entry)
+}
+
+// WithFields добавляет поля к логгеру из контекста и возвращает новый контекст.
+func WithFields(ctx context.Context, fields log.Fields) context.Context {
+	return WithEntry(ctx, From(ctx).WithFields(fields))
+}
+
+// From возвращает логгер из контекста. Если его там нет, возвращается глобальный логгер,
+// поэтому вызывать From можно из любого кода, в том числе вне обработки запроса.
+func From(ctx context.Context) *log.Entry {
+	if entry, ok := ctx.Value(ctxKey{}).(*log.Entry); ok {
+		return entry
+	}
+	return log.NewEntry(log.StandardLogger())
+}
+
+// RequestId возвращает идентификатор запроса, который middleware добавили к логгеру в контексте.
+func RequestId(ctx context.Context) string {
+	id, _ := From(ctx).Data[FieldRequestId].(string)
+	return id
+}
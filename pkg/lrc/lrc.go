@@ -0,0 +1,227 @@
+// Package lrc предназначен для разбора и формирования текстов песен в формате LRC.
+package lrc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Стандартные теги метаданных.
+const (
+	TagTitle  = "ti"
+	TagArtist = "ar"
+	TagAlbum  = "al"
+	TagAuthor = "au"
+	TagLength = "length"
+	TagBy     = "by"
+	TagOffset = "offset"
+	TagTool   = "re"
+)
+
+// tagsOrder задаёт порядок вывода известных тегов метаданных.
+var tagsOrder = []string{TagTitle, TagArtist, TagAlbum, TagAuthor, TagLength, TagBy, TagTool}
+
+var (
+	ErrInvalidTimestamp = errors.New("invalid timestamp")
+	ErrInvalidOffset    = errors.New("invalid offset")
+)
+
+var (
+	metadataRegexp  = regexp.MustCompile(`^\[([a-zA-Z#]+):(.*)]$`)
+	timestampRegexp = regexp.MustCompile(`^\[(\d+):(\d{1,2})(?:[.:](\d{1,3}))?]`)
+)
+
+// Line описывает одну строку текста. Start равен nil, если у строки нет временной метки.
+// Строка с пустым Text без метки разделяет куплеты, а с меткой отмечает конец предыдущей
+// строки, например перед проигрышем.
+type Line struct {
+	Start *time.Duration
+	Text  string
+}
+
+// File хранит разобранный LRC-файл.
+type File struct {
+	Tags  map[string]string
+	Lines []Line
+}
+
+// Parse разбирает LRC-текст.
+// Поддерживаются метки [mm:ss], [mm:ss.xx] и [mm:ss.xxx], а также несколько меток на одной строке.
+// Во втором случае строки сортируются по времени, а строки без меток отбрасываются. Пустые строки
+// при этом сохраняются между строками разных куплетов: куплетом считается каждый повтор блока,
+// отделённого в исходном тексте пустой строкой.
+// Значение тега offset (в миллисекундах) применяется к меткам и в Tags не попадает.
+func Parse(data string) (File, error) {
+	file := File{Tags: make(map[string]string)}
+	compressed := false
+
+	// parsed хранит строки вместе с номером блока и номером повтора, которые нужны,
+	// чтобы восстановить границы куплетов после сортировки.
+	type parsedLine struct {
+		Line
+		block, repeat int
+	}
+	var parsed []parsedLine
+	block := 0
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		raw := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+
+		if m := metadataRegexp.FindStringSubmatch(raw); m != nil {
+			file.Tags[strings.ToLower(m[1])] = strings.TrimSpace(m[2])
+			continue
+		}
+
+		var starts []time.Duration
+		for {
+			m := timestampRegexp.FindStringSubmatch(raw)
+			if m == nil {
+				break
+			}
+			start, err := parseTimestamp(m[1], m[2], m[3])
+			if err != nil {
+				return File{}, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			starts = append(starts, start)
+			raw = raw[len(m[0]):]
+		}
+		text := strings.TrimSpace(raw)
+
+		if len(starts) == 0 {
+			parsed = append(parsed, parsedLine{Line: Line{Text: text}, block: block})
+			if len(text) == 0 {
+				block++
+			}
+			continue
+		}
+		if len(starts) > 1 {
+			compressed = true
+		}
+		for i, start := range starts {
+			parsed = append(parsed, parsedLine{Line: Line{Start: &start, Text: text}, block: block, repeat: i})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return File{}, err
+	}
+
+	if compressed {
+		timed := make([]parsedLine, 0, len(parsed))
+		for _, line := range parsed {
+			if line.Start != nil {
+				timed = append(timed, line)
+			}
+		}
+		sort.SliceStable(timed, func(i, j int) bool {
+			return *timed[i].Start < *timed[j].Start
+		})
+
+		for i, line := range timed {
+			if i > 0 && (line.block != timed[i-1].block || line.repeat != timed[i-1].repeat) {
+				file.Lines = append(file.Lines, Line{})
+			}
+			file.Lines = append(file.Lines, line.Line)
+		}
+	} else {
+		for _, line := range parsed {
+			file.Lines = append(file.Lines, line.Line)
+		}
+	}
+
+	if offset, ok := file.Tags[TagOffset]; ok {
+		ms, err := strconv.Atoi(offset)
+		if err != nil {
+			return File{}, ErrInvalidOffset
+		}
+		for i := range file.Lines {
+			if file.Lines[i].Start != nil {
+				shifted := max(*file.Lines[i].Start-time.Duration(ms)*time.Millisecond, 0)
+				file.Lines[i].Start = &shifted
+			}
+		}
+		delete(file.Tags, TagOffset)
+	}
+
+	return file, nil
+}
+
+// Format формирует LRC-текст: сначала известные теги в стандартном порядке, затем остальные по алфавиту, затем строки.
+func Format(file File) string {
+	var sb strings.Builder
+
+	written := make(map[string]bool, len(file.Tags))
+	for _, tag := range tagsOrder {
+		if value, ok := file.Tags[tag]; ok && len(value) > 0 {
+			fmt.Fprintf(&sb, "[%s:%s]\n", tag, value)
+			written[tag] = true
+		}
+	}
+
+	rest := make([]string, 0)
+	for tag := range file.Tags {
+		if !written[tag] && tag != TagOffset {
+			rest = append(rest, tag)
+		}
+	}
+	sort.Strings(rest)
+	for _, tag := range rest {
+		fmt.Fprintf(&sb, "[%s:%s]\n", tag, file.Tags[tag])
+	}
+
+	if sb.Len() > 0 && len(file.Lines) > 0 {
+		sb.WriteString("\n")
+	}
+
+	for _, line := range file.Lines {
+		if line.Start != nil {
+			sb.WriteString(FormatTimestamp(*line.Start))
+		}
+		sb.WriteString(line.Text)
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// FormatTimestamp возвращает метку в формате [mm:ss.xx].
+func FormatTimestamp(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	centiseconds := d.Milliseconds() / 10
+	return fmt.Sprintf("[%02d:%02d.%02d]", centiseconds/6000, centiseconds/100%60, centiseconds%100)
+}
+
+func parseTimestamp(minutes, seconds, fraction string) (time.Duration, error) {
+	m, err := strconv.Atoi(minutes)
+	if err != nil {
+		return 0, ErrInvalidTimestamp
+	}
+
+	s, err := strconv.Atoi(seconds)
+	if err != nil || s >= 60 {
+		return 0, ErrInvalidTimestamp
+	}
+
+	var ms int
+	if len(fraction) > 0 {
+		ms, err = strconv.Atoi(fraction)
+		if err != nil {
+			return 0, ErrInvalidTimestamp
+		}
+		// .5 = 500 мс, .05 = 50 мс, .005 = 5 мс
+		for i := len(fraction); i < 3; i++ {
+			ms *= 10
+		}
+	}
+
+	return time.Duration(m)*time.Minute + time.Duration(s)*time.Second + time.Duration(ms)*time.Millisecond, nil
+}
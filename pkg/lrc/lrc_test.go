@@ -0,0 +1,183 @@
+package lrc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func at(d time.Duration) *time.Duration {
+	return &d
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		tags    map[string]string
+		lines   []Line
+		wantErr error
+	}{
+		{
+			name: "timestamp formats",
+			data: "[00:01]a\n[00:02.5]b\n[00:03.25]c\n[00:04.125]d\n[01:05:50]e",
+			tags: map[string]string{},
+			lines: []Line{
+				{Start: at(time.Second), Text: "a"},
+				{Start: at(2500 * time.Millisecond), Text: "b"},
+				{Start: at(3250 * time.Millisecond), Text: "c"},
+				{Start: at(4125 * time.Millisecond), Text: "d"},
+				{Start: at(time.Minute + 5500*time.Millisecond), Text: "e"},
+			},
+		},
+		{
+			name: "metadata and blank lines",
+			data: "\ufeff[ti: Song ]\n[AR:Group]\n[la:ru]\n\n[00:01.00]a\n[00:02.00]b\n\n[00:03.00]c",
+			tags: map[string]string{TagTitle: "Song", TagArtist: "Group", "la": "ru"},
+			lines: []Line{
+				{Text: ""},
+				{Start: at(time.Second), Text: "a"},
+				{Start: at(2 * time.Second), Text: "b"},
+				{Text: ""},
+				{Start: at(3 * time.Second), Text: "c"},
+			},
+		},
+		{
+			name: "untimed lines are kept",
+			data: "a\n\nb",
+			tags: map[string]string{},
+			lines: []Line{
+				{Text: "a"},
+				{Text: ""},
+				{Text: "b"},
+			},
+		},
+		{
+			name: "offset shifts timestamps",
+			data: "[offset:500]\n[00:00.20]a\n[00:02.00]b",
+			tags: map[string]string{},
+			lines: []Line{
+				{Start: at(0), Text: "a"},
+				{Start: at(1500 * time.Millisecond), Text: "b"},
+			},
+		},
+		{
+			name: "negative offset",
+			data: "[offset:-1000]\n[00:01.00]a",
+			tags: map[string]string{},
+			lines: []Line{
+				{Start: at(2 * time.Second), Text: "a"},
+			},
+		},
+		{
+			name: "compressed lines keep couplets",
+			data: "[ti:Song]\n" +
+				"[00:10.00]verse 1\n" +
+				"[00:12.00]verse 1 end\n" +
+				"\n" +
+				"[00:20.00][01:20.00]chorus\n" +
+				"[00:22.00][01:22.00]chorus end\n" +
+				"\n" +
+				"[00:30.00]verse 2\n" +
+				"untimed\n",
+			tags: map[string]string{TagTitle: "Song"},
+			lines: []Line{
+				{Start: at(10 * time.Second), Text: "verse 1"},
+				{Start: at(12 * time.Second), Text: "verse 1 end"},
+				{},
+				{Start: at(20 * time.Second), Text: "chorus"},
+				{Start: at(22 * time.Second), Text: "chorus end"},
+				{},
+				{Start: at(30 * time.Second), Text: "verse 2"},
+				{},
+				{Start: at(80 * time.Second), Text: "chorus"},
+				{Start: at(82 * time.Second), Text: "chorus end"},
+			},
+		},
+		{
+			name: "timed empty line is kept",
+			data: "[00:10.00]a\n[00:15.00]\n\n[00:20.00]b",
+			tags: map[string]string{},
+			lines: []Line{
+				{Start: at(10 * time.Second), Text: "a"},
+				{Start: at(15 * time.Second)},
+				{},
+				{Start: at(20 * time.Second), Text: "b"},
+			},
+		},
+		{
+			name:    "seconds out of range",
+			data:    "[00:61.00]a",
+			wantErr: ErrInvalidTimestamp,
+		},
+		{
+			name:    "invalid offset",
+			data:    "[offset:soon]\n[00:01.00]a",
+			wantErr: ErrInvalidOffset,
+		},
+		{
+			name: "malformed tag is text",
+			data: "[00:1x]a\n[abc]b",
+			tags: map[string]string{},
+			lines: []Line{
+				{Text: "[00:1x]a"},
+				{Text: "[abc]b"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := Parse(tt.data)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.tags, file.Tags)
+			assert.Equal(t, tt.lines, file.Lines)
+		})
+	}
+}
+
+func TestFormatTimestamp(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "[00:00.00]"},
+		{-time.Second, "[00:00.00]"},
+		{1234 * time.Millisecond, "[00:01.23]"},
+		{61*time.Minute + 5*time.Second, "[61:05.00]"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, FormatTimestamp(tt.d))
+	}
+}
+
+func TestParseFormatRoundTrip(t *testing.T) {
+	file := File{
+		Tags: map[string]string{TagArtist: "Group", TagTitle: "Song", "la": "ru"},
+		Lines: []Line{
+			{Start: at(10 * time.Second), Text: "first"},
+			{Start: at(12500 * time.Millisecond), Text: "second"},
+			{Start: at(15 * time.Second)},
+			{},
+			{Start: at(time.Minute + 20*time.Millisecond), Text: "third"},
+			{Text: "untimed"},
+		},
+	}
+
+	data := Format(file)
+	assert.Equal(t, "[ti:Song]\n[ar:Group]\n[la:ru]\n\n"+
+		"[00:10.00]first\n[00:12.50]second\n[00:15.00]\n\n[01:00.02]third\nuntimed\n", data)
+
+	parsed, err := Parse(data)
+	require.NoError(t, err)
+	assert.Equal(t, file.Tags, parsed.Tags)
+	// Пустая строка между тегами и текстом читается как разделитель перед первым куплетом.
+	assert.Equal(t, append([]Line{{}}, file.Lines...), parsed.Lines)
+}
@@ -21,3 +21,24 @@ func ConnTimeout(timeout time.Duration) Option {
 		p.connTimeout = timeout
 	}
 }
+
+// Replicas задаёт адреса реплик, на которые GetReadQueryRunner направляет чтение.
+func Replicas(urls ...string) Option {
+	return func(p *Postgres) {
+		p.replicaURLs = urls
+	}
+}
+
+// ReplicaCheckInterval задаёт период проверки доступности реплик.
+func ReplicaCheckInterval(interval time.Duration) Option {
+	return func(p *Postgres) {
+		p.replicaCheckInterval = interval
+	}
+}
+
+// ReadYourWrites включает чтение с основного сервера после записи, см. WithReadYourWrites.
+func ReadYourWrites(enabled bool) Option {
+	return func(p *Postgres) {
+		p.readYourWrites = enabled
+	}
+}
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/Masterminds/squirrel"
@@ -37,6 +38,7 @@ type QueryRunner interface {
 	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
 	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
 	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
 }
 
 type PgxPool interface {
@@ -53,15 +55,24 @@ type Postgres struct {
 	connAttempts int
 	connTimeout  time.Duration
 
+	replicaURLs          []string
+	replicaCheckInterval time.Duration
+	readYourWrites       bool
+
+	replicas       []*replica
+	nextReplicaIdx atomic.Uint64
+	stopChecks     chan struct{}
+
 	Builder squirrel.StatementBuilderType
 	Pool    *pgxpool.Pool
 }
 
 func New(url string, opts ...Option) (*Postgres, error) {
 	pg := &Postgres{
-		maxPoolSize:  defaultMaxPoolSize,
-		connAttempts: defaultConnAttempts,
-		connTimeout:  defaultConnTimeout,
+		maxPoolSize:          defaultMaxPoolSize,
+		connAttempts:         defaultConnAttempts,
+		connTimeout:          defaultConnTimeout,
+		replicaCheckInterval: defaultReplicaCheckInterval,
 	}
 
 	// Custom options
@@ -77,6 +88,7 @@ func New(url string, opts ...Option) (*Postgres, error) {
 	}
 
 	poolConfig.MaxConns = int32(pg.maxPoolSize)
+	poolConfig.ConnConfig.Tracer = newTracer()
 
 	for pg.connAttempts > 0 {
 		pg.Pool, err = pgxpool.NewWithConfig(context.Background(), poolConfig)
@@ -97,37 +109,27 @@ func New(url string, opts ...Option) (*Postgres, error) {
 		return nil, fmt.Errorf("postgres - NewPostgres - connAttempts == 0: %w", err)
 	}
 
+	if err = pg.connectReplicas(); err != nil {
+		pg.Close()
+		return nil, fmt.Errorf("postgres - NewPostgres - pg.connectReplicas: %w", err)
+	}
+
 	return pg, nil
 }
 
 func (pg *Postgres) Close() {
+	pg.closeReplicas()
 	if pg.Pool != nil {
 		pg.Pool.Close()
 	}
 }
 
 // GetQueryRunner возвращает объект для выполнения запросов: если в контексте есть транзакция,
-// а иначе использует пул соединений.
+// а иначе использует пул соединений основного сервера. Для чтения, которое можно направить
+// на реплику, используется GetReadQueryRunner.
 func (pg *Postgres) GetQueryRunner(ctx context.Context) QueryRunner {
 	if tx, ok := extractTx(ctx); ok {
 		return tx
 	}
 	return pg.Pool
 }
-
-// WithinTransaction выполняет функцию fn в рамках транзакции.
-func (pg *Postgres) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
-	tx, err := pg.Pool.Begin(ctx)
-	if err != nil {
-		return fmt.Errorf("postgres - WithinTransaction - Begin: %w", err)
-	}
-
-	defer func() {
-		_ = tx.Rollback(ctx)
-	}()
-
-	if err = fn(injectTx(ctx, tx)); err != nil {
-		return err
-	}
-	return tx.Commit(ctx)
-}
@@ -0,0 +1,189 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultReplicaCheckInterval = 5 * time.Second
+
+// replica — пул соединений реплики и результат её последней проверки.
+type replica struct {
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+}
+
+// pinKey используется для хранения признаков записи в контексте запроса.
+type pinKey struct{}
+
+// pinState: pinned направляет чтение на основной сервер, wrote отмечает, что запрос сам
+// записал данные.
+type pinState struct {
+	pinned atomic.Bool
+	wrote  atomic.Bool
+}
+
+// WithReadYourWrites подготавливает контекст запроса: после первой записи чтение в этом
+// контексте идёт с основного сервера, чтобы запрос видел свои изменения, даже если реплика
+// отстаёт. Без реплик или с выключенной опцией возвращает ctx без изменений.
+func (pg *Postgres) WithReadYourWrites(ctx context.Context) context.Context {
+	if !pg.readYourWrites || len(pg.replicas) == 0 {
+		return ctx
+	}
+	if _, ok := ctx.Value(pinKey{}).(*pinState); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, pinKey{}, new(pinState))
+}
+
+// WithPrimary возвращает контекст, чтение в котором идёт с основного сервера.
+// Признаки записи запроса в нём не видны и не меняются.
+func WithPrimary(ctx context.Context) context.Context {
+	state := new(pinState)
+	state.pinned.Store(true)
+	return context.WithValue(ctx, pinKey{}, state)
+}
+
+// SkipReadYourWrites возвращает контекст, записи в котором не направляют чтение запроса
+// на основной сервер. Подходит для служебных записей, которые запрос не читает, например счётчиков.
+func SkipReadYourWrites(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(pinKey{}).(*pinState); !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, pinKey{}, new(pinState))
+}
+
+// Pin направляет дальнейшее чтение запроса на основной сервер, например, если клиент
+// записывал данные в предыдущем запросе. Работает в контексте из WithReadYourWrites.
+func Pin(ctx context.Context) {
+	if state, ok := ctx.Value(pinKey{}).(*pinState); ok {
+		state.pinned.Store(true)
+	}
+}
+
+// Wrote сообщает, записал ли запрос данные на основной сервер.
+func Wrote(ctx context.Context) bool {
+	state, ok := ctx.Value(pinKey{}).(*pinState)
+	return ok && state.wrote.Load()
+}
+
+// markWrite отмечает, что запрос записал данные.
+func markWrite(ctx context.Context) {
+	if state, ok := ctx.Value(pinKey{}).(*pinState); ok {
+		state.wrote.Store(true)
+		state.pinned.Store(true)
+	}
+}
+
+func isPinned(ctx context.Context) bool {
+	state, ok := ctx.Value(pinKey{}).(*pinState)
+	return ok && state.pinned.Load()
+}
+
+// GetReadQueryRunner возвращает объект для запросов только на чтение: транзакцию из контекста,
+// основной сервер, если запрос уже записывал данные, или доступную реплику по кругу.
+// Если доступных реплик нет, чтение идёт с основного сервера.
+func (pg *Postgres) GetReadQueryRunner(ctx context.Context) QueryRunner {
+	if tx, ok := extractTx(ctx); ok {
+		return tx
+	}
+	if isPinned(ctx) {
+		return pg.Pool
+	}
+	if r := pg.nextReplica(); r != nil {
+		return r.pool
+	}
+	return pg.Pool
+}
+
+func (pg *Postgres) nextReplica() *replica {
+	n := uint64(len(pg.replicas))
+	if n == 0 {
+		return nil
+	}
+
+	start := pg.nextReplicaIdx.Add(1)
+	for i := range n {
+		if r := pg.replicas[(start+i)%n]; r.healthy.Load() {
+			return r
+		}
+	}
+	return nil
+}
+
+// connectReplicas создаёт пулы реплик и запускает их проверку. Недоступная при запуске
+// реплика не мешает старту: она начнёт получать запросы после успешной проверки.
+func (pg *Postgres) connectReplicas() error {
+	for i, url := range pg.replicaURLs {
+		poolConfig, err := pgxpool.ParseConfig(url)
+		if err != nil {
+			return fmt.Errorf("replica #%d - pgxpool.ParseConfig: %w", i+1, err)
+		}
+
+		poolConfig.MaxConns = int32(pg.maxPoolSize)
+		poolConfig.ConnConfig.Tracer = newTracer()
+
+		pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+		if err != nil {
+			return fmt.Errorf("replica #%d - pgxpool.NewWithConfig: %w", i+1, err)
+		}
+		pg.replicas = append(pg.replicas, &replica{pool: pool})
+	}
+
+	if len(pg.replicas) == 0 {
+		return nil
+	}
+
+	pg.checkReplicas()
+	pg.stopChecks = make(chan struct{})
+	go pg.watchReplicas()
+
+	return nil
+}
+
+func (pg *Postgres) watchReplicas() {
+	ticker := time.NewTicker(pg.replicaCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-pg.stopChecks:
+			return
+		case <-ticker.C:
+			pg.checkReplicas()
+		}
+	}
+}
+
+// checkReplicas проверяет каждую реплику и сообщает в журнал об изменении её состояния.
+func (pg *Postgres) checkReplicas() {
+	for i, r := range pg.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), pg.replicaCheckInterval)
+		err := r.pool.Ping(ctx)
+		cancel()
+
+		healthy := err == nil
+		if r.healthy.Swap(healthy) == healthy {
+			continue
+		}
+		if healthy {
+			log.Infof("Postgres replica #%d is available", i+1)
+		} else {
+			log.Warnf("Postgres replica #%d is unavailable, reading from other replicas or primary: %v", i+1, err)
+		}
+	}
+}
+
+func (pg *Postgres) closeReplicas() {
+	if pg.stopChecks != nil {
+		close(pg.stopChecks)
+	}
+	for _, r := range pg.replicas {
+		r.pool.Close()
+	}
+}
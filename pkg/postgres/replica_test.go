@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadYourWrites(t *testing.T) {
+	pg := &Postgres{readYourWrites: true, replicas: []*replica{{}}}
+
+	ctx := pg.WithReadYourWrites(context.Background())
+	assert.False(t, isPinned(ctx))
+	assert.False(t, Wrote(ctx))
+
+	// Служебная запись не направляет чтение запроса на основной сервер.
+	markWrite(SkipReadYourWrites(ctx))
+	assert.False(t, isPinned(ctx))
+
+	// Чтение с основного сервера для кэша не отмечает запрос как записавший.
+	assert.True(t, isPinned(WithPrimary(ctx)))
+	assert.False(t, isPinned(ctx))
+
+	Pin(ctx)
+	assert.True(t, isPinned(ctx))
+	assert.False(t, Wrote(ctx))
+
+	markWrite(ctx)
+	assert.True(t, Wrote(ctx))
+	assert.Same(t, ctx, pg.WithReadYourWrites(ctx), "nested call keeps the request state")
+}
+
+func TestReadYourWritesDisabled(t *testing.T) {
+	for _, pg := range []*Postgres{
+		{readYourWrites: false, replicas: []*replica{{}}},
+		{readYourWrites: true},
+	} {
+		ctx := context.Background()
+		assert.Equal(t, ctx, pg.WithReadYourWrites(ctx))
+
+		Pin(ctx)
+		markWrite(ctx)
+		assert.False(t, isPinned(ctx))
+		assert.False(t, Wrote(ctx))
+	}
+}
+
+func TestIsWrite(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want bool
+	}{
+		{"INSERT 0 1", true},
+		{"UPDATE 2", true},
+		{"DELETE 0", true},
+		{"MERGE 1", true},
+		{"SELECT 1", false},
+		{"BEGIN", false},
+		{"SAVEPOINT", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, isWrite(pgconn.NewCommandTag(tt.tag)), tt.tag)
+	}
+}
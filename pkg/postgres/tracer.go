@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/spanwalla/song-library/pkg/postgres"
+
+// tracer создаёт span на каждый запрос, пакет запросов и COPY, выполненные через пул.
+// Провайдер берётся глобальный, поэтому при выключенной трассировке span не записываются.
+// Кроме того, tracer видит ошибку каждого запроса и отмечает конфликты транзакций для повтора,
+// а по результату INSERT, UPDATE, DELETE, MERGE и COPY — что запрос записал данные.
+type tracer struct {
+	tracer trace.Tracer
+}
+
+func newTracer() *tracer {
+	return &tracer{tracer: otel.Tracer(tracerName)}
+}
+
+func (t *tracer) start(ctx context.Context, name string, attrs ...attribute.KeyValue) context.Context {
+	ctx, _ = t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL),
+		trace.WithAttributes(attrs...),
+	)
+	return ctx
+}
+
+func (t *tracer) end(ctx context.Context, err error, rowsAffected int64) {
+	markConflict(ctx, err)
+
+	span := trace.SpanFromContext(ctx)
+	// Отсутствие строк для сервиса является обычным результатом, а не ошибкой запроса.
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	if rowsAffected >= 0 {
+		span.SetAttributes(attribute.Int64("db.rows_affected", rowsAffected))
+	}
+	span.End()
+}
+
+func (t *tracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return t.start(ctx, operationName(data.SQL), semconv.DBQueryText(data.SQL))
+}
+
+func (t *tracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	if data.Err == nil && isWrite(data.CommandTag) {
+		markWrite(ctx)
+	}
+	t.end(ctx, data.Err, data.CommandTag.RowsAffected())
+}
+
+func (t *tracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	return t.start(ctx, "BATCH", attribute.Int("db.batch.size", data.Batch.Len()))
+}
+
+func (t *tracer) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchQueryData) {
+	span := trace.SpanFromContext(ctx)
+	span.AddEvent("query", trace.WithAttributes(semconv.DBQueryText(data.SQL)))
+	if data.Err != nil {
+		span.RecordError(data.Err)
+	}
+}
+
+func (t *tracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	t.end(ctx, data.Err, -1)
+}
+
+func (t *tracer) TraceCopyFromStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	return t.start(ctx, "COPY "+data.TableName.Sanitize(),
+		semconv.DBCollectionName(data.TableName.Sanitize()),
+		attribute.StringSlice("db.copy.columns", data.ColumnNames),
+	)
+}
+
+func (t *tracer) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	if data.Err == nil {
+		markWrite(ctx)
+	}
+	t.end(ctx, data.Err, data.CommandTag.RowsAffected())
+}
+
+func isWrite(tag pgconn.CommandTag) bool {
+	return tag.Insert() || tag.Update() || tag.Delete() || strings.HasPrefix(tag.String(), "MERGE")
+}
+
+// operationName возвращает первое слово запроса (SELECT, INSERT, ...) для имени span.
+func operationName(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "QUERY"
+	}
+	return strings.ToUpper(fields[0])
+}
@@ -0,0 +1,192 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/spanwalla/song-library/pkg/logger"
+)
+
+const (
+	defaultMaxRetries  = 3
+	defaultRetryDelay  = 20 * time.Millisecond
+	maxRetryDelay      = time.Second
+	serializationError = "40001"
+	deadlockError      = "40P01"
+)
+
+type txConfig struct {
+	options    pgx.TxOptions
+	maxRetries int
+}
+
+// TxOption настраивает транзакцию WithinTransaction. Во вложенном вызове настройки
+// не действуют: он выполняется в транзакции внешнего вызова.
+type TxOption func(*txConfig)
+
+// Isolation задаёт уровень изоляции, по умолчанию используется уровень сервера.
+func Isolation(level pgx.TxIsoLevel) TxOption {
+	return func(c *txConfig) {
+		c.options.IsoLevel = level
+	}
+}
+
+// ReadOnly запрещает транзакции изменять данные.
+func ReadOnly() TxOption {
+	return func(c *txConfig) {
+		c.options.AccessMode = pgx.ReadOnly
+	}
+}
+
+// Deferrable вместе с Isolation(pgx.Serializable) и ReadOnly ждёт снимок, которому
+// не грозит ошибка сериализации, вместо повторов.
+func Deferrable() TxOption {
+	return func(c *txConfig) {
+		c.options.DeferrableMode = pgx.Deferrable
+	}
+}
+
+// MaxRetries задаёт, сколько раз повторить транзакцию после ошибки сериализации
+// или взаимной блокировки. 0 отключает повторы.
+func MaxRetries(n int) TxOption {
+	return func(c *txConfig) {
+		c.maxRetries = n
+	}
+}
+
+// txState отмечает, что в транзакции произошёл конфликт. Сервисы заменяют ошибки репозиториев
+// своими, поэтому по ошибке fn конфликт не распознать, и его отмечает tracer.
+type txState struct {
+	conflict atomic.Bool
+}
+
+type txStateKey struct{}
+
+// markConflict отмечает конфликт в транзакции из контекста, если err — ошибка сериализации
+// или взаимная блокировка.
+func markConflict(ctx context.Context, err error) {
+	if state, ok := ctx.Value(txStateKey{}).(*txState); ok && isConflict(err) {
+		state.conflict.Store(true)
+	}
+}
+
+func isConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == serializationError || pgErr.Code == deadlockError)
+}
+
+// WithinTransaction выполняет функцию fn в рамках транзакции.
+// Запросы внутри транзакции группируются под общим span.
+//
+// Вложенный вызов присоединяется к транзакции из контекста через точку сохранения: ошибка fn
+// откатывает только его изменения. После ошибки сериализации (40001) или взаимной блокировки
+// (40P01) транзакция целиком повторяется с нарастающей задержкой, поэтому fn не должна иметь
+// побочных эффектов вне базы.
+func (pg *Postgres) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) (err error) {
+	if tx, ok := extractTx(ctx); ok {
+		return withinSavepoint(ctx, tx, fn)
+	}
+
+	cfg := txConfig{maxRetries: defaultMaxRetries}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "postgres.transaction")
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	for attempt := 0; ; attempt++ {
+		var conflict bool
+		conflict, err = pg.runTransaction(ctx, cfg.options, fn)
+		if !conflict || attempt >= cfg.maxRetries {
+			span.SetAttributes(attribute.Int("db.transaction.attempts", attempt+1))
+			break
+		}
+
+		delay := retryDelay(attempt)
+		logger.From(ctx).Warnf("postgres - WithinTransaction - conflict, retrying in %s: %v", delay, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+	return err
+}
+
+// runTransaction выполняет одну попытку транзакции и сообщает, была ли она прервана конфликтом.
+func (pg *Postgres) runTransaction(ctx context.Context, options pgx.TxOptions, fn func(ctx context.Context) error) (bool, error) {
+	state := &txState{}
+	ctx = context.WithValue(ctx, txStateKey{}, state)
+
+	tx, err := pg.Pool.BeginTx(ctx, options)
+	if err != nil {
+		return false, fmt.Errorf("postgres - WithinTransaction - BeginTx: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if err = fn(injectTx(ctx, tx)); err != nil {
+		return state.conflict.Load() || isConflict(err), err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return state.conflict.Load() || isConflict(err), err
+	}
+
+	return false, nil
+}
+
+// withinSavepoint выполняет fn во вложенной транзакции pgx, то есть под SAVEPOINT.
+func withinSavepoint(ctx context.Context, tx pgx.Tx, fn func(ctx context.Context) error) (err error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "postgres.savepoint")
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("postgres - WithinTransaction - Begin: %w", err)
+	}
+
+	defer func() {
+		_ = savepoint.Rollback(ctx)
+	}()
+
+	if err = fn(injectTx(ctx, savepoint)); err != nil {
+		return err
+	}
+	return savepoint.Commit(ctx)
+}
+
+// retryDelay удваивает задержку с каждой попыткой и добавляет случайную часть, чтобы
+// конфликтующие транзакции не повторялись одновременно.
+func retryDelay(attempt int) time.Duration {
+	delay := maxRetryDelay
+	if attempt < 10 {
+		delay = min(defaultRetryDelay<<attempt, maxRetryDelay)
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryDelay(t *testing.T) {
+	for attempt := range 64 {
+		want := maxRetryDelay
+		if attempt < 10 {
+			want = min(defaultRetryDelay<<attempt, maxRetryDelay)
+		}
+
+		for range 100 {
+			delay := retryDelay(attempt)
+			require.GreaterOrEqual(t, delay, want/2, "attempt %d", attempt)
+			require.LessOrEqual(t, delay, want, "attempt %d", attempt)
+		}
+	}
+
+	// Большой номер попытки не переполняет сдвиг.
+	assert.GreaterOrEqual(t, retryDelay(1000), maxRetryDelay/2)
+	assert.InDelta(t, 15*time.Millisecond, retryDelay(0), float64(5*time.Millisecond))
+}
+
+func TestIsConflict(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"serialization failure", &pgconn.PgError{Code: "40001"}, true},
+		{"deadlock", &pgconn.PgError{Code: "40P01"}, true},
+		{"wrapped", fmt.Errorf("SongRepo.Insert - Exec: %w", &pgconn.PgError{Code: "40001"}), true},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, false},
+		{"lock not available", &pgconn.PgError{Code: "55P03"}, false},
+		{"other transaction rollback", &pgconn.PgError{Code: "40002"}, false},
+		{"no rows", pgx.ErrNoRows, false},
+		{"plain error", errors.New("40001"), false},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isConflict(tt.err))
+		})
+	}
+}
+
+func TestMarkConflict(t *testing.T) {
+	state := &txState{}
+	ctx := context.WithValue(context.Background(), txStateKey{}, state)
+
+	markConflict(ctx, &pgconn.PgError{Code: "23505"})
+	assert.False(t, state.conflict.Load())
+
+	markConflict(ctx, &pgconn.PgError{Code: "40P01"})
+	assert.True(t, state.conflict.Load())
+
+	// Без транзакции в контексте отмечать нечего.
+	markConflict(context.Background(), &pgconn.PgError{Code: "40001"})
+}
+
+// fakeTx записывает вызовы точек сохранения. Остальные методы pgx.Tx не используются.
+type fakeTx struct {
+	pgx.Tx
+	name string
+	log  *[]string
+}
+
+func (tx *fakeTx) Begin(context.Context) (pgx.Tx, error) {
+	name := tx.name + "/savepoint"
+	*tx.log = append(*tx.log, "begin "+name)
+	return &fakeTx{name: name, log: tx.log}, nil
+}
+
+func (tx *fakeTx) Commit(context.Context) error {
+	*tx.log = append(*tx.log, "commit "+tx.name)
+	return nil
+}
+
+func (tx *fakeTx) Rollback(context.Context) error {
+	*tx.log = append(*tx.log, "rollback "+tx.name)
+	return nil
+}
+
+func TestWithinTransactionNested(t *testing.T) {
+	var log []string
+	pg := &Postgres{}
+	ctx := injectTx(context.Background(), &fakeTx{name: "tx", log: &log})
+	errInner := errors.New("inner failed")
+
+	err := pg.WithinTransaction(ctx, func(ctx context.Context) error {
+		tx, ok := extractTx(ctx)
+		require.True(t, ok)
+		assert.Equal(t, "tx/savepoint", tx.(*fakeTx).name)
+
+		// Ошибка вложенного вызова откатывает только его точку сохранения.
+		err := pg.WithinTransaction(ctx, func(ctx context.Context) error {
+			return errInner
+		})
+		assert.ErrorIs(t, err, errInner)
+
+		return pg.WithinTransaction(ctx, func(ctx context.Context) error {
+			return nil
+		}, Isolation(pgx.Serializable))
+	})
+	require.NoError(t, err)
+
+	// Откат после фиксации точки сохранения ничего не делает, pgx его пропускает.
+	assert.Equal(t, []string{
+		"begin tx/savepoint",
+		"begin tx/savepoint/savepoint",
+		"rollback tx/savepoint/savepoint",
+		"begin tx/savepoint/savepoint",
+		"commit tx/savepoint/savepoint",
+		"rollback tx/savepoint/savepoint",
+		"commit tx/savepoint",
+		"rollback tx/savepoint",
+	}, log)
+}
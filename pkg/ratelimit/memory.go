@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type counter struct {
+	window    time.Time
+	expiresAt time.Time
+	count     int
+}
+
+// MemoryStore keeps counters in process memory. It suits a single instance:
+// replicas with separate stores give each client a budget per replica.
+type MemoryStore struct {
+	mu       sync.Mutex
+	counters map[string]*counter
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{counters: make(map[string]*counter)}
+}
+
+func (s *MemoryStore) Increment(_ context.Context, key string, window, expiresAt time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.counters[key]
+	if !ok || !c.window.Equal(window) {
+		c = &counter{window: window, expiresAt: expiresAt}
+		s.counters[key] = c
+	}
+	c.count++
+
+	return c.count, nil
+}
+
+func (s *MemoryStore) DeleteExpired(_ context.Context, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, c := range s.counters {
+		if !now.Before(c.expiresAt) {
+			delete(s.counters, key)
+		}
+	}
+
+	return nil
+}
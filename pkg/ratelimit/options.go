@@ -0,0 +1,12 @@
+package ratelimit
+
+import "time"
+
+type Option func(*Limiter)
+
+// SweepInterval sets how often expired counters are dropped from the store.
+func SweepInterval(interval time.Duration) Option {
+	return func(l *Limiter) {
+		l.sweepInterval = interval
+	}
+}
@@ -0,0 +1,99 @@
+// Package ratelimit implements fixed-window rate limiting over a pluggable counter store.
+package ratelimit
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+const defaultSweepInterval = time.Minute
+
+// Limit allows Requests requests per Window.
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// Result describes the state of a key's budget after a request.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time left until the current window ends and the budget is restored.
+	Reset time.Duration
+}
+
+// Store keeps request counters. Implementations must increment atomically,
+// so that several application instances can share one store.
+type Store interface {
+	// Increment adds a request to the counter of key in the window starting at window
+	// and returns the new count. The counter may be dropped after expiresAt.
+	Increment(ctx context.Context, key string, window, expiresAt time.Time) (int, error)
+	// DeleteExpired drops counters that expired before now.
+	DeleteExpired(ctx context.Context, now time.Time) error
+}
+
+type Limiter struct {
+	store         Store
+	sweepInterval time.Duration
+	now           func() time.Time
+
+	lastSweep atomic.Int64
+}
+
+func New(store Store, opts ...Option) *Limiter {
+	l := &Limiter{
+		store:         store,
+		sweepInterval: defaultSweepInterval,
+		now:           time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	l.lastSweep.Store(l.now().UnixNano())
+
+	return l
+}
+
+// Allow counts a request for key and reports whether it fits into limit.
+// A non-positive limit disables limiting for the call.
+func (l *Limiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	if limit.Requests <= 0 || limit.Window <= 0 {
+		return Result{Allowed: true}, nil
+	}
+
+	now := l.now()
+	window := now.Truncate(limit.Window)
+	reset := window.Add(limit.Window)
+
+	l.sweep(now)
+
+	count, err := l.store.Increment(ctx, key, window, reset)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{
+		Allowed:   count <= limit.Requests,
+		Limit:     limit.Requests,
+		Remaining: max(limit.Requests-count, 0),
+		Reset:     reset.Sub(now),
+	}, nil
+}
+
+// sweep drops expired counters in the background, at most once per sweep interval.
+func (l *Limiter) sweep(now time.Time) {
+	last := l.lastSweep.Load()
+	if now.UnixNano()-last < int64(l.sweepInterval) || !l.lastSweep.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), l.sweepInterval)
+		defer cancel()
+		_ = l.store.DeleteExpired(ctx, now)
+	}()
+}
@@ -0,0 +1,103 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func newTestLimiter(start time.Time) (*Limiter, *clock) {
+	c := &clock{now: start}
+	l := New(NewMemoryStore(), SweepInterval(time.Hour))
+	l.now = c.Now
+	return l, c
+}
+
+func TestAllowBudget(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 10, 0, time.UTC)
+	l, _ := newTestLimiter(start)
+	limit := Limit{Requests: 3, Window: time.Minute}
+
+	for i := 1; i <= 3; i++ {
+		result, err := l.Allow(context.Background(), "client", limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 3, result.Limit)
+		assert.Equal(t, 3-i, result.Remaining)
+		assert.Equal(t, 50*time.Second, result.Reset)
+	}
+
+	result, err := l.Allow(context.Background(), "client", limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	// Each key has its own budget.
+	result, err = l.Allow(context.Background(), "other", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 2, result.Remaining)
+}
+
+func TestAllowWindowRollover(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 50, 0, time.UTC)
+	l, c := newTestLimiter(start)
+	limit := Limit{Requests: 1, Window: time.Minute}
+
+	result, err := l.Allow(context.Background(), "client", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 10*time.Second, result.Reset)
+
+	c.now = start.Add(9 * time.Second)
+	result, err = l.Allow(context.Background(), "client", limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.Reset)
+
+	// Windows are fixed: the next one starts at the minute boundary, not a minute after the first request.
+	c.now = start.Add(10 * time.Second)
+	result, err = l.Allow(context.Background(), "client", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, time.Minute, result.Reset)
+}
+
+func TestAllowDisabled(t *testing.T) {
+	l, _ := newTestLimiter(time.Now())
+
+	for _, limit := range []Limit{{Requests: 0, Window: time.Minute}, {Requests: 1, Window: 0}} {
+		for range 3 {
+			result, err := l.Allow(context.Background(), "client", limit)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+		}
+	}
+}
+
+func TestMemoryStoreDeleteExpired(t *testing.T) {
+	s := NewMemoryStore()
+	window := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	_, err := s.Increment(ctx, "old", window, window.Add(time.Minute))
+	require.NoError(t, err)
+	_, err = s.Increment(ctx, "new", window.Add(time.Minute), window.Add(2*time.Minute))
+	require.NoError(t, err)
+
+	require.NoError(t, s.DeleteExpired(ctx, window.Add(time.Minute)))
+	assert.NotContains(t, s.counters, "old")
+	assert.Contains(t, s.counters, "new")
+}
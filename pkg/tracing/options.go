@@ -0,0 +1,33 @@
+package tracing
+
+type Option func(*Tracing)
+
+// Exporter sets where spans are sent: otlp, stdout or off.
+func Exporter(exporter string) Option {
+	return func(t *Tracing) {
+		t.exporter = exporter
+	}
+}
+
+// Endpoint sets the OTLP collector address. When empty, the standard
+// OTEL_EXPORTER_OTLP_ENDPOINT variable or the exporter default is used.
+func Endpoint(endpoint string) Option {
+	return func(t *Tracing) {
+		t.endpoint = endpoint
+	}
+}
+
+// Insecure disables TLS for the OTLP exporter.
+func Insecure(insecure bool) Option {
+	return func(t *Tracing) {
+		t.insecure = insecure
+	}
+}
+
+// SampleRatio sets the fraction of new traces that are recorded.
+// Traces started by a caller follow the caller's sampling decision.
+func SampleRatio(ratio float64) Option {
+	return func(t *Tracing) {
+		t.sampleRatio = ratio
+	}
+}
@@ -0,0 +1,120 @@
+// Package tracing configures the global OpenTelemetry tracer provider.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterOff    = "off"
+)
+
+const (
+	defaultExporter        = ExporterOff
+	defaultSampleRatio     = 1.0
+	defaultShutdownTimeout = 3 * time.Second
+)
+
+type Tracing struct {
+	exporter    string
+	endpoint    string
+	insecure    bool
+	sampleRatio float64
+
+	provider *sdktrace.TracerProvider
+}
+
+// New installs the global tracer provider and W3C trace context propagator.
+// With the off exporter spans are not recorded, but incoming trace context
+// is still propagated to outgoing requests.
+func New(serviceName, serviceVersion string, opts ...Option) (*Tracing, error) {
+	t := &Tracing{
+		exporter:    defaultExporter,
+		sampleRatio: defaultSampleRatio,
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+
+	switch t.exporter {
+	case ExporterOff:
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		return t, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		exporter, err = t.newOTLPExporter()
+	default:
+		return nil, fmt.Errorf("tracing - New: unknown exporter %q", t.exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tracing - New - %s exporter: %w", t.exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(serviceVersion),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("tracing - New - resource.Merge: %w", err)
+	}
+
+	t.provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(t.sampleRatio))),
+	)
+	otel.SetTracerProvider(t.provider)
+
+	return t, nil
+}
+
+func (t *Tracing) newOTLPExporter() (sdktrace.SpanExporter, error) {
+	var opts []otlptracegrpc.Option
+	if len(t.endpoint) > 0 {
+		opts = append(opts, otlptracegrpc.WithEndpoint(t.endpoint))
+	}
+	if t.insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+
+	return otlptracegrpc.New(context.Background(), opts...)
+}
+
+// Shutdown flushes buffered spans and stops the exporter.
+func (t *Tracing) Shutdown() error {
+	if t.provider == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultShutdownTimeout)
+	defer cancel()
+
+	return t.provider.Shutdown(ctx)
+}
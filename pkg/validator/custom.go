@@ -1,14 +1,40 @@
 package validator
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-playground/validator"
 )
 
+var languageRegexp = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// FieldError описывает ошибку проверки одного поля.
+type FieldError struct {
+	Field   string
+	Tag     string
+	Param   string
+	Message string
+}
+
+// ValidationError содержит ошибки по всем полям, не прошедшим проверку.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
 type CustomValidator struct {
 	v *validator.Validate
 }
@@ -18,11 +44,16 @@ func NewCustomValidator() *CustomValidator {
 	cv := &CustomValidator{v: v}
 
 	v.RegisterTagNameFunc(func(fld reflect.StructField) string {
-		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
-		if name == "-" {
-			return ""
+		for _, tag := range []string{"json", "param", "query"} {
+			name := strings.SplitN(fld.Tag.Get(tag), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if len(name) > 0 {
+				return name
+			}
 		}
-		return name
+		return ""
 	})
 
 	err := v.RegisterValidation("date", cv.validateDate)
@@ -30,15 +61,37 @@ func NewCustomValidator() *CustomValidator {
 		panic(err)
 	}
 
+	err = v.RegisterValidation("lang", cv.validateLanguage)
+	if err != nil {
+		panic(err)
+	}
+
+	err = v.RegisterValidation("maxbytes", cv.validateMaxBytes)
+	if err != nil {
+		panic(err)
+	}
+
 	return cv
 }
 
 func (cv *CustomValidator) Validate(i any) error {
 	err := cv.v.Struct(i)
 	if err != nil {
-		fieldErr := err.(validator.ValidationErrors)[0]
+		var fieldErrs validator.ValidationErrors
+		if !errors.As(err, &fieldErrs) {
+			return err
+		}
 
-		return cv.newValidationError(fieldErr.Field(), fieldErr.Tag(), fieldErr.Param())
+		validationErr := &ValidationError{Fields: make([]FieldError, 0, len(fieldErrs))}
+		for _, fieldErr := range fieldErrs {
+			validationErr.Fields = append(validationErr.Fields, FieldError{
+				Field:   fieldErr.Field(),
+				Tag:     fieldErr.Tag(),
+				Param:   fieldErr.Param(),
+				Message: cv.newValidationError(fieldErr.Field(), fieldErr.Tag(), fieldErr.Param()).Error(),
+			})
+		}
+		return validationErr
 	}
 	return nil
 }
@@ -51,14 +104,20 @@ func (cv *CustomValidator) newValidationError(field string, tag string, param st
 		return fmt.Errorf("field %s must be at least %s characters", field, param)
 	case "max":
 		return fmt.Errorf("field %s must be at most %s characters", field, param)
+	case "maxbytes":
+		return fmt.Errorf("field %s must be at most %s bytes", field, param)
 	case "date":
 		return fmt.Errorf("field %s must be a valid date (format: 2006-01-17)", field)
+	case "lang":
+		return fmt.Errorf("field %s must be a valid language code (format: en, ru, pt-BR)", field)
 	case "uri":
 		return fmt.Errorf("field %s must be a valid URI", field)
 	case "number":
 		return fmt.Errorf("field %s must be a valid number", field)
 	case "gt":
 		return fmt.Errorf("field %s must be greater than %s", field, param)
+	case "oneof":
+		return fmt.Errorf("field %s must be one of: %s", field, strings.ReplaceAll(param, " ", ", "))
 	default:
 		return fmt.Errorf("field %s is invalid", field)
 	}
@@ -69,3 +128,17 @@ func (cv *CustomValidator) validateDate(fl validator.FieldLevel) bool {
 	_, err := time.Parse("2006-01-02", dateStr)
 	return err == nil
 }
+
+// validateMaxBytes ограничивает длину строки в байтах, а не в символах, как max.
+func (cv *CustomValidator) validateMaxBytes(fl validator.FieldLevel) bool {
+	limit, err := strconv.Atoi(fl.Param())
+	if err != nil {
+		return false
+	}
+	return len(fl.Field().String()) <= limit
+}
+
+func (cv *CustomValidator) validateLanguage(fl validator.FieldLevel) bool {
+	lang := fl.Field().String()
+	return len(lang) <= 16 && languageRegexp.MatchString(lang)
+}
@@ -0,0 +1,42 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxBytes(t *testing.T) {
+	type input struct {
+		Password string `json:"password" validate:"maxbytes=72"`
+	}
+	cv := NewCustomValidator()
+
+	tests := []struct {
+		name     string
+		password string
+		valid    bool
+	}{
+		{"ascii at limit", strings.Repeat("a", 72), true},
+		{"ascii over limit", strings.Repeat("a", 73), false},
+		{"cyrillic at limit", strings.Repeat("я", 36), true},
+		// 40 символов кириллицы занимают 80 байт.
+		{"cyrillic over limit", strings.Repeat("я", 40), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := cv.Validate(input{Password: tt.password})
+			if tt.valid {
+				assert.NoError(t, err)
+				return
+			}
+
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, "field password must be at most 72 bytes", validationErr.Error())
+		})
+	}
+}